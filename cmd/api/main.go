@@ -2,14 +2,19 @@ package main
 
 import (
 	"fmt"
+	"log"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/rezbow/ecommerce/internal/app/handlers"
+	"github.com/rezbow/ecommerce/internal/app/models"
 	"github.com/rezbow/ecommerce/internal/app/services"
 	"github.com/rezbow/ecommerce/internal/platform/cache"
 	"github.com/rezbow/ecommerce/internal/platform/config"
 	"github.com/rezbow/ecommerce/internal/platform/database"
+	"github.com/rezbow/ecommerce/internal/platform/mail"
 	"github.com/rezbow/ecommerce/internal/platform/middlewares"
+	"github.com/rezbow/ecommerce/internal/platform/payment"
 )
 
 func main() {
@@ -30,55 +35,252 @@ func main() {
 		return
 	}
 
+	shippingMethods, err := loadShippingMethods(cfg.ShippingMethodsFile)
+	if err != nil {
+		fmt.Println(err.Error())
+		return
+	}
+	taxConfig, err := loadTaxConfig(cfg.TaxRulesFile)
+	if err != nil {
+		fmt.Println(err.Error())
+		return
+	}
+	passwordPolicy, err := loadPasswordPolicy(cfg.PasswordPolicyFile)
+	if err != nil {
+		fmt.Println(err.Error())
+		return
+	}
+
 	// repo
 	userRepo := database.NewUserRepo(db)
+	refreshTokenRepo := database.NewRefreshTokenRepo(db)
+	passwordResetRepo := database.NewPasswordResetRepo(db)
+	emailVerificationRepo := database.NewEmailVerificationRepo(db)
 	productRepo := database.NewProductRepo(db)
+	variantRepo := database.NewVariantRepo(db)
 	cartRepo := database.NewCartRepoRedis(redis)
+	orderRepo := database.NewOrderRepo(db)
+	reservationRepo := database.NewReservationRepo(db)
+	paymentRepo := database.NewPaymentRepo(db)
+	webhookEventRepo := database.NewWebhookEventRepo(db)
+	refundRepo := database.NewRefundRepo(db)
+	addressRepo := database.NewAddressRepo(db)
+	couponRepo := database.NewCouponRepo(db)
+	promotionRepo := database.NewPromotionRepo(db)
+	categoryRepo := database.NewCategoryRepo(db)
+	roleRepo := database.NewRoleRepo(db)
+
+	// payment provider
+	paymentProvider := payment.NewFakeProvider()
+
+	// mailer
+	var mailer mail.Mailer
+	if cfg.Mailer == "log" {
+		log.Println("MAILER=log, emails are not delivered")
+		mailer = mail.NewLogMailer()
+	} else {
+		mailer = mail.NewSMTPMailer(cfg.SMTPHost, cfg.SMTPPort, cfg.SMTPUser, cfg.SMTPPass, cfg.MailFrom)
+	}
 
 	// services
-	userSvc := services.NewUserService(userRepo, cfg.JWTSecret)
-	productSvc := services.NewProductService(productRepo)
-	cartSvc := services.NewCartService(cartRepo, productRepo)
+	emailVerificationSvc := services.NewEmailVerificationService(userRepo, emailVerificationRepo, mailer, cfg.AppURL, cfg.EmailVerificationTTL, cfg.EmailVerificationResendInterval)
+	userSvc := services.NewUserService(userRepo, refreshTokenRepo, emailVerificationSvc, cfg.JWTSecret, cfg.AccessTokenTTL, cfg.RefreshTokenTTL)
+	passwordResetSvc := services.NewPasswordResetService(userRepo, passwordResetRepo, mailer, cfg.AppURL, cfg.PasswordResetTTL)
+	productSvc := services.NewProductService(productRepo, variantRepo, categoryRepo)
+	addressSvc := services.NewAddressService(addressRepo)
+	taxSvc := services.NewTaxService(taxConfig)
+	cartSvc := services.NewCartService(cartRepo, productRepo, couponRepo, promotionRepo, categoryRepo, taxSvc, addressSvc, cfg.DefaultCurrency)
+	orderSvc := services.NewOrderService(orderRepo)
+	paymentSvc := services.NewPaymentService(paymentProvider, paymentRepo, webhookEventRepo, orderSvc)
+	shippingSvc := services.NewShippingService(shippingMethods, cartSvc, addressSvc, productRepo)
+	refundSvc := services.NewRefundService(refundRepo, orderSvc, paymentSvc)
+	cancellationSvc := services.NewCancellationService(orderSvc, paymentSvc, reservationRepo)
+	couponSvc := services.NewCouponService(couponRepo)
+	promotionSvc := services.NewPromotionService(promotionRepo)
+	categorySvc := services.NewCategoryService(categoryRepo, productRepo)
+	roleSvc := services.NewRoleService(roleRepo, userRepo)
+	checkoutSvc := services.NewCheckoutService(cartSvc, paymentSvc, addressSvc, shippingSvc, taxSvc, productRepo, orderRepo, userRepo)
+
+	// background jobs
+	go releaseExpiredHolds(cancellationSvc, time.Minute)
 
 	// handler
-	userHandler := handlers.NewUserHandler(userSvc)
+	userHandler := handlers.NewUserHandler(userSvc, passwordPolicy)
+	passwordHandler := handlers.NewPasswordHandler(passwordResetSvc, passwordPolicy)
+	emailVerificationHandler := handlers.NewEmailVerificationHandler(emailVerificationSvc)
 	productHandler := handlers.NewProductHandler(productSvc)
 	cartHandler := handlers.NewCartHandler(cartSvc)
+	checkoutHandler := handlers.NewCheckoutHandler(checkoutSvc)
+	orderHandler := handlers.NewOrderHandler(orderSvc, cancellationSvc)
+	webhookHandler := handlers.NewWebhookHandler(paymentSvc)
+	refundHandler := handlers.NewRefundHandler(refundSvc)
+	addressHandler := handlers.NewAddressHandler(addressSvc)
+	shippingHandler := handlers.NewShippingHandler(shippingSvc)
+	couponHandler := handlers.NewCouponHandler(couponSvc)
+	promotionHandler := handlers.NewPromotionHandler(promotionSvc)
+	categoryHandler := handlers.NewCategoryHandler(categorySvc)
+	roleHandler := handlers.NewRoleHandler(roleSvc)
+
+	// pagination cursors handed out by the list endpoints are signed
+	handlers.SetCursorSecret(cfg.CursorSecret)
 
 	// middlewares
 	authMiddleware := middlewares.AuthMiddleware(cfg)
+	idempotencyMiddleware := middlewares.IdempotencyMiddleware(redis)
 
 	router := gin.Default()
 
+	router.GET("/products/search", productHandler.SearchProducts)
 	router.GET("/products/:id", productHandler.GetProduct)
 	router.GET("/products", productHandler.ListProducts)
+	router.GET("/categories", categoryHandler.GetCategoryTree)
+	router.GET("/categories/:slug/products", categoryHandler.ListCategoryProducts)
 
 	router.POST("/register", userHandler.Register)
 	router.POST("/login", userHandler.Login)
+	// refresh tokens are single use, both endpoints take one in the body
+	router.POST("/token/refresh", userHandler.RefreshToken)
+	router.POST("/logout", userHandler.Logout)
+	router.POST("/password/forgot", passwordHandler.ForgotPassword)
+	router.POST("/password/reset", passwordHandler.ResetPassword)
+	router.GET("/verify-email", emailVerificationHandler.VerifyEmail)
+
+	// callbacks from the payment provider, authenticated by their signature
+	router.POST("/webhooks/payments", middlewares.WebhookSignatureMiddleware(cfg.PaymentWebhookSecret), webhookHandler.PaymentEvent)
 
 	protected := router.Group("/")
 	protected.Use(authMiddleware)
 	{
 		protected.POST("/profile", userHandler.Profile)
+		protected.POST("/verify-email/resend", emailVerificationHandler.ResendVerification) // mail a new verification link, rate limited
 		// endpoints for cart operations
-		protected.GET("/cart", cartHandler.GetCart)                // getting user's cart information
-		protected.POST("/cart", cartHandler.AddToCart)             // adding an item to cart
-		protected.PUT("/cart/:id", cartHandler.UpdateItemQuantity) // update quantity of an item in cart
-		protected.DELETE("/cart/:id", cartHandler.DeleteItem)      // delete a specific item with id in the cart
-		protected.DELETE("/cart", cartHandler.ClearCart)           // delete the entire cart
+		protected.GET("/cart", cartHandler.GetCart)                           // getting user's cart information
+		protected.POST("/cart", idempotencyMiddleware, cartHandler.AddToCart) // adding an item to cart
+		protected.PUT("/cart/:id", cartHandler.UpdateItemQuantity)            // update quantity of an item in cart, id is the variant id for variants and the product id otherwise
+		protected.DELETE("/cart/:id", cartHandler.DeleteItem)                 // delete a specific item with id in the cart
+		protected.DELETE("/cart", cartHandler.ClearCart)                      // delete the entire cart
+		protected.POST("/cart/coupon", cartHandler.ApplyCoupon)               // apply a coupon code to the cart
+		protected.DELETE("/cart/coupon", cartHandler.RemoveCoupon)            // take the coupon code off the cart
+		// shipping methods available for the user's cart
+		protected.GET("/shipping/quotes", shippingHandler.GetQuotes)
+		// endpoints for the user's address book
+		protected.GET("/addresses", addressHandler.ListAddresses)
+		protected.POST("/addresses", addressHandler.CreateAddress)
+		protected.GET("/addresses/:id", addressHandler.GetAddress)
+		protected.PUT("/addresses/:id", addressHandler.UpdateAddress)
+		protected.DELETE("/addresses/:id", addressHandler.DeleteAddress)
 		// endpoint for user's order details
-		protected.GET("/orders")
-		protected.GET("/orders/:id")
+		protected.GET("/orders", orderHandler.ListOrders)
+		protected.GET("/orders/:id", orderHandler.GetOrder)
+		protected.POST("/orders/:id/cancel", orderHandler.CancelOrder)
 		// endpoint for checkingout the cart
-		protected.POST("/checkout")
+		protected.POST("/checkout", idempotencyMiddleware, checkoutHandler.Checkout)
 	}
 
+	// every admin endpoint requires a permission granted by the user's roles
+	productsWrite := middlewares.RequirePermission(models.PermissionProductsWrite)
+	categoriesWrite := middlewares.RequirePermission(models.PermissionCategoriesWrite)
+	ordersRead := middlewares.RequirePermission(models.PermissionOrdersRead)
+	ordersWrite := middlewares.RequirePermission(models.PermissionOrdersWrite)
+	refundsWrite := middlewares.RequirePermission(models.PermissionRefundsWrite)
+	couponsRead := middlewares.RequirePermission(models.PermissionCouponsRead)
+	couponsWrite := middlewares.RequirePermission(models.PermissionCouponsWrite)
+	promotionsRead := middlewares.RequirePermission(models.PermissionPromotionsRead)
+	promotionsWrite := middlewares.RequirePermission(models.PermissionPromotionsWrite)
+	rolesRead := middlewares.RequirePermission(models.PermissionRolesRead)
+	rolesWrite := middlewares.RequirePermission(models.PermissionRolesWrite)
+
 	admin := router.Group("/admin")
-	admin.Use(authMiddleware, middlewares.AdminMiddleware())
+	admin.Use(authMiddleware)
 	{
-		admin.POST("/products", productHandler.CreateProduct)
-		admin.PUT("/products/:id", productHandler.UpdateProduct)
+		admin.POST("/products", productsWrite, productHandler.CreateProduct)
+		admin.PUT("/products/:id", productsWrite, productHandler.UpdateProduct)
+		admin.PUT("/products/:id/prices", productsWrite, productHandler.SetProductPrices)
+		admin.PUT("/products/:id/categories", productsWrite, productHandler.SetProductCategories)
+		// endpoints for the options and variants a product is sold in
+		admin.PUT("/products/:id/options", productsWrite, productHandler.SetProductOptions)
+		admin.POST("/products/:id/variants", productsWrite, productHandler.CreateVariant)
+		admin.PUT("/products/:id/variants/:variantId", productsWrite, productHandler.UpdateVariant)
+		admin.DELETE("/products/:id/variants/:variantId", productsWrite, productHandler.DeleteVariant)
+		// endpoints for the category tree
+		admin.POST("/categories", categoriesWrite, categoryHandler.CreateCategory)
+		admin.PUT("/categories/:id", categoriesWrite, categoryHandler.UpdateCategory)
+		admin.DELETE("/categories/:id", categoriesWrite, categoryHandler.DeleteCategory)
+		// endpoints for moving orders through their lifecycle
+		admin.PUT("/orders/:id/status", ordersWrite, orderHandler.UpdateOrderStatus)
+		admin.GET("/orders/:id/history", ordersRead, orderHandler.GetOrderStatusHistory)
+		admin.POST("/orders/:id/refunds", refundsWrite, refundHandler.CreateRefund)
+		admin.GET("/orders/:id/refunds", ordersRead, refundHandler.ListRefunds)
+		// endpoints for discount codes
+		admin.POST("/coupons", couponsWrite, couponHandler.CreateCoupon)
+		admin.GET("/coupons", couponsRead, couponHandler.ListCoupons)
+		// endpoints for automatic cart promotions
+		admin.POST("/promotions", promotionsWrite, promotionHandler.CreatePromotion)
+		admin.GET("/promotions", promotionsRead, promotionHandler.ListPromotions)
+		admin.GET("/promotions/:id", promotionsRead, promotionHandler.GetPromotion)
+		admin.DELETE("/promotions/:id", promotionsWrite, promotionHandler.DeletePromotion)
+		// endpoints for the roles users are given
+		admin.GET("/roles", rolesRead, roleHandler.ListRoles)
+		admin.PUT("/users/:id/roles", rolesWrite, roleHandler.SetUserRoles)
 	}
 
 	router.Run(":8080")
 }
+
+func loadShippingMethods(path string) ([]models.ShippingMethod, error) {
+	var methods []models.ShippingMethod
+	if err := config.LoadJSONFile(path, &methods); err != nil {
+		return nil, err
+	}
+	for idx := range methods {
+		if err := methods[idx].Validate(); err != nil {
+			return nil, err
+		}
+	}
+	return methods, nil
+}
+
+func loadTaxConfig(path string) (*models.TaxConfig, error) {
+	var taxConfig models.TaxConfig
+	if err := config.LoadJSONFile(path, &taxConfig); err != nil {
+		return nil, err
+	}
+	if err := taxConfig.Validate(); err != nil {
+		return nil, err
+	}
+	return &taxConfig, nil
+}
+
+func loadPasswordPolicy(path string) (*models.PasswordPolicy, error) {
+	var policy models.PasswordPolicy
+	if err := config.LoadJSONFile(path, &policy); err != nil {
+		return nil, err
+	}
+	if err := policy.Validate(); err != nil {
+		return nil, err
+	}
+	if policy.DenylistFile != "" {
+		denylist, err := config.LoadLines(policy.DenylistFile)
+		if err != nil {
+			return nil, err
+		}
+		policy.SetDenylist(denylist)
+	}
+	return &policy, nil
+}
+
+// releaseExpiredHolds periodically gives back the stock held by orders that
+// were never paid.
+func releaseExpiredHolds(cancellationSvc services.ICancellationService, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for range ticker.C {
+		released, err := cancellationSvc.ReleaseExpiredHolds()
+		if err != nil {
+			log.Printf("releasing expired stock holds: %v", err)
+		}
+		if released > 0 {
+			log.Printf("released stock holds of %d expired orders", released)
+		}
+	}
+}
@@ -0,0 +1,157 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/rezbow/ecommerce/internal/app/models"
+	"github.com/rezbow/ecommerce/internal/app/services"
+)
+
+type AddressHandler struct {
+	addressSvc services.IAddressService
+}
+
+func NewAddressHandler(addressSvc services.IAddressService) *AddressHandler {
+	return &AddressHandler{
+		addressSvc: addressSvc,
+	}
+}
+
+func (handler *AddressHandler) ListAddresses(ctx *gin.Context) {
+	value, _ := ctx.Get("userId")
+	userId, ok := value.(uuid.UUID)
+	if !ok {
+		ctx.JSON(http.StatusUnauthorized, gin.H{
+			"error": "unauthroized user",
+		})
+		return
+	}
+
+	addresses, err := handler.addressSvc.ListAddresses(userId)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{"data": models.AddressesToAddressesResponse(addresses)})
+}
+
+func (handler *AddressHandler) GetAddress(ctx *gin.Context) {
+	value, _ := ctx.Get("userId")
+	userId, ok := value.(uuid.UUID)
+	if !ok {
+		ctx.JSON(http.StatusUnauthorized, gin.H{
+			"error": "unauthroized user",
+		})
+		return
+	}
+	addressId, err := uuid.Parse(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "address not found"})
+		return
+	}
+
+	address, err := handler.addressSvc.GetAddress(userId, addressId)
+	if err != nil {
+		if errors.Is(err, services.ErrAddressNotFound) {
+			ctx.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+	ctx.JSON(http.StatusOK, models.AddressToAddressResponse(*address))
+}
+
+func (handler *AddressHandler) CreateAddress(ctx *gin.Context) {
+	value, _ := ctx.Get("userId")
+	userId, ok := value.(uuid.UUID)
+	if !ok {
+		ctx.JSON(http.StatusUnauthorized, gin.H{
+			"error": "unauthroized user",
+		})
+		return
+	}
+
+	var addressCreate models.AddressCreate
+	if err := ctx.ShouldBindJSON(&addressCreate); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if valid, errs := addressCreate.Validate(); !valid {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": errs})
+		return
+	}
+
+	address, err := handler.addressSvc.CreateAddress(userId, &addressCreate)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+	ctx.JSON(http.StatusCreated, models.AddressToAddressResponse(*address))
+}
+
+func (handler *AddressHandler) UpdateAddress(ctx *gin.Context) {
+	value, _ := ctx.Get("userId")
+	userId, ok := value.(uuid.UUID)
+	if !ok {
+		ctx.JSON(http.StatusUnauthorized, gin.H{
+			"error": "unauthroized user",
+		})
+		return
+	}
+	addressId, err := uuid.Parse(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "address not found"})
+		return
+	}
+
+	var addressUpdate models.AddressUpdateRequest
+	if err := ctx.ShouldBindJSON(&addressUpdate); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if valid, errs := addressUpdate.Validate(); !valid {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": errs})
+		return
+	}
+
+	address, err := handler.addressSvc.UpdateAddress(userId, addressId, &addressUpdate)
+	if err != nil {
+		if errors.Is(err, services.ErrAddressNotFound) {
+			ctx.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+	ctx.JSON(http.StatusOK, models.AddressToAddressResponse(*address))
+}
+
+func (handler *AddressHandler) DeleteAddress(ctx *gin.Context) {
+	value, _ := ctx.Get("userId")
+	userId, ok := value.(uuid.UUID)
+	if !ok {
+		ctx.JSON(http.StatusUnauthorized, gin.H{
+			"error": "unauthroized user",
+		})
+		return
+	}
+	addressId, err := uuid.Parse(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "address not found"})
+		return
+	}
+
+	if err := handler.addressSvc.DeleteAddress(userId, addressId); err != nil {
+		if errors.Is(err, services.ErrAddressNotFound) {
+			ctx.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+	ctx.Status(http.StatusNoContent)
+}
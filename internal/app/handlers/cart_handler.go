@@ -72,11 +72,21 @@ func (handler *CartHandler) AddToCart(ctx *gin.Context) {
 			{
 				ctx.JSON(http.StatusNotFound, gin.H{"error": "product not found"})
 			}
-		case errors.Is(err, services.ErrInsufficientQuantity):
+		case errors.Is(err, services.ErrVariantNotFound):
+			{
+				ctx.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			}
+		case errors.Is(err, services.ErrInsufficientQuantity),
+			errors.Is(err, services.ErrPriceUnavailable),
+			errors.Is(err, services.ErrVariantRequired):
 			{
 				ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 
 			}
+		case errors.Is(err, services.ErrCartCurrencyMismatch):
+			{
+				ctx.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+			}
 		default:
 			{
 				ctx.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
@@ -97,7 +107,7 @@ func (handler *CartHandler) DeleteItem(ctx *gin.Context) {
 		})
 		return
 	}
-	productId, err := uuid.Parse(ctx.Param("id"))
+	lineId, err := uuid.Parse(ctx.Param("id"))
 	if err != nil {
 		ctx.JSON(http.StatusBadRequest, gin.H{
 			"error": err.Error(),
@@ -105,7 +115,7 @@ func (handler *CartHandler) DeleteItem(ctx *gin.Context) {
 		return
 	}
 
-	if err := handler.cartSvc.RemoveItemFromCart(userId, productId); err != nil {
+	if err := handler.cartSvc.RemoveItemFromCart(userId, lineId); err != nil {
 		if errors.Is(err, services.ErrItemNotFound) {
 			ctx.JSON(http.StatusNotFound, gin.H{
 				"error": err.Error(),
@@ -154,7 +164,7 @@ func (handler *CartHandler) UpdateItemQuantity(ctx *gin.Context) {
 		})
 		return
 	}
-	productId, err := uuid.Parse(ctx.Param("id"))
+	lineId, err := uuid.Parse(ctx.Param("id"))
 	if err != nil {
 		ctx.JSON(http.StatusBadRequest, gin.H{
 			"error": err.Error(),
@@ -174,7 +184,7 @@ func (handler *CartHandler) UpdateItemQuantity(ctx *gin.Context) {
 		return
 	}
 
-	if err := handler.cartSvc.UpdateItemQuantity(userId, productId, &itemQuantityUpdate); err != nil {
+	if err := handler.cartSvc.UpdateItemQuantity(userId, lineId, &itemQuantityUpdate); err != nil {
 		code, errStr := handleServiceErrs(err)
 		ctx.JSON(code, gin.H{"error": errStr})
 		return
@@ -184,11 +194,73 @@ func (handler *CartHandler) UpdateItemQuantity(ctx *gin.Context) {
 
 }
 
+func (handler *CartHandler) ApplyCoupon(ctx *gin.Context) {
+	value, _ := ctx.Get("userId")
+	userId, ok := value.(uuid.UUID)
+	if !ok {
+		ctx.JSON(http.StatusUnauthorized, gin.H{
+			"error": "unauthroized user",
+		})
+		return
+	}
+
+	var couponApplyRequest models.CouponApplyRequest
+	if err := ctx.ShouldBindJSON(&couponApplyRequest); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	cart, err := handler.cartSvc.ApplyCoupon(userId, &couponApplyRequest)
+	if err != nil {
+		code, errStr := handleCouponErrs(err)
+		ctx.JSON(code, gin.H{"error": errStr})
+		return
+	}
+	ctx.JSON(http.StatusOK, cart)
+}
+
+func (handler *CartHandler) RemoveCoupon(ctx *gin.Context) {
+	value, _ := ctx.Get("userId")
+	userId, ok := value.(uuid.UUID)
+	if !ok {
+		ctx.JSON(http.StatusUnauthorized, gin.H{
+			"error": "unauthroized user",
+		})
+		return
+	}
+
+	if err := handler.cartSvc.RemoveCoupon(userId); err != nil {
+		code, errStr := handleCouponErrs(err)
+		ctx.JSON(code, gin.H{"error": errStr})
+		return
+	}
+	ctx.Status(http.StatusNoContent)
+}
+
+func handleCouponErrs(err error) (int, string) {
+	switch {
+	case errors.Is(err, services.ErrCartNotFound),
+		errors.Is(err, services.ErrCouponNotFound):
+		return http.StatusNotFound, err.Error()
+	case errors.Is(err, services.ErrCouponNotActive),
+		errors.Is(err, services.ErrCouponCurrency),
+		errors.Is(err, services.ErrCouponMinimumTotal):
+		return http.StatusUnprocessableEntity, err.Error()
+	case errors.Is(err, services.ErrCouponLimitReached):
+		return http.StatusConflict, err.Error()
+	default:
+		return http.StatusInternalServerError, "internal server error"
+	}
+}
+
 func handleServiceErrs(err error) (int, string) {
 	switch {
-	case errors.Is(err, services.ErrProductNotFound):
+	case errors.Is(err, services.ErrProductNotFound),
+		errors.Is(err, services.ErrVariantNotFound):
 		return http.StatusNotFound, err.Error()
-	case errors.Is(err, services.ErrInsufficientQuantity):
+	case errors.Is(err, services.ErrInsufficientQuantity),
+		errors.Is(err, services.ErrPriceUnavailable),
+		errors.Is(err, services.ErrVariantRequired):
 		return http.StatusBadRequest, err.Error()
 	case errors.Is(err, services.ErrItemNotFound):
 		return http.StatusNotFound, err.Error()
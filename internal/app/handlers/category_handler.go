@@ -0,0 +1,135 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/rezbow/ecommerce/internal/app/models"
+	"github.com/rezbow/ecommerce/internal/app/services"
+)
+
+type CategoryHandler struct {
+	categorySvc services.ICategoryService
+}
+
+func NewCategoryHandler(categorySvc services.ICategoryService) *CategoryHandler {
+	return &CategoryHandler{
+		categorySvc: categorySvc,
+	}
+}
+
+func (handler *CategoryHandler) GetCategoryTree(ctx *gin.Context) {
+	tree, err := handler.categorySvc.GetCategoryTree()
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"data": tree})
+}
+
+func (handler *CategoryHandler) ListCategoryProducts(ctx *gin.Context) {
+	pagination, err := ExtractPagination(ctx)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	products, total, err := handler.categorySvc.ListCategoryProducts(ctx.Param("slug"), &pagination)
+	if err != nil {
+		status, message := handleCategoryErrs(err)
+		ctx.JSON(status, gin.H{"error": message})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"data": models.ProductsToProductsResponse(products),
+		"metadata": gin.H{
+			"page":        pagination.Page,
+			"limit":       pagination.Limit,
+			"total":       total,
+			"total_pages": pagination.TotalPages(total),
+			"next_cursor": nextCursor(ctx, &pagination, len(products), func() models.Cursor {
+				return products[len(products)-1].Cursor()
+			}),
+		},
+	})
+}
+
+func (handler *CategoryHandler) CreateCategory(ctx *gin.Context) {
+	var categoryCreate models.CategoryCreate
+	if err := ctx.ShouldBindJSON(&categoryCreate); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if valid, errs := categoryCreate.Validate(); !valid {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": errs})
+		return
+	}
+
+	category, err := handler.categorySvc.CreateCategory(&categoryCreate)
+	if err != nil {
+		status, message := handleCategoryErrs(err)
+		ctx.JSON(status, gin.H{"error": message})
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, models.CategoryToCategoryResponse(*category))
+}
+
+func (handler *CategoryHandler) UpdateCategory(ctx *gin.Context) {
+	categoryId, err := uuid.Parse(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": services.ErrCategoryNotFound.Error()})
+		return
+	}
+	var categoryUpdateRequest models.CategoryUpdateRequest
+	if err := ctx.ShouldBindJSON(&categoryUpdateRequest); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if valid, errs := categoryUpdateRequest.Validate(); !valid {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": errs})
+		return
+	}
+
+	category, err := handler.categorySvc.UpdateCategory(categoryId, &categoryUpdateRequest)
+	if err != nil {
+		status, message := handleCategoryErrs(err)
+		ctx.JSON(status, gin.H{"error": message})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, models.CategoryToCategoryResponse(*category))
+}
+
+func (handler *CategoryHandler) DeleteCategory(ctx *gin.Context) {
+	categoryId, err := uuid.Parse(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": services.ErrCategoryNotFound.Error()})
+		return
+	}
+
+	if err := handler.categorySvc.DeleteCategory(categoryId); err != nil {
+		status, message := handleCategoryErrs(err)
+		ctx.JSON(status, gin.H{"error": message})
+		return
+	}
+
+	ctx.Status(http.StatusNoContent)
+}
+
+func handleCategoryErrs(err error) (int, string) {
+	switch {
+	case errors.Is(err, services.ErrCategoryNotFound):
+		return http.StatusNotFound, err.Error()
+	case errors.Is(err, services.ErrCategoryExists),
+		errors.Is(err, services.ErrCategoryHasChildren):
+		return http.StatusConflict, err.Error()
+	case errors.Is(err, services.ErrCategoryParent):
+		return http.StatusUnprocessableEntity, err.Error()
+	default:
+		return http.StatusInternalServerError, "internal server error"
+	}
+}
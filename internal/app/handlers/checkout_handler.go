@@ -0,0 +1,85 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/rezbow/ecommerce/internal/app/models"
+	"github.com/rezbow/ecommerce/internal/app/services"
+)
+
+type CheckoutHandler struct {
+	checkoutSvc services.ICheckoutService
+}
+
+func NewCheckoutHandler(checkoutSvc services.ICheckoutService) *CheckoutHandler {
+	return &CheckoutHandler{
+		checkoutSvc: checkoutSvc,
+	}
+}
+
+func (handler *CheckoutHandler) Checkout(ctx *gin.Context) {
+	value, _ := ctx.Get("userId")
+	userId, ok := value.(uuid.UUID)
+	if !ok {
+		ctx.JSON(http.StatusUnauthorized, gin.H{
+			"error": "unauthroized user",
+		})
+		return
+	}
+
+	var checkoutRequest models.CheckoutRequest
+	if err := ctx.ShouldBindJSON(&checkoutRequest); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if valid, errs := checkoutRequest.Validate(); !valid {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": errs})
+		return
+	}
+
+	order, err := handler.checkoutSvc.Checkout(userId, &checkoutRequest)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrEmptyCart),
+			errors.Is(err, services.ErrShippingAddressRequired),
+			errors.Is(err, services.ErrTaxDestinationUnknown),
+			errors.Is(err, services.ErrShippingMethodNotFound),
+			errors.Is(err, services.ErrShippingUnavailable):
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		case errors.Is(err, services.ErrUserNotFound):
+			ctx.JSON(http.StatusUnauthorized, gin.H{"error": "unauthroized user"})
+		case errors.Is(err, services.ErrEmailNotVerified):
+			ctx.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		case errors.Is(err, services.ErrAddressNotFound):
+			ctx.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		case errors.Is(err, services.ErrProductNotFound):
+			ctx.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		case errors.Is(err, services.ErrInsufficientQuantity),
+			errors.Is(err, services.ErrCartChanged),
+			errors.Is(err, services.ErrCartCurrencyMismatch),
+			errors.Is(err, services.ErrPriceUnavailable),
+			errors.Is(err, services.ErrVariantNotFound),
+			errors.Is(err, services.ErrVariantRequired),
+			errors.Is(err, services.ErrCouponLimitReached):
+			ctx.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		case errors.Is(err, services.ErrPaymentDeclined):
+			ctx.JSON(http.StatusPaymentRequired, gin.H{"error": err.Error()})
+		case errors.Is(err, services.ErrPaymentFailed):
+			ctx.JSON(http.StatusBadGateway, gin.H{"error": err.Error()})
+		default:
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		}
+		return
+	}
+
+	// the payment outcome is not known yet, the order stays pending until
+	// the provider reports back or its stock hold expires
+	if order.Status == models.OrderStatusPending {
+		ctx.JSON(http.StatusAccepted, models.OrderToOrderResponse(*order))
+		return
+	}
+	ctx.JSON(http.StatusCreated, models.OrderToOrderResponse(*order))
+}
@@ -0,0 +1,65 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rezbow/ecommerce/internal/app/models"
+	"github.com/rezbow/ecommerce/internal/app/services"
+)
+
+type CouponHandler struct {
+	couponSvc services.ICouponService
+}
+
+func NewCouponHandler(couponSvc services.ICouponService) *CouponHandler {
+	return &CouponHandler{
+		couponSvc: couponSvc,
+	}
+}
+
+func (handler *CouponHandler) CreateCoupon(ctx *gin.Context) {
+	var couponCreate models.CouponCreate
+	if err := ctx.ShouldBindJSON(&couponCreate); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if valid, errs := couponCreate.Validate(); !valid {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": errs})
+		return
+	}
+
+	coupon, err := handler.couponSvc.CreateCoupon(&couponCreate)
+	if err != nil {
+		if errors.Is(err, services.ErrCouponExists) {
+			ctx.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, models.CouponToCouponResponse(*coupon))
+}
+
+func (handler *CouponHandler) ListCoupons(ctx *gin.Context) {
+	pagination, err := extractOffsetPagination(ctx)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	coupons, err := handler.couponSvc.ListCoupons(&pagination)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"data": models.CouponsToCouponsResponse(coupons),
+		"metadata": gin.H{
+			"page":  pagination.Page,
+			"limit": pagination.Limit,
+		},
+	})
+}
@@ -0,0 +1,65 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/rezbow/ecommerce/internal/app/services"
+)
+
+type EmailVerificationHandler struct {
+	verificationSvc services.IEmailVerificationService
+}
+
+func NewEmailVerificationHandler(verificationSvc services.IEmailVerificationService) *EmailVerificationHandler {
+	return &EmailVerificationHandler{
+		verificationSvc: verificationSvc,
+	}
+}
+
+// VerifyEmail is the target of the link in the verification email.
+func (handler *EmailVerificationHandler) VerifyEmail(ctx *gin.Context) {
+	token := ctx.Query("token")
+	if token == "" {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "token is required"})
+		return
+	}
+
+	if err := handler.verificationSvc.VerifyEmail(token); err != nil {
+		if errors.Is(err, services.ErrInvalidVerificationToken) {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{"message": "email verified"})
+}
+
+func (handler *EmailVerificationHandler) ResendVerification(ctx *gin.Context) {
+	value, _ := ctx.Get("userId")
+	userId, ok := value.(uuid.UUID)
+	if !ok {
+		ctx.JSON(http.StatusUnauthorized, gin.H{
+			"error": "unauthroized user",
+		})
+		return
+	}
+
+	if err := handler.verificationSvc.ResendVerification(userId); err != nil {
+		switch {
+		case errors.Is(err, services.ErrUserNotFound):
+			ctx.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		case errors.Is(err, services.ErrEmailAlreadyVerified):
+			ctx.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		case errors.Is(err, services.ErrVerificationRateLimited):
+			ctx.JSON(http.StatusTooManyRequests, gin.H{"error": err.Error()})
+		default:
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		}
+		return
+	}
+	ctx.Status(http.StatusAccepted)
+}
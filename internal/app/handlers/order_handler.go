@@ -0,0 +1,197 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/rezbow/ecommerce/internal/app/models"
+	"github.com/rezbow/ecommerce/internal/app/services"
+)
+
+type OrderHandler struct {
+	orderSvc        services.IOrderService
+	cancellationSvc services.ICancellationService
+}
+
+func NewOrderHandler(orderSvc services.IOrderService, cancellationSvc services.ICancellationService) *OrderHandler {
+	return &OrderHandler{
+		orderSvc:        orderSvc,
+		cancellationSvc: cancellationSvc,
+	}
+}
+
+func (handler *OrderHandler) ListOrders(ctx *gin.Context) {
+	value, _ := ctx.Get("userId")
+	userId, ok := value.(uuid.UUID)
+	if !ok {
+		ctx.JSON(http.StatusUnauthorized, gin.H{
+			"error": "unauthroized user",
+		})
+		return
+	}
+
+	pagination, err := ExtractPagination(ctx)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	orders, err := handler.orderSvc.ListUserOrders(userId, &pagination)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{
+			"error": "internal server error",
+		})
+		return
+	}
+
+	response := gin.H{
+		"data": models.OrdersToOrdersResponse(orders),
+		"metadata": gin.H{
+			"page":  pagination.Page,
+			"limit": pagination.Limit,
+			"next_cursor": nextCursor(ctx, &pagination, len(orders), func() models.Cursor {
+				return orders[len(orders)-1].Cursor()
+			}),
+		},
+	}
+	ctx.JSON(http.StatusOK, response)
+}
+
+func (handler *OrderHandler) GetOrder(ctx *gin.Context) {
+	value, _ := ctx.Get("userId")
+	userId, ok := value.(uuid.UUID)
+	if !ok {
+		ctx.JSON(http.StatusUnauthorized, gin.H{
+			"error": "unauthroized user",
+		})
+		return
+	}
+	orderId, err := uuid.Parse(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusNotFound, gin.H{
+			"error": "order not found",
+		})
+		return
+	}
+
+	order, err := handler.orderSvc.GetUserOrder(userId, orderId)
+	if err != nil {
+		if errors.Is(err, services.ErrOrderNotFound) {
+			ctx.JSON(http.StatusNotFound, gin.H{
+				"error": "order not found",
+			})
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, gin.H{
+			"error": "internal server error",
+		})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, models.OrderToOrderResponse(*order))
+}
+
+func (handler *OrderHandler) CancelOrder(ctx *gin.Context) {
+	value, _ := ctx.Get("userId")
+	userId, ok := value.(uuid.UUID)
+	if !ok {
+		ctx.JSON(http.StatusUnauthorized, gin.H{
+			"error": "unauthroized user",
+		})
+		return
+	}
+	orderId, err := uuid.Parse(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusNotFound, gin.H{
+			"error": "order not found",
+		})
+		return
+	}
+
+	order, err := handler.cancellationSvc.CancelUserOrder(userId, orderId)
+	if err != nil {
+		code, errStr := handleOrderErrs(err)
+		ctx.JSON(code, gin.H{"error": errStr})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, models.OrderToOrderResponse(*order))
+}
+
+func (handler *OrderHandler) UpdateOrderStatus(ctx *gin.Context) {
+	value, _ := ctx.Get("userId")
+	actorId, ok := value.(uuid.UUID)
+	if !ok {
+		ctx.JSON(http.StatusUnauthorized, gin.H{
+			"error": "unauthroized user",
+		})
+		return
+	}
+	orderId, err := uuid.Parse(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var statusUpdate models.OrderStatusUpdate
+	if err := ctx.ShouldBindJSON(&statusUpdate); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if valid, errs := statusUpdate.Validate(); !valid {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": errs})
+		return
+	}
+
+	var order *models.Order
+	if statusUpdate.Status == models.OrderStatusCancelled {
+		// cancelling has to give the payment back too
+		order, err = handler.cancellationSvc.CancelOrder(orderId, actorId)
+	} else {
+		order, err = handler.orderSvc.TransitionStatus(orderId, statusUpdate.Status, &actorId)
+	}
+	if err != nil {
+		code, errStr := handleOrderErrs(err)
+		ctx.JSON(code, gin.H{"error": errStr})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, models.OrderToOrderResponse(*order))
+}
+
+func (handler *OrderHandler) GetOrderStatusHistory(ctx *gin.Context) {
+	orderId, err := uuid.Parse(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	changes, err := handler.orderSvc.GetStatusHistory(orderId)
+	if err != nil {
+		code, errStr := handleOrderErrs(err)
+		ctx.JSON(code, gin.H{"error": errStr})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"data": models.OrderStatusChangesToResponse(changes)})
+}
+
+func handleOrderErrs(err error) (int, string) {
+	switch {
+	case errors.Is(err, services.ErrOrderNotFound):
+		return http.StatusNotFound, err.Error()
+	case errors.Is(err, services.ErrInvalidOrderStatus):
+		return http.StatusBadRequest, err.Error()
+	case errors.Is(err, services.ErrInvalidStatusTransition):
+		return http.StatusConflict, err.Error()
+	case errors.Is(err, services.ErrOrderModified):
+		return http.StatusConflict, err.Error()
+	case errors.Is(err, services.ErrOrderNotCancellable):
+		return http.StatusConflict, err.Error()
+	case errors.Is(err, services.ErrPaymentFailed):
+		return http.StatusBadGateway, "order cancelled but the payment could not be released"
+	default:
+		return http.StatusInternalServerError, "internal server error"
+	}
+}
@@ -0,0 +1,61 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rezbow/ecommerce/internal/app/models"
+	"github.com/rezbow/ecommerce/internal/app/services"
+)
+
+type PasswordHandler struct {
+	passwordResetSvc services.IPasswordResetService
+	passwordPolicy   *models.PasswordPolicy
+}
+
+func NewPasswordHandler(passwordResetSvc services.IPasswordResetService, passwordPolicy *models.PasswordPolicy) *PasswordHandler {
+	return &PasswordHandler{
+		passwordResetSvc: passwordResetSvc,
+		passwordPolicy:   passwordPolicy,
+	}
+}
+
+func (handler *PasswordHandler) ForgotPassword(ctx *gin.Context) {
+	var req models.ForgotPassword
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := handler.passwordResetSvc.RequestReset(&req); err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+	// the same answer for every email, accounts can't be told apart
+	ctx.JSON(http.StatusAccepted, gin.H{
+		"message": "if an account exists for this email, a password reset link has been sent to it",
+	})
+}
+
+func (handler *PasswordHandler) ResetPassword(ctx *gin.Context) {
+	var req models.ResetPassword
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if valid, errs := req.Validate(handler.passwordPolicy); !valid {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": errs})
+		return
+	}
+
+	if err := handler.passwordResetSvc.ResetPassword(&req); err != nil {
+		if errors.Is(err, services.ErrInvalidResetToken) {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+	ctx.Status(http.StatusNoContent)
+}
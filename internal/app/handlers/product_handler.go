@@ -49,23 +49,81 @@ func (handler *ProductHandler) GetProduct(ctx *gin.Context) {
 }
 
 func (handler *ProductHandler) ListProducts(ctx *gin.Context) {
-	pagination := ExtractPagination(ctx)
-	products, err := handler.productSvc.ListProducts(&pagination)
+	pagination, err := ExtractPagination(ctx)
 	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	var query models.ProductListQuery
+	if err := ctx.ShouldBindQuery(&query); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if valid, errs := query.Validate(); !valid {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": errs})
+		return
+	}
+	// cursors point into the newest first order only
+	sortsByCreation := query.Sort == "" || models.ProductSort(query.Sort) == models.ProductSortNewest
+	if pagination.After != nil && !sortsByCreation {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": ErrCursorUnsupported.Error()})
+		return
+	}
+
+	products, total, err := handler.productSvc.ListProducts(&query, &pagination)
+	if err != nil {
+		if errors.Is(err, services.ErrCategoryNotFound) {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
 		ctx.JSON(http.StatusInternalServerError, gin.H{
 			"error": "internal server error",
 		})
 		return
 	}
 
-	response := gin.H{
-		"data": models.ProductsToProductsResponse(products),
+	metadata := gin.H{
+		"page":        pagination.Page,
+		"limit":       pagination.Limit,
+		"total":       total,
+		"total_pages": pagination.TotalPages(total),
+		"next_cursor": nil,
+	}
+	if sortsByCreation {
+		metadata["next_cursor"] = nextCursor(ctx, &pagination, len(products), func() models.Cursor {
+			return products[len(products)-1].Cursor()
+		})
+	}
+	ctx.JSON(http.StatusOK, gin.H{
+		"data":     models.ProductsToProductsResponse(products),
+		"metadata": metadata,
+	})
+}
+
+func (handler *ProductHandler) SearchProducts(ctx *gin.Context) {
+	// search results are ordered by rank, they can only be paged by offset
+	pagination, err := extractOffsetPagination(ctx)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	hits, err := handler.productSvc.SearchProducts(ctx.Query("q"), &pagination)
+	if err != nil {
+		if errors.Is(err, services.ErrEmptySearchQuery) {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"data": models.SearchHitsToSearchHitsResponse(hits),
 		"metadata": gin.H{
 			"page":  pagination.Page,
 			"limit": pagination.Limit,
 		},
-	}
-	ctx.JSON(http.StatusOK, response)
+	})
 }
 
 func (handler *ProductHandler) CreateProduct(ctx *gin.Context) {
@@ -81,6 +139,10 @@ func (handler *ProductHandler) CreateProduct(ctx *gin.Context) {
 
 	product, err := handler.productSvc.CreateProduct(&productCreate)
 	if err != nil {
+		if errors.Is(err, services.ErrCategoryNotFound) {
+			ctx.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+			return
+		}
 		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
 		return
 	}
@@ -116,3 +178,185 @@ func (handler *ProductHandler) UpdateProduct(ctx *gin.Context) {
 
 	ctx.JSON(http.StatusCreated, models.ProductToProductResponse(*product))
 }
+
+func (handler *ProductHandler) SetProductPrices(ctx *gin.Context) {
+	productId, err := uuid.Parse(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	var pricesUpdate models.ProductPricesUpdate
+	if err := ctx.ShouldBindJSON(&pricesUpdate); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if valid, errs := pricesUpdate.Validate(); !valid {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": errs})
+		return
+	}
+
+	product, err := handler.productSvc.SetProductPrices(productId, &pricesUpdate)
+	if err != nil {
+		if errors.Is(err, services.ErrProductNotFound) {
+			ctx.JSON(http.StatusNotFound, gin.H{"error": "product not found"})
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, models.ProductToProductResponse(*product))
+}
+
+func (handler *ProductHandler) SetProductCategories(ctx *gin.Context) {
+	productId, err := uuid.Parse(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	var categoriesUpdate models.ProductCategoriesUpdate
+	if err := ctx.ShouldBindJSON(&categoriesUpdate); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if valid, errs := categoriesUpdate.Validate(); !valid {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": errs})
+		return
+	}
+
+	product, err := handler.productSvc.SetProductCategories(productId, &categoriesUpdate)
+	if err != nil {
+		if errors.Is(err, services.ErrProductNotFound) {
+			ctx.JSON(http.StatusNotFound, gin.H{"error": "product not found"})
+			return
+		}
+		if errors.Is(err, services.ErrCategoryNotFound) {
+			ctx.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, models.ProductToProductResponse(*product))
+}
+
+func (handler *ProductHandler) SetProductOptions(ctx *gin.Context) {
+	productId, err := uuid.Parse(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	var optionsUpdate models.ProductOptionsUpdate
+	if err := ctx.ShouldBindJSON(&optionsUpdate); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if valid, errs := optionsUpdate.Validate(); !valid {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": errs})
+		return
+	}
+
+	product, err := handler.productSvc.SetProductOptions(productId, &optionsUpdate)
+	if err != nil {
+		code, errStr := handleVariantErrs(err)
+		ctx.JSON(code, gin.H{"error": errStr})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, models.ProductToProductResponse(*product))
+}
+
+func (handler *ProductHandler) CreateVariant(ctx *gin.Context) {
+	productId, err := uuid.Parse(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	var variantCreate models.VariantCreate
+	if err := ctx.ShouldBindJSON(&variantCreate); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if valid, errs := variantCreate.Validate(); !valid {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": errs})
+		return
+	}
+
+	variant, err := handler.productSvc.CreateVariant(productId, &variantCreate)
+	if err != nil {
+		code, errStr := handleVariantErrs(err)
+		ctx.JSON(code, gin.H{"error": errStr})
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, models.VariantToVariantResponse(*variant))
+}
+
+func (handler *ProductHandler) UpdateVariant(ctx *gin.Context) {
+	productId, err := uuid.Parse(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	variantId, err := uuid.Parse(ctx.Param("variantId"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	var variantUpdateRequest models.VariantUpdateRequest
+	if err := ctx.ShouldBindJSON(&variantUpdateRequest); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if valid, errs := variantUpdateRequest.Validate(); !valid {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": errs})
+		return
+	}
+
+	variant, err := handler.productSvc.UpdateVariant(productId, variantId, &variantUpdateRequest)
+	if err != nil {
+		code, errStr := handleVariantErrs(err)
+		ctx.JSON(code, gin.H{"error": errStr})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, models.VariantToVariantResponse(*variant))
+}
+
+func (handler *ProductHandler) DeleteVariant(ctx *gin.Context) {
+	productId, err := uuid.Parse(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	variantId, err := uuid.Parse(ctx.Param("variantId"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := handler.productSvc.DeleteVariant(productId, variantId); err != nil {
+		code, errStr := handleVariantErrs(err)
+		ctx.JSON(code, gin.H{"error": errStr})
+		return
+	}
+
+	ctx.Status(http.StatusNoContent)
+}
+
+func handleVariantErrs(err error) (int, string) {
+	switch {
+	case errors.Is(err, services.ErrProductNotFound),
+		errors.Is(err, services.ErrVariantNotFound):
+		return http.StatusNotFound, err.Error()
+	case errors.Is(err, services.ErrVariantOptions):
+		return http.StatusUnprocessableEntity, err.Error()
+	case errors.Is(err, services.ErrVariantExists),
+		errors.Is(err, services.ErrVariantOrdered),
+		errors.Is(err, services.ErrProductHasVariants):
+		return http.StatusConflict, err.Error()
+	default:
+		return http.StatusInternalServerError, "internal server error"
+	}
+}
@@ -0,0 +1,101 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/rezbow/ecommerce/internal/app/models"
+	"github.com/rezbow/ecommerce/internal/app/services"
+)
+
+type PromotionHandler struct {
+	promotionSvc services.IPromotionService
+}
+
+func NewPromotionHandler(promotionSvc services.IPromotionService) *PromotionHandler {
+	return &PromotionHandler{
+		promotionSvc: promotionSvc,
+	}
+}
+
+func (handler *PromotionHandler) CreatePromotion(ctx *gin.Context) {
+	var promotionCreate models.PromotionCreate
+	if err := ctx.ShouldBindJSON(&promotionCreate); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if valid, errs := promotionCreate.Validate(); !valid {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": errs})
+		return
+	}
+
+	promotion, err := handler.promotionSvc.CreatePromotion(&promotionCreate)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, models.PromotionToPromotionResponse(*promotion))
+}
+
+func (handler *PromotionHandler) ListPromotions(ctx *gin.Context) {
+	pagination, err := extractOffsetPagination(ctx)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	promotions, err := handler.promotionSvc.ListPromotions(&pagination)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"data": models.PromotionsToPromotionsResponse(promotions),
+		"metadata": gin.H{
+			"page":  pagination.Page,
+			"limit": pagination.Limit,
+		},
+	})
+}
+
+func (handler *PromotionHandler) GetPromotion(ctx *gin.Context) {
+	promotionId, err := uuid.Parse(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": services.ErrPromotionNotFound.Error()})
+		return
+	}
+
+	promotion, err := handler.promotionSvc.GetPromotion(promotionId)
+	if err != nil {
+		if errors.Is(err, services.ErrPromotionNotFound) {
+			ctx.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, models.PromotionToPromotionResponse(*promotion))
+}
+
+func (handler *PromotionHandler) DeletePromotion(ctx *gin.Context) {
+	promotionId, err := uuid.Parse(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": services.ErrPromotionNotFound.Error()})
+		return
+	}
+
+	if err := handler.promotionSvc.DeletePromotion(promotionId); err != nil {
+		if errors.Is(err, services.ErrPromotionNotFound) {
+			ctx.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	ctx.Status(http.StatusNoContent)
+}
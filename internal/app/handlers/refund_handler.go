@@ -0,0 +1,92 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/rezbow/ecommerce/internal/app/models"
+	"github.com/rezbow/ecommerce/internal/app/services"
+)
+
+type RefundHandler struct {
+	refundSvc services.IRefundService
+}
+
+func NewRefundHandler(refundSvc services.IRefundService) *RefundHandler {
+	return &RefundHandler{
+		refundSvc: refundSvc,
+	}
+}
+
+func (handler *RefundHandler) CreateRefund(ctx *gin.Context) {
+	value, _ := ctx.Get("userId")
+	actorId, ok := value.(uuid.UUID)
+	if !ok {
+		ctx.JSON(http.StatusUnauthorized, gin.H{
+			"error": "unauthroized user",
+		})
+		return
+	}
+	orderId, err := uuid.Parse(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var refundRequest models.RefundRequest
+	if err := ctx.ShouldBindJSON(&refundRequest); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if valid, errs := refundRequest.Validate(); !valid {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": errs})
+		return
+	}
+
+	refund, err := handler.refundSvc.RefundOrder(orderId, &refundRequest, actorId)
+	if err != nil {
+		code, errStr := handleRefundErrs(err)
+		ctx.JSON(code, gin.H{"error": errStr})
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, models.RefundToRefundResponse(*refund))
+}
+
+func (handler *RefundHandler) ListRefunds(ctx *gin.Context) {
+	orderId, err := uuid.Parse(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	refunds, err := handler.refundSvc.ListRefunds(orderId)
+	if err != nil {
+		code, errStr := handleRefundErrs(err)
+		ctx.JSON(code, gin.H{"error": errStr})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"data": models.RefundsToRefundsResponse(refunds)})
+}
+
+func handleRefundErrs(err error) (int, string) {
+	switch {
+	case errors.Is(err, services.ErrOrderNotFound),
+		errors.Is(err, services.ErrRefundItemNotFound):
+		return http.StatusNotFound, err.Error()
+	case errors.Is(err, services.ErrRefundQuantity),
+		errors.Is(err, services.ErrRefundExceedsCaptured):
+		return http.StatusBadRequest, err.Error()
+	case errors.Is(err, services.ErrOrderNotRefundable),
+		errors.Is(err, services.ErrNothingToRefund),
+		errors.Is(err, services.ErrOrderModified):
+		return http.StatusConflict, err.Error()
+	case errors.Is(err, services.ErrPaymentFailed):
+		return http.StatusBadGateway, err.Error()
+	default:
+		return http.StatusInternalServerError, "internal server error"
+	}
+}
@@ -0,0 +1,59 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/rezbow/ecommerce/internal/app/models"
+	"github.com/rezbow/ecommerce/internal/app/services"
+)
+
+type ShippingHandler struct {
+	shippingSvc services.IShippingService
+}
+
+func NewShippingHandler(shippingSvc services.IShippingService) *ShippingHandler {
+	return &ShippingHandler{
+		shippingSvc: shippingSvc,
+	}
+}
+
+func (handler *ShippingHandler) GetQuotes(ctx *gin.Context) {
+	value, _ := ctx.Get("userId")
+	userId, ok := value.(uuid.UUID)
+	if !ok {
+		ctx.JSON(http.StatusUnauthorized, gin.H{
+			"error": "unauthroized user",
+		})
+		return
+	}
+
+	var quoteRequest models.ShippingQuoteRequest
+	if err := ctx.ShouldBindQuery(&quoteRequest); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if valid, errs := quoteRequest.Validate(); !valid {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": errs})
+		return
+	}
+
+	quotes, err := handler.shippingSvc.QuoteCart(userId, &quoteRequest)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrCartNotFound),
+			errors.Is(err, services.ErrAddressNotFound),
+			errors.Is(err, services.ErrProductNotFound):
+			ctx.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		case errors.Is(err, services.ErrCartCurrencyMismatch):
+			ctx.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		default:
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		}
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"data": quotes})
+}
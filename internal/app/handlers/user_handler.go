@@ -11,12 +11,14 @@ import (
 )
 
 type UserHandler struct {
-	userSvc models.UserSvc
+	userSvc        models.UserSvc
+	passwordPolicy *models.PasswordPolicy
 }
 
-func NewUserHandler(userSvc models.UserSvc) *UserHandler {
+func NewUserHandler(userSvc models.UserSvc, passwordPolicy *models.PasswordPolicy) *UserHandler {
 	return &UserHandler{
-		userSvc: userSvc,
+		userSvc:        userSvc,
+		passwordPolicy: passwordPolicy,
 	}
 }
 
@@ -26,6 +28,10 @@ func (handler *UserHandler) Register(ctx *gin.Context) {
 		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
+	if valid, errs := req.Validate(handler.passwordPolicy); !valid {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": errs})
+		return
+	}
 
 	user, err := handler.userSvc.RegisterUser(&req)
 	if err != nil {
@@ -37,10 +43,11 @@ func (handler *UserHandler) Register(ctx *gin.Context) {
 		return
 	}
 	response := gin.H{
-		"id":         user.ID,
-		"email":      user.Email,
-		"created_at": user.CreatedAt,
-		"updated_at": user.UpdatedAt,
+		"id":                user.ID,
+		"email":             user.Email,
+		"email_verified_at": user.EmailVerifiedAt,
+		"created_at":        user.CreatedAt,
+		"updated_at":        user.UpdatedAt,
 	}
 
 	ctx.JSON(http.StatusCreated, response)
@@ -53,7 +60,7 @@ func (handler *UserHandler) Login(ctx *gin.Context) {
 		return
 	}
 
-	token, err := handler.userSvc.Authenticate(&loginData)
+	tokens, err := handler.userSvc.Authenticate(&loginData)
 	if err != nil {
 		if errors.Is(err, services.ErrInvalidCredentials) {
 			ctx.JSON(http.StatusUnauthorized, gin.H{
@@ -66,10 +73,53 @@ func (handler *UserHandler) Login(ctx *gin.Context) {
 		})
 		return
 	}
-	response := gin.H{
-		"token": token,
+	ctx.JSON(http.StatusOK, tokenPairResponse(tokens))
+}
+
+func (handler *UserHandler) RefreshToken(ctx *gin.Context) {
+	var req models.RefreshTokenRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	tokens, err := handler.userSvc.RefreshSession(req.RefreshToken)
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidToken) || errors.Is(err, services.ErrTokenReused) {
+			ctx.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+	ctx.JSON(http.StatusOK, tokenPairResponse(tokens))
+}
+
+func (handler *UserHandler) Logout(ctx *gin.Context) {
+	var req models.RefreshTokenRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := handler.userSvc.Logout(req.RefreshToken); err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+	ctx.Status(http.StatusNoContent)
+}
+
+func tokenPairResponse(tokens *models.TokenPair) gin.H {
+	return gin.H{
+		// token is the access token under the name login used to return it
+		// as, kept for clients that predate refresh tokens. Deprecated, use
+		// access_token.
+		"token":         tokens.AccessToken,
+		"access_token":  tokens.AccessToken,
+		"refresh_token": tokens.RefreshToken,
+		"token_type":    "Bearer",
+		"expires_in":    int64(tokens.ExpiresIn.Seconds()),
 	}
-	ctx.JSON(http.StatusOK, response)
 }
 
 func (handler *UserHandler) Profile(ctx *gin.Context) {
@@ -91,11 +141,13 @@ func (handler *UserHandler) Profile(ctx *gin.Context) {
 	}
 
 	response := gin.H{
-		"id":         user.ID,
-		"email":      user.Email,
-		"is_admin":   user.IsAdmin,
-		"created_at": user.CreatedAt,
-		"updated_at": user.UpdatedAt,
+		"id":                user.ID,
+		"email":             user.Email,
+		"email_verified_at": user.EmailVerifiedAt,
+		"roles":             user.RoleNames(),
+		"permissions":       user.Permissions(),
+		"created_at":        user.CreatedAt,
+		"updated_at":        user.UpdatedAt,
 	}
 	ctx.JSON(http.StatusOK, response)
 }
@@ -2,10 +2,16 @@
 package handlers
 
 import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/rezbow/ecommerce/internal/app/models"
+	"github.com/rezbow/ecommerce/internal/platform/signing"
 )
 
 const (
@@ -14,7 +20,85 @@ const (
 	MaxLimit     = 100 // Maximum number of items allowed per page
 )
 
-func ExtractPagination(c *gin.Context) models.Pagination {
+var (
+	ErrInvalidCursor     = errors.New("invalid cursor")
+	ErrCursorUnsupported = errors.New("cursors can't be used with this listing, use page instead")
+)
+
+// cursorSecret signs the cursors handed out to clients, so they can't be
+// forged to point anywhere else
+var cursorSecret []byte
+
+// SetCursorSecret sets the key cursors are signed with, it must be called
+// before the router starts serving.
+func SetCursorSecret(secret string) {
+	cursorSecret = []byte(secret)
+}
+
+// cursorTTL bounds how long a next_cursor can be used, listings move on and
+// a stale cursor has nothing useful to resume
+const cursorTTL = time.Hour * 24
+
+// signedCursor is what a cursor string carries: the row to resume after, the
+// listing it was handed out for and when it stops being accepted.
+type signedCursor struct {
+	models.Cursor
+	Listing   string `json:"l"`
+	ExpiresAt int64  `json:"exp"`
+}
+
+// EncodeCursor turns the cursor into the opaque, signed string returned to
+// clients as next_cursor. It is only accepted back on the listing it was
+// handed out for, see cursorListing.
+func EncodeCursor(listing string, cursor models.Cursor) string {
+	return encodeCursor(listing, cursor, time.Now().Add(cursorTTL))
+}
+
+func encodeCursor(listing string, cursor models.Cursor, expiresAt time.Time) string {
+	payload, _ := json.Marshal(signedCursor{Cursor: cursor, Listing: listing, ExpiresAt: expiresAt.Unix()})
+	encoded := base64.RawURLEncoding.EncodeToString(payload)
+	return encoded + "." + signing.Sign(cursorSecret, []byte(encoded))
+}
+
+func decodeCursor(listing string, value string, now time.Time) (*models.Cursor, error) {
+	encoded, signature, found := strings.Cut(value, ".")
+	if !found || !signing.Verify(cursorSecret, []byte(encoded), signature) {
+		return nil, ErrInvalidCursor
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	var cursor signedCursor
+	if err := json.Unmarshal(payload, &cursor); err != nil {
+		return nil, ErrInvalidCursor
+	}
+	if cursor.Listing != listing || now.Unix() >= cursor.ExpiresAt {
+		return nil, ErrInvalidCursor
+	}
+	return &cursor.Cursor, nil
+}
+
+// cursorListing names the listing the request pages through, its path
+// including parameters like a category's slug.
+func cursorListing(c *gin.Context) string {
+	return c.Request.URL.Path
+}
+
+// nextCursor encodes the cursor of the last row of a full page, it is nil
+// once the listing is over.
+func nextCursor(c *gin.Context, pagination *models.Pagination, rows int, last func() models.Cursor) *string {
+	if !pagination.Full(rows) {
+		return nil
+	}
+	cursor := EncodeCursor(cursorListing(c), last())
+	return &cursor
+}
+
+// ExtractPagination reads page and limit, or a cursor handed out as
+// next_cursor by an earlier page, from the query. It only fails when the
+// cursor was tampered with, expired or was handed out for another listing.
+func ExtractPagination(c *gin.Context) (models.Pagination, error) {
 
 	// Default values
 	page := DefaultPage
@@ -46,9 +130,32 @@ func ExtractPagination(c *gin.Context) models.Pagination {
 		offset = 0 // Should only happen if DefaultPage was changed to 0 or less
 	}
 
-	return models.Pagination{
+	pagination := models.Pagination{
 		Page:   page,
 		Limit:  limit,
 		Offset: offset,
 	}
+
+	// 5. Parse 'cursor' parameter, it takes over from the page
+	if cursorStr := c.Query("cursor"); cursorStr != "" {
+		cursor, err := decodeCursor(cursorListing(c), cursorStr, time.Now())
+		if err != nil {
+			return pagination, err
+		}
+		pagination.After = cursor
+	}
+	return pagination, nil
+}
+
+// extractOffsetPagination is ExtractPagination for listings that can only be
+// paged by offset.
+func extractOffsetPagination(c *gin.Context) (models.Pagination, error) {
+	pagination, err := ExtractPagination(c)
+	if err != nil {
+		return pagination, err
+	}
+	if pagination.After != nil {
+		return pagination, ErrCursorUnsupported
+	}
+	return pagination, nil
 }
@@ -0,0 +1,160 @@
+package handlers
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/rezbow/ecommerce/internal/app/models"
+)
+
+func setTestCursorSecret(t *testing.T, secret string) {
+	t.Helper()
+	previous := cursorSecret
+	SetCursorSecret(secret)
+	t.Cleanup(func() { cursorSecret = previous })
+}
+
+// extractTestPagination runs ExtractPagination for a request to target.
+func extractTestPagination(target string) (models.Pagination, error) {
+	gin.SetMode(gin.TestMode)
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	c.Request = httptest.NewRequest(http.MethodGet, target, nil)
+	return ExtractPagination(c)
+}
+
+func TestCursorRoundTrip(t *testing.T) {
+	setTestCursorSecret(t, "cursor secret")
+	cursor := models.Cursor{CreatedAt: time.Date(2026, 5, 4, 3, 2, 1, 123456000, time.UTC), ID: uuid.New()}
+
+	encoded := EncodeCursor("/products", cursor)
+	pagination, err := extractTestPagination("/products?limit=20&cursor=" + url.QueryEscape(encoded))
+	if err != nil {
+		t.Fatalf("ExtractPagination: %v", err)
+	}
+	if pagination.After == nil {
+		t.Fatal("pagination has no cursor")
+	}
+	if !pagination.After.CreatedAt.Equal(cursor.CreatedAt) || pagination.After.ID != cursor.ID {
+		t.Errorf("cursor is %v, want %v", *pagination.After, cursor)
+	}
+	if pagination.Limit != 20 {
+		t.Errorf("limit is %d, want 20", pagination.Limit)
+	}
+}
+
+func TestCursorRejected(t *testing.T) {
+	setTestCursorSecret(t, "cursor secret")
+	now := time.Now()
+	cursor := models.Cursor{CreatedAt: now, ID: uuid.New()}
+	valid := encodeCursor("/products", cursor, now.Add(time.Hour))
+	encoded, signature, _ := strings.Cut(valid, ".")
+
+	// the same cursor moved to another row, under the original signature
+	tampered, _ := json.Marshal(signedCursor{
+		Cursor:    models.Cursor{CreatedAt: now.Add(-time.Hour), ID: cursor.ID},
+		Listing:   "/products",
+		ExpiresAt: now.Add(time.Hour).Unix(),
+	})
+	tests := []struct {
+		name    string
+		listing string
+		cursor  func() string
+	}{
+		{
+			name:    "tampered payload",
+			listing: "/products",
+			cursor: func() string {
+				return base64.RawURLEncoding.EncodeToString(tampered) + "." + signature
+			},
+		},
+		{
+			name:    "tampered signature",
+			listing: "/products",
+			cursor: func() string {
+				return encoded + "." + strings.Repeat("0", len(signature))
+			},
+		},
+		{
+			name:    "missing signature",
+			listing: "/products",
+			cursor:  func() string { return encoded },
+		},
+		{
+			name:    "not a cursor",
+			listing: "/products",
+			cursor:  func() string { return "garbage" },
+		},
+		{
+			name:    "signed with another secret",
+			listing: "/products",
+			cursor: func() string {
+				SetCursorSecret("other secret")
+				defer SetCursorSecret("cursor secret")
+				return encodeCursor("/products", cursor, now.Add(time.Hour))
+			},
+		},
+		{
+			name:    "expired",
+			listing: "/products",
+			cursor: func() string {
+				return encodeCursor("/products", cursor, now.Add(-time.Second))
+			},
+		},
+		{
+			name:    "another listing",
+			listing: "/orders",
+			cursor:  func() string { return valid },
+		},
+		{
+			name:    "another category",
+			listing: "/categories/shoes/products",
+			cursor: func() string {
+				return encodeCursor("/categories/shirts/products", cursor, now.Add(time.Hour))
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := extractTestPagination(tt.listing + "?cursor=" + url.QueryEscape(tt.cursor()))
+			if !errors.Is(err, ErrInvalidCursor) {
+				t.Errorf("ExtractPagination error is %v, want %v", err, ErrInvalidCursor)
+			}
+		})
+	}
+}
+
+func TestCursorUnsupported(t *testing.T) {
+	setTestCursorSecret(t, "cursor secret")
+	cursor := url.QueryEscape(EncodeCursor("/products", models.Cursor{CreatedAt: time.Now(), ID: uuid.New()}))
+
+	t.Run("offset only listing", func(t *testing.T) {
+		gin.SetMode(gin.TestMode)
+		c, _ := gin.CreateTestContext(httptest.NewRecorder())
+		c.Request = httptest.NewRequest(http.MethodGet, "/products?cursor="+cursor, nil)
+		if _, err := extractOffsetPagination(c); !errors.Is(err, ErrCursorUnsupported) {
+			t.Errorf("extractOffsetPagination error is %v, want %v", err, ErrCursorUnsupported)
+		}
+	})
+
+	// cursors only point into the newest first order of products
+	for _, sort := range []models.ProductSort{models.ProductSortPrice, models.ProductSortName, models.ProductSortPopularity} {
+		t.Run("sorted by "+string(sort), func(t *testing.T) {
+			router := gin.New()
+			router.GET("/products", NewProductHandler(nil).ListProducts)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/products?currency=USD&sort="+url.QueryEscape(string(sort))+"&cursor="+cursor, nil))
+			if w.Code != http.StatusBadRequest || !strings.Contains(w.Body.String(), ErrCursorUnsupported.Error()) {
+				t.Errorf("response is %d %s, want %d with %q", w.Code, w.Body, http.StatusBadRequest, ErrCursorUnsupported)
+			}
+		})
+	}
+}
@@ -0,0 +1,49 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rezbow/ecommerce/internal/app/models"
+	"github.com/rezbow/ecommerce/internal/app/services"
+)
+
+type WebhookHandler struct {
+	paymentSvc services.IPaymentService
+}
+
+func NewWebhookHandler(paymentSvc services.IPaymentService) *WebhookHandler {
+	return &WebhookHandler{
+		paymentSvc: paymentSvc,
+	}
+}
+
+func (handler *WebhookHandler) PaymentEvent(ctx *gin.Context) {
+	var event models.PaymentEvent
+	if err := ctx.ShouldBindJSON(&event); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if valid, errs := event.Validate(); !valid {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": errs})
+		return
+	}
+
+	if err := handler.paymentSvc.HandleEvent(&event); err != nil {
+		switch {
+		case errors.Is(err, services.ErrDuplicateEvent):
+			// the provider only needs to know we have it
+			ctx.JSON(http.StatusOK, gin.H{"message": err.Error()})
+		case errors.Is(err, services.ErrPaymentNotFound), errors.Is(err, services.ErrOrderNotFound):
+			ctx.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		case errors.Is(err, services.ErrOrderModified):
+			ctx.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		default:
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		}
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"message": "ok"})
+}
@@ -0,0 +1,82 @@
+package models
+
+import (
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type Address struct {
+	ID         uuid.UUID
+	UserId     uuid.UUID
+	FullName   string
+	Line1      string
+	Line2      *string
+	City       string
+	Region     *string
+	PostalCode string
+	// Country is an ISO 3166-1 alpha-2 code
+	Country   string
+	Phone     *string
+	IsDefault bool
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+func (a *Address) TaxDestination() TaxDestination {
+	destination := TaxDestination{Country: a.Country}
+	if a.Region != nil {
+		destination.Region = *a.Region
+	}
+	return destination
+}
+
+// AddressSnapshot is a frozen copy of an address stored on an order, later
+// edits of the address don't change it.
+type AddressSnapshot struct {
+	FullName   string  `json:"full_name"`
+	Line1      string  `json:"line1"`
+	Line2      *string `json:"line2,omitempty"`
+	City       string  `json:"city"`
+	Region     *string `json:"region,omitempty"`
+	PostalCode string  `json:"postal_code"`
+	Country    string  `json:"country"`
+	Phone      *string `json:"phone,omitempty"`
+}
+
+func (a *Address) Snapshot() *AddressSnapshot {
+	return &AddressSnapshot{
+		FullName:   a.FullName,
+		Line1:      a.Line1,
+		Line2:      a.Line2,
+		City:       a.City,
+		Region:     a.Region,
+		PostalCode: a.PostalCode,
+		Country:    a.Country,
+		Phone:      a.Phone,
+	}
+}
+
+func (s *AddressSnapshot) TaxDestination() TaxDestination {
+	destination := TaxDestination{Country: s.Country}
+	if s.Region != nil {
+		destination.Region = *s.Region
+	}
+	return destination
+}
+
+// String formats the address the way it is printed on a shipping label.
+func (s *AddressSnapshot) String() string {
+	lines := []string{s.FullName, s.Line1}
+	if s.Line2 != nil && *s.Line2 != "" {
+		lines = append(lines, *s.Line2)
+	}
+	cityLine := s.City
+	if s.Region != nil && *s.Region != "" {
+		cityLine += ", " + *s.Region
+	}
+	cityLine += " " + s.PostalCode
+	lines = append(lines, cityLine, s.Country)
+	return strings.Join(lines, "\n")
+}
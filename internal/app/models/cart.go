@@ -4,17 +4,67 @@ import (
 	"github.com/google/uuid"
 )
 
+type DiscountSource string
+
+const (
+	DiscountSourceCoupon    DiscountSource = "coupon"
+	DiscountSourcePromotion DiscountSource = "promotion"
+)
+
 type Cart struct {
-	UserId uuid.UUID   `json:"user_id"`
-	Items  []*CartItem `json:"items"`
-	Total  int64       `json:"total"`
+	UserId uuid.UUID `json:"user_id"`
+	// Currency is shared by every price in the cart
+	Currency string      `json:"currency"`
+	Items    []*CartItem `json:"items"`
+	// Total is the sum of the items before any discount
+	Total  Money       `json:"total"`
+	Coupon *CartCoupon `json:"coupon,omitempty"`
+	// Discounts are recomputed every time the cart is priced
+	Discounts       []CartDiscount `json:"discounts"`
+	DiscountedTotal Money          `json:"discounted_total"`
+	FreeShipping    bool           `json:"free_shipping"`
+	// Tax is only known once the cart is priced for a destination
+	Tax          *TaxBreakdown `json:"tax,omitempty"`
+	TotalWithTax Money         `json:"total_with_tax"`
+}
+
+// CartCoupon is the coupon code the user applied to the cart.
+type CartCoupon struct {
+	ID   uuid.UUID `json:"id"`
+	Code string    `json:"code"`
+}
+
+// CartDiscount sums up what a single coupon or promotion took off the cart.
+// Code is the coupon code or the name of the promotion.
+type CartDiscount struct {
+	Source       DiscountSource `json:"source"`
+	Code         string         `json:"code"`
+	PromotionId  *uuid.UUID     `json:"promotion_id,omitempty"`
+	Amount       Money          `json:"amount"`
+	FreeShipping bool           `json:"free_shipping,omitempty"`
 }
 
-func NewCart(userId uuid.UUID) *Cart {
-	return &Cart{
+// LineDiscount is the part of a CartDiscount taken off a single cart line.
+type LineDiscount struct {
+	Source      DiscountSource `json:"source"`
+	Code        string         `json:"code"`
+	PromotionId *uuid.UUID     `json:"promotion_id,omitempty"`
+	Amount      Money          `json:"amount"`
+}
+
+func NewCart(userId uuid.UUID, currency string) *Cart {
+	cart := &Cart{
 		UserId: userId,
 		Items:  make([]*CartItem, 0),
 	}
+	cart.SetCurrency(currency)
+	return cart
+}
+
+// SetCurrency switches the currency of an empty cart.
+func (c *Cart) SetCurrency(currency string) {
+	c.Currency = currency
+	c.update()
 }
 
 func (c *Cart) SetItems(items []*CartItem) {
@@ -23,16 +73,109 @@ func (c *Cart) SetItems(items []*CartItem) {
 }
 
 func (c *Cart) update() {
-	var total int64
+	total := Zero(c.Currency)
 	for _, item := range c.Items {
-		total += item.SubTotal
+		total = total.Add(item.SubTotal)
 	}
 	c.Total = total
+	// any change to the items makes the discounts and the tax stale
+	for _, item := range c.Items {
+		item.Discounts = nil
+	}
+	c.Discounts = make([]CartDiscount, 0)
+	c.DiscountedTotal = total
+	c.FreeShipping = false
+	c.Tax = nil
+	c.TotalWithTax = total
 }
 
-func (c *Cart) Remove(productId uuid.UUID) bool {
+// ApplyDiscount takes amount off the cart, spread over the lines in
+// proportion to what is left of them. The discount never takes the cart
+// below zero.
+func (c *Cart) ApplyDiscount(source DiscountSource, code string, amount Money) {
+	bases := make([]int64, len(c.Items))
 	for idx, item := range c.Items {
-		if item.ProductId == productId {
+		bases[idx] = item.DiscountedSubTotal().Amount
+	}
+	c.applyShares(CartDiscount{Source: source, Code: code}, allocate(amount.Amount, bases))
+}
+
+// applyShares takes shares[idx] off the idx-th line and records the sum of
+// them as discount.
+func (c *Cart) applyShares(discount CartDiscount, shares []int64) {
+	total := Zero(c.Currency)
+	for idx, item := range c.Items {
+		if shares[idx] <= 0 {
+			continue
+		}
+		amount := NewMoney(shares[idx], c.Currency)
+		item.Discounts = append(item.Discounts, LineDiscount{
+			Source:      discount.Source,
+			Code:        discount.Code,
+			PromotionId: discount.PromotionId,
+			Amount:      amount,
+		})
+		total = total.Add(amount)
+	}
+	if total.IsZero() {
+		return
+	}
+	discount.Amount = total
+	c.addDiscount(discount)
+}
+
+// allocate splits amount over bases in proportion to them, never giving a
+// base more than itself. The shares sum up to amount, or to the sum of the
+// bases when amount is larger.
+func allocate(amount int64, bases []int64) []int64 {
+	var sum int64
+	for _, base := range bases {
+		sum += base
+	}
+	shares := make([]int64, len(bases))
+	if amount <= 0 || sum <= 0 {
+		return shares
+	}
+	if amount > sum {
+		amount = sum
+	}
+	left := amount
+	for idx, base := range bases {
+		shares[idx] = amount * base / sum
+		left -= shares[idx]
+	}
+	// rounding leftovers go to the first bases that still have room
+	for idx, base := range bases {
+		extra := min(left, base-shares[idx])
+		shares[idx] += extra
+		left -= extra
+	}
+	return shares
+}
+
+// WaiveShipping makes the shipping of the order free.
+func (c *Cart) WaiveShipping(source DiscountSource, code string) {
+	c.FreeShipping = true
+	c.addDiscount(CartDiscount{Source: source, Code: code, Amount: Zero(c.Currency), FreeShipping: true})
+}
+
+func (c *Cart) addDiscount(discount CartDiscount) {
+	c.Discounts = append(c.Discounts, discount)
+	c.DiscountedTotal = c.DiscountedTotal.Sub(discount.Amount)
+	// tax is charged on the discounted prices
+	c.Tax = nil
+	c.TotalWithTax = c.DiscountedTotal
+}
+
+func (c *Cart) SetTax(breakdown *TaxBreakdown) {
+	c.Tax = breakdown
+	c.TotalWithTax = c.DiscountedTotal.Add(breakdown.Payable())
+}
+
+// Remove takes the line with lineId off the cart, see CartItem.LineId.
+func (c *Cart) Remove(lineId uuid.UUID) bool {
+	for idx, item := range c.Items {
+		if item.LineId() == lineId {
 			c.Items = append(c.Items[:idx], c.Items[idx+1:]...)
 			c.update()
 			return true
@@ -41,34 +184,56 @@ func (c *Cart) Remove(productId uuid.UUID) bool {
 	return false
 }
 
-func (c *Cart) findItem(id uuid.UUID) *CartItem {
+func (c *Cart) findItem(lineId uuid.UUID) *CartItem {
 	for _, item := range c.Items {
-		if item.ProductId == id {
+		if item.LineId() == lineId {
 			return item
 		}
 	}
 	return nil
 }
 
-func (c *Cart) ItemQuantity(id uuid.UUID) int {
-	if item := c.findItem(id); item != nil {
+// CartLine is what the user sees of a line before paying for it.
+type CartLine struct {
+	Quantity int
+	Price    Money
+}
+
+// Lines maps the id of every line to its quantity and unit price, see
+// CartItem.LineId.
+func (c *Cart) Lines() map[uuid.UUID]CartLine {
+	lines := make(map[uuid.UUID]CartLine, len(c.Items))
+	for _, item := range c.Items {
+		lines[item.LineId()] = CartLine{Quantity: item.Quantity, Price: item.Price}
+	}
+	return lines
+}
+
+func (c *Cart) ItemQuantity(lineId uuid.UUID) int {
+	if item := c.findItem(lineId); item != nil {
 		return item.Quantity
 	}
 	return 0
 }
 
-func (c *Cart) AddQuantityOrInsert(product *Product, quantity int) {
-	if item := c.findItem(product.ID); item != nil {
+// AddQuantityOrInsert adds quantity units of the product, or of its variant
+// when it has one, at price, which must be in the cart's currency.
+func (c *Cart) AddQuantityOrInsert(product *Product, variant *ProductVariant, price Money, quantity int) {
+	lineId := product.ID
+	if variant != nil {
+		lineId = variant.ID
+	}
+	if item := c.findItem(lineId); item != nil {
 		item.Quantity += quantity
-		item.SubTotal = int64(item.Quantity) * item.Price
+		item.SubTotal = item.Price.Mul(int64(item.Quantity))
 		c.update()
 		return
 	}
-	item := NewCartItem(product.ID)
-	item.Name = product.Name
-	item.Price = product.Price
+	item := NewCartItem(product.ID, nil)
+	item.Describe(product, variant)
+	item.Price = price
 	item.Quantity = quantity
-	item.SubTotal = int64(item.Quantity) * item.Price
+	item.SubTotal = item.Price.Mul(int64(item.Quantity))
 
 	c.Items = append(c.Items, item)
 	c.update()
@@ -76,14 +241,63 @@ func (c *Cart) AddQuantityOrInsert(product *Product, quantity int) {
 
 type CartItem struct {
 	ProductId uuid.UUID `json:"product_id"`
-	Quantity  int       `json:"quantity"`
-	Name      string    `json:"name"`
-	Price     int64     `json:"price"`
-	SubTotal  int64     `json:"subtotal"`
+	// VariantId and Sku are only set for products sold through variants
+	VariantId *uuid.UUID `json:"variant_id,omitempty"`
+	Sku       string     `json:"sku,omitempty"`
+	Quantity  int        `json:"quantity"`
+	Name      string     `json:"name"`
+	Price     Money      `json:"price"`
+	SubTotal  Money      `json:"subtotal"`
+	TaxClass  string     `json:"tax_class"`
+	// CategoryIds holds the product's categories and all their ancestors, it
+	// is only known while the cart is priced
+	CategoryIds []uuid.UUID `json:"-"`
+	// Discounts lists every discount taken off the line
+	Discounts []LineDiscount `json:"discounts,omitempty"`
+}
+
+// DiscountTotal sums up every discount taken off the line.
+func (i *CartItem) DiscountTotal() Money {
+	total := Zero(i.SubTotal.Currency)
+	for _, discount := range i.Discounts {
+		total = total.Add(discount.Amount)
+	}
+	return total
+}
+
+func (i *CartItem) DiscountedSubTotal() Money {
+	return i.SubTotal.Sub(i.DiscountTotal())
+}
+
+// LineId identifies the line within the cart, it is the variant's id for
+// variants and the product's id otherwise.
+func (i *CartItem) LineId() uuid.UUID {
+	if i.VariantId != nil {
+		return *i.VariantId
+	}
+	return i.ProductId
+}
+
+// Describe copies the live details of the product and its variant onto the
+// line.
+func (i *CartItem) Describe(product *Product, variant *ProductVariant) {
+	i.Name = product.Name
+	i.TaxClass = product.TaxClass
+	i.CategoryIds = product.CategoryIds()
+	i.VariantId = nil
+	i.Sku = ""
+	if variant != nil {
+		i.VariantId = &variant.ID
+		i.Sku = variant.Sku
+		if title := variant.Title(product); title != "" {
+			i.Name = product.Name + " - " + title
+		}
+	}
 }
 
-func NewCartItem(productId uuid.UUID) *CartItem {
+func NewCartItem(productId uuid.UUID, variantId *uuid.UUID) *CartItem {
 	return &CartItem{
 		ProductId: productId,
+		VariantId: variantId,
 	}
 }
@@ -0,0 +1,86 @@
+package models
+
+import (
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type Category struct {
+	ID uuid.UUID
+	// ParentId is nil for top level categories
+	ParentId    *uuid.UUID
+	Name        string
+	Slug        string
+	Description *string
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+}
+
+// CategoryNode is a category along with its whole subtree.
+type CategoryNode struct {
+	ID          uuid.UUID       `json:"id"`
+	Name        string          `json:"name"`
+	Slug        string          `json:"slug"`
+	Description *string         `json:"description,omitempty"`
+	Children    []*CategoryNode `json:"children"`
+}
+
+// BuildCategoryTree nests the categories under their parents and returns the
+// top level ones. Siblings are sorted by name. Categories whose parent is not
+// in the list end up at the top level.
+func BuildCategoryTree(categories []Category) []*CategoryNode {
+	nodes := make(map[uuid.UUID]*CategoryNode, len(categories))
+	for _, category := range categories {
+		nodes[category.ID] = &CategoryNode{
+			ID:          category.ID,
+			Name:        category.Name,
+			Slug:        category.Slug,
+			Description: category.Description,
+			Children:    make([]*CategoryNode, 0),
+		}
+	}
+
+	roots := make([]*CategoryNode, 0)
+	for _, category := range categories {
+		node := nodes[category.ID]
+		if category.ParentId != nil {
+			if parent, ok := nodes[*category.ParentId]; ok {
+				parent.Children = append(parent.Children, node)
+				continue
+			}
+		}
+		roots = append(roots, node)
+	}
+	sortCategoryNodes(roots)
+	return roots
+}
+
+func sortCategoryNodes(nodes []*CategoryNode) {
+	sort.Slice(nodes, func(i, j int) bool {
+		return nodes[i].Name < nodes[j].Name
+	})
+	for _, node := range nodes {
+		sortCategoryNodes(node.Children)
+	}
+}
+
+// WithAncestors returns ids along with the ids of every ancestor of those
+// categories, each listed once.
+func WithAncestors(categories []Category, ids []uuid.UUID) []uuid.UUID {
+	parents := make(map[uuid.UUID]*uuid.UUID, len(categories))
+	for _, category := range categories {
+		parents[category.ID] = category.ParentId
+	}
+
+	seen := make(map[uuid.UUID]bool)
+	result := make([]uuid.UUID, 0, len(ids))
+	for _, id := range ids {
+		for current := &id; current != nil && !seen[*current]; current = parents[*current] {
+			seen[*current] = true
+			result = append(result, *current)
+		}
+	}
+	return result
+}
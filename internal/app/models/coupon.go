@@ -0,0 +1,96 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type CouponType string
+
+const (
+	// CouponTypePercentage takes PercentBps off the cart
+	CouponTypePercentage CouponType = "percentage"
+	// CouponTypeFixed takes FixedAmount off the cart
+	CouponTypeFixed CouponType = "fixed"
+	// CouponTypeFreeShipping waives the shipping cost of the order
+	CouponTypeFreeShipping CouponType = "free_shipping"
+)
+
+func (t CouponType) IsValid() bool {
+	switch t {
+	case CouponTypePercentage, CouponTypeFixed, CouponTypeFreeShipping:
+		return true
+	}
+	return false
+}
+
+type Coupon struct {
+	ID   uuid.UUID
+	Code string
+	Type CouponType
+	// PercentBps is the discount of percentage coupons in hundredths of a
+	// percent
+	PercentBps  int64
+	FixedAmount Money `gorm:"embedded;embeddedPrefix:fixed_"`
+	// MinTotal is the cart total the coupon needs, carts in another currency
+	// can't use the coupon. A zero MinTotal has no minimum.
+	MinTotal Money `gorm:"embedded;embeddedPrefix:min_total_"`
+	StartsAt *time.Time
+	EndsAt   *time.Time
+	// MaxRedemptions and MaxRedemptionsPerUser are unlimited when nil
+	MaxRedemptions        *int
+	MaxRedemptionsPerUser *int
+	Redemptions           int
+	CreatedAt             time.Time
+	UpdatedAt             time.Time
+}
+
+// ActiveAt tells whether now falls in the coupon's validity window.
+func (c *Coupon) ActiveAt(now time.Time) bool {
+	if c.StartsAt != nil && now.Before(*c.StartsAt) {
+		return false
+	}
+	if c.EndsAt != nil && !now.Before(*c.EndsAt) {
+		return false
+	}
+	return true
+}
+
+// AppliesTo tells whether the coupon can be used for amounts in currency.
+func (c *Coupon) AppliesTo(currency string) bool {
+	if c.Type == CouponTypeFixed && c.FixedAmount.Currency != currency {
+		return false
+	}
+	return c.MinTotal.IsZero() || c.MinTotal.Currency == currency
+}
+
+// Discount is what the coupon takes off subtotal, never more than subtotal.
+// Free shipping coupons and coupons in another currency don't discount the
+// goods.
+func (c *Coupon) Discount(subtotal Money) Money {
+	discount := Zero(subtotal.Currency)
+	switch c.Type {
+	case CouponTypePercentage:
+		discount = NewMoney((subtotal.Amount*c.PercentBps+5000)/10000, subtotal.Currency)
+	case CouponTypeFixed:
+		discount = c.FixedAmount
+	}
+	exceeds, err := subtotal.CheckedLessThan(discount)
+	if err != nil {
+		return Zero(subtotal.Currency)
+	}
+	if exceeds {
+		return subtotal
+	}
+	return discount
+}
+
+// CouponRedemption records that an order used a coupon.
+type CouponRedemption struct {
+	ID        uuid.UUID
+	CouponId  uuid.UUID
+	UserId    uuid.UUID
+	OrderId   uuid.UUID
+	CreatedAt time.Time
+}
@@ -0,0 +1,129 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+var ErrCurrencyMismatch = errors.New("currency mismatch")
+
+// Money is an amount in the minor units of its currency, e.g. cents for USD.
+// The zero value has no currency and can be added to any amount.
+type Money struct {
+	Amount   int64  `json:"amount"`
+	Currency string `json:"currency"`
+}
+
+func NewMoney(amount int64, currency string) Money {
+	return Money{Amount: amount, Currency: strings.ToUpper(currency)}
+}
+
+// Zero is a zero amount in the given currency.
+func Zero(currency string) Money {
+	return NewMoney(0, currency)
+}
+
+func (m Money) IsZero() bool {
+	return m.Amount == 0
+}
+
+// SameCurrency tells whether m and other can be combined, the zero value is
+// compatible with any currency.
+func (m Money) SameCurrency(other Money) bool {
+	return m.Currency == other.Currency ||
+		(m.Currency == "" && m.Amount == 0) ||
+		(other.Currency == "" && other.Amount == 0)
+}
+
+// Add sums both amounts. Mixing currencies is a programming error, so it
+// panics instead of producing a meaningless sum. Amounts whose currencies
+// aren't known to match go through CheckedAdd instead.
+func (m Money) Add(other Money) Money {
+	return NewMoney(m.Amount+other.Amount, m.currencyWith(other))
+}
+
+// CheckedAdd sums both amounts, it fails with ErrCurrencyMismatch on mixed
+// currencies.
+func (m Money) CheckedAdd(other Money) (Money, error) {
+	currency, err := m.checkedCurrencyWith(other)
+	if err != nil {
+		return Money{}, err
+	}
+	return NewMoney(m.Amount+other.Amount, currency), nil
+}
+
+// Sub subtracts other from m, it panics on mixed currencies like Add.
+func (m Money) Sub(other Money) Money {
+	return NewMoney(m.Amount-other.Amount, m.currencyWith(other))
+}
+
+// CheckedSub subtracts other from m, it fails with ErrCurrencyMismatch on
+// mixed currencies.
+func (m Money) CheckedSub(other Money) (Money, error) {
+	currency, err := m.checkedCurrencyWith(other)
+	if err != nil {
+		return Money{}, err
+	}
+	return NewMoney(m.Amount-other.Amount, currency), nil
+}
+
+func (m Money) Mul(factor int64) Money {
+	return Money{Amount: m.Amount * factor, Currency: m.Currency}
+}
+
+// Div divides the amount, truncating any remainder.
+func (m Money) Div(divisor int64) Money {
+	return Money{Amount: m.Amount / divisor, Currency: m.Currency}
+}
+
+// LessThan compares both amounts, it panics on mixed currencies like Add.
+func (m Money) LessThan(other Money) bool {
+	m.currencyWith(other)
+	return m.Amount < other.Amount
+}
+
+// CheckedLessThan compares both amounts, it fails with ErrCurrencyMismatch on
+// mixed currencies.
+func (m Money) CheckedLessThan(other Money) (bool, error) {
+	if _, err := m.checkedCurrencyWith(other); err != nil {
+		return false, err
+	}
+	return m.Amount < other.Amount, nil
+}
+
+func (m Money) String() string {
+	return fmt.Sprintf("%d %s", m.Amount, m.Currency)
+}
+
+func (m Money) currencyWith(other Money) string {
+	currency, err := m.checkedCurrencyWith(other)
+	if err != nil {
+		panic(err)
+	}
+	return currency
+}
+
+// checkedCurrencyWith is the currency of combining m and other.
+func (m Money) checkedCurrencyWith(other Money) (string, error) {
+	if !m.SameCurrency(other) {
+		return "", fmt.Errorf("%w: %s and %s", ErrCurrencyMismatch, m, other)
+	}
+	if m.Currency == "" {
+		return other.Currency, nil
+	}
+	return m.Currency, nil
+}
+
+// ValidCurrency checks for a three letter ISO 4217 style code.
+func ValidCurrency(currency string) bool {
+	if len(currency) != 3 {
+		return false
+	}
+	for _, r := range currency {
+		if (r < 'A' || r > 'Z') && (r < 'a' || r > 'z') {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,160 @@
+package models
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestMoneyArithmetic(t *testing.T) {
+	tests := []struct {
+		name    string
+		a, b    Money
+		sum     Money
+		diff    Money
+		less    bool
+		wantErr bool
+	}{
+		{
+			name: "same currency",
+			a:    NewMoney(1500, "USD"),
+			b:    NewMoney(500, "usd"),
+			sum:  NewMoney(2000, "USD"),
+			diff: NewMoney(1000, "USD"),
+		},
+		{
+			name: "negative difference",
+			a:    NewMoney(500, "EUR"),
+			b:    NewMoney(1500, "EUR"),
+			sum:  NewMoney(2000, "EUR"),
+			diff: NewMoney(-1000, "EUR"),
+			less: true,
+		},
+		{
+			name: "zero value takes the other currency",
+			a:    Money{},
+			b:    NewMoney(700, "GBP"),
+			sum:  NewMoney(700, "GBP"),
+			diff: NewMoney(-700, "GBP"),
+			less: true,
+		},
+		{
+			name: "zero value on the right keeps the currency",
+			a:    NewMoney(700, "GBP"),
+			b:    Money{},
+			sum:  NewMoney(700, "GBP"),
+			diff: NewMoney(700, "GBP"),
+		},
+		{
+			name:    "mixed currencies",
+			a:       NewMoney(700, "USD"),
+			b:       NewMoney(700, "EUR"),
+			wantErr: true,
+		},
+		{
+			name:    "zero amount in a currency still has to match",
+			a:       NewMoney(700, "USD"),
+			b:       Zero("EUR"),
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sum, err := tt.a.CheckedAdd(tt.b)
+			if tt.wantErr {
+				if !errors.Is(err, ErrCurrencyMismatch) {
+					t.Errorf("CheckedAdd error is %v, want %v", err, ErrCurrencyMismatch)
+				}
+			} else if err != nil || sum != tt.sum {
+				t.Errorf("CheckedAdd is %s, %v, want %s", sum, err, tt.sum)
+			}
+
+			diff, err := tt.a.CheckedSub(tt.b)
+			if tt.wantErr {
+				if !errors.Is(err, ErrCurrencyMismatch) {
+					t.Errorf("CheckedSub error is %v, want %v", err, ErrCurrencyMismatch)
+				}
+			} else if err != nil || diff != tt.diff {
+				t.Errorf("CheckedSub is %s, %v, want %s", diff, err, tt.diff)
+			}
+
+			less, err := tt.a.CheckedLessThan(tt.b)
+			if tt.wantErr {
+				if !errors.Is(err, ErrCurrencyMismatch) {
+					t.Errorf("CheckedLessThan error is %v, want %v", err, ErrCurrencyMismatch)
+				}
+			} else if err != nil || less != tt.less {
+				t.Errorf("CheckedLessThan is %t, %v, want %t", less, err, tt.less)
+			}
+
+			if tt.wantErr {
+				assertPanicsWithMismatch(t, "Add", func() { tt.a.Add(tt.b) })
+				assertPanicsWithMismatch(t, "Sub", func() { tt.a.Sub(tt.b) })
+				assertPanicsWithMismatch(t, "LessThan", func() { tt.a.LessThan(tt.b) })
+				return
+			}
+			if got := tt.a.Add(tt.b); got != tt.sum {
+				t.Errorf("Add is %s, want %s", got, tt.sum)
+			}
+			if got := tt.a.Sub(tt.b); got != tt.diff {
+				t.Errorf("Sub is %s, want %s", got, tt.diff)
+			}
+			if got := tt.a.LessThan(tt.b); got != tt.less {
+				t.Errorf("LessThan is %t, want %t", got, tt.less)
+			}
+		})
+	}
+}
+
+func assertPanicsWithMismatch(t *testing.T, name string, f func()) {
+	t.Helper()
+	defer func() {
+		err, ok := recover().(error)
+		if !ok || !errors.Is(err, ErrCurrencyMismatch) {
+			t.Errorf("%s panicked with %v, want %v", name, err, ErrCurrencyMismatch)
+		}
+	}()
+	f()
+}
+
+func TestMoneyMulDiv(t *testing.T) {
+	price := NewMoney(333, "USD")
+	if got := price.Mul(3); got != NewMoney(999, "USD") {
+		t.Errorf("Mul is %s, want 999 USD", got)
+	}
+	if got := price.Div(2); got != NewMoney(166, "USD") {
+		t.Errorf("Div is %s, want 166 USD", got)
+	}
+}
+
+func TestValidCurrency(t *testing.T) {
+	tests := []struct {
+		currency string
+		want     bool
+	}{
+		{currency: "USD", want: true},
+		{currency: "eur", want: true},
+		{currency: "US", want: false},
+		{currency: "USDT", want: false},
+		{currency: "U5D", want: false},
+		{currency: "", want: false},
+	}
+	for _, tt := range tests {
+		if got := ValidCurrency(tt.currency); got != tt.want {
+			t.Errorf("ValidCurrency(%q) is %t, want %t", tt.currency, got, tt.want)
+		}
+	}
+}
+
+func TestParcelAddRejectsOtherCurrencies(t *testing.T) {
+	parcel := NewParcel("USD")
+	product := &Product{WeightGrams: 200}
+	if err := parcel.Add(product, 2, NewMoney(1000, "USD")); err != nil {
+		t.Fatalf("Add: %v", err)
+	}
+	if err := parcel.Add(product, 1, NewMoney(500, "EUR")); !errors.Is(err, ErrCurrencyMismatch) {
+		t.Fatalf("Add error is %v, want %v", err, ErrCurrencyMismatch)
+	}
+	if parcel.Subtotal != NewMoney(1000, "USD") || parcel.WeightGrams != 400 {
+		t.Errorf("parcel is %s, %dg, want 1000 USD, 400g", parcel.Subtotal, parcel.WeightGrams)
+	}
+}
@@ -0,0 +1,244 @@
+package models
+
+import (
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type OrderStatus string
+
+const (
+	OrderStatusPending   OrderStatus = "pending"
+	OrderStatusPaid      OrderStatus = "paid"
+	OrderStatusFulfilled OrderStatus = "fulfilled"
+	OrderStatusShipped   OrderStatus = "shipped"
+	OrderStatusDelivered OrderStatus = "delivered"
+	OrderStatusCancelled OrderStatus = "cancelled"
+	OrderStatusRefunded  OrderStatus = "refunded"
+	// OrderStatusPartiallyRefunded part of the payment was given back, more
+	// of it can still be refunded
+	OrderStatusPartiallyRefunded OrderStatus = "partially_refunded"
+)
+
+// orderStatusTransitions lists, for every status, the statuses an order is
+// allowed to move to next. Statuses without an entry are final. Further
+// partial refunds leave a partially refunded order where it is.
+var orderStatusTransitions = map[OrderStatus][]OrderStatus{
+	OrderStatusPending:           {OrderStatusPaid, OrderStatusCancelled},
+	OrderStatusPaid:              {OrderStatusFulfilled, OrderStatusCancelled, OrderStatusRefunded, OrderStatusPartiallyRefunded},
+	OrderStatusFulfilled:         {OrderStatusShipped, OrderStatusRefunded, OrderStatusPartiallyRefunded},
+	OrderStatusShipped:           {OrderStatusDelivered, OrderStatusRefunded, OrderStatusPartiallyRefunded},
+	OrderStatusDelivered:         {OrderStatusRefunded, OrderStatusPartiallyRefunded},
+	OrderStatusPartiallyRefunded: {OrderStatusRefunded},
+}
+
+func (s OrderStatus) IsValid() bool {
+	switch s {
+	case OrderStatusPending, OrderStatusPaid, OrderStatusFulfilled, OrderStatusShipped,
+		OrderStatusDelivered, OrderStatusCancelled, OrderStatusRefunded, OrderStatusPartiallyRefunded:
+		return true
+	}
+	return false
+}
+
+// IsRefund tells whether the status can only be reached by refunding money,
+// never by setting it by hand.
+func (s OrderStatus) IsRefund() bool {
+	return s == OrderStatusRefunded || s == OrderStatusPartiallyRefunded
+}
+
+func (s OrderStatus) CanTransitionTo(next OrderStatus) bool {
+	for _, allowed := range orderStatusTransitions[s] {
+		if allowed == next {
+			return true
+		}
+	}
+	return false
+}
+
+type Order struct {
+	ID              uuid.UUID
+	UserId          uuid.UUID
+	Status          OrderStatus
+	Total           Money `gorm:"embedded;embeddedPrefix:total_"`
+	ShippingMethod  *string
+	ShippingCost    Money `gorm:"embedded;embeddedPrefix:shipping_cost_"`
+	ShippingAddress string
+	Tax             Money         `gorm:"embedded;embeddedPrefix:tax_"`
+	TaxBreakdown    *TaxBreakdown `gorm:"serializer:json"`
+	// Discount is everything taken off the order, waived shipping included
+	Discount   Money `gorm:"embedded;embeddedPrefix:discount_"`
+	CouponId   *uuid.UUID
+	CouponCode *string
+	// ShippingAddressSnapshot is set when the order ships to a saved address
+	ShippingAddressSnapshot *AddressSnapshot `gorm:"serializer:json"`
+	// ShippingCountry and ShippingRegion locate where the order ships to for
+	// tax and shipping, free form addresses included
+	ShippingCountry string
+	ShippingRegion  *string
+	Items           []OrderItem `gorm:"foreignKey:OrderId"`
+	CreatedAt       time.Time
+	UpdatedAt       time.Time
+}
+
+// Cursor points to the order in listings ordered by creation time.
+func (o *Order) Cursor() Cursor {
+	return Cursor{CreatedAt: o.CreatedAt, ID: o.ID}
+}
+
+func (o *Order) FindItem(id uuid.UUID) *OrderItem {
+	for idx := range o.Items {
+		if o.Items[idx].ID == id {
+			return &o.Items[idx]
+		}
+	}
+	return nil
+}
+
+type OrderItem struct {
+	ID        uuid.UUID
+	OrderId   uuid.UUID
+	ProductId uuid.UUID
+	// VariantId and Sku are only set for products sold through variants
+	VariantId *uuid.UUID
+	Sku       *string
+	Quantity  int
+	UnitPrice Money `gorm:"embedded;embeddedPrefix:unit_price_"`
+	TaxClass  string
+	// Tax and Discount are for the whole line, not for a single unit
+	Tax       Money `gorm:"embedded;embeddedPrefix:tax_"`
+	Discount  Money `gorm:"embedded;embeddedPrefix:discount_"`
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// NewOrder starts an empty order, every amount of it is in currency.
+func NewOrder(userId uuid.UUID, currency string) *Order {
+	return &Order{
+		UserId:       userId,
+		Status:       OrderStatusPending,
+		Total:        Zero(currency),
+		ShippingCost: Zero(currency),
+		Tax:          Zero(currency),
+		Discount:     Zero(currency),
+		Items:        make([]OrderItem, 0),
+	}
+}
+
+// SetShipping adds the cost of the chosen shipping method to the order.
+func (o *Order) SetShipping(quote *ShippingQuote) {
+	o.Total = o.Total.Add(quote.Cost).Sub(o.ShippingCost)
+	o.ShippingMethod = &quote.Method
+	o.ShippingCost = quote.Cost
+}
+
+// WaiveShipping takes the shipping cost off the order again.
+func (o *Order) WaiveShipping() {
+	o.Total = o.Total.Sub(o.ShippingCost)
+	o.Discount = o.Discount.Add(o.ShippingCost)
+	o.ShippingCost = Zero(o.ShippingCost.Currency)
+}
+
+// ShipTo freezes a copy of the address onto the order.
+func (o *Order) ShipTo(address *Address) {
+	o.ShippingAddressSnapshot = address.Snapshot()
+	o.ShippingAddress = o.ShippingAddressSnapshot.String()
+	o.ShippingCountry = address.Country
+	o.ShippingRegion = address.Region
+}
+
+// ShipToFreeForm ships the order to an address typed in at checkout, country
+// and region are only used to tax and quote the order.
+func (o *Order) ShipToFreeForm(address string, country string, region *string) {
+	o.ShippingAddressSnapshot = nil
+	o.ShippingAddress = address
+	o.ShippingCountry = strings.ToUpper(country)
+	o.ShippingRegion = region
+}
+
+// TaxDestination is where the order is taxed, ok is false when its country
+// is not known.
+func (o *Order) TaxDestination() (destination TaxDestination, ok bool) {
+	if o.ShippingCountry == "" {
+		return destination, false
+	}
+	destination.Country = o.ShippingCountry
+	if o.ShippingRegion != nil {
+		destination.Region = *o.ShippingRegion
+	}
+	return destination, true
+}
+
+// AddItem adds quantity units of the product, or of its variant when it has
+// one, at unitPrice with discount taken off the whole line. Both must be in
+// the order's currency.
+func (o *Order) AddItem(product *Product, variant *ProductVariant, quantity int, unitPrice Money, discount Money) {
+	item := OrderItem{
+		ProductId: product.ID,
+		Quantity:  quantity,
+		UnitPrice: unitPrice,
+		TaxClass:  product.TaxClass,
+		Tax:       Zero(unitPrice.Currency),
+		Discount:  discount,
+	}
+	if variant != nil {
+		item.VariantId = &variant.ID
+		item.Sku = &variant.Sku
+	}
+	o.Items = append(o.Items, item)
+	o.Total = o.Total.Add(unitPrice.Mul(int64(quantity))).Sub(discount)
+	o.Discount = o.Discount.Add(discount)
+}
+
+// StockKey identifies the stock the line is taken out of, the variant's
+// when it has one and the product's otherwise.
+func (i *OrderItem) StockKey() uuid.UUID {
+	if i.VariantId != nil {
+		return *i.VariantId
+	}
+	return i.ProductId
+}
+
+// Taxable is what the line is charged after its discount.
+func (i *OrderItem) Taxable() Money {
+	return i.UnitPrice.Mul(int64(i.Quantity)).Sub(i.Discount)
+}
+
+// SetTax records the tax of the order, the total only grows by it when
+// prices don't include the tax already.
+func (o *Order) SetTax(breakdown *TaxBreakdown) {
+	if o.TaxBreakdown != nil {
+		o.Total = o.Total.Sub(o.TaxBreakdown.Payable())
+	}
+	o.Total = o.Total.Add(breakdown.Payable())
+	o.Tax = breakdown.Total
+	o.TaxBreakdown = breakdown
+}
+
+// ItemRefundAmount is what refunding quantity units of the item gives back,
+// net of their share of the discount and including their share of the tax
+// when it was charged on top.
+func (o *Order) ItemRefundAmount(item *OrderItem, quantity int) Money {
+	amount := item.UnitPrice.Mul(int64(quantity))
+	if item.Quantity > 0 {
+		amount = amount.Sub(item.Discount.Mul(int64(quantity)).Div(int64(item.Quantity)))
+	}
+	if o.TaxBreakdown != nil && !o.TaxBreakdown.PricesIncludeTax && item.Quantity > 0 {
+		amount = amount.Add(item.Tax.Mul(int64(quantity)).Div(int64(item.Quantity)))
+	}
+	return amount
+}
+
+// OrderStatusChange records a single status transition of an order and
+// who performed it.
+type OrderStatusChange struct {
+	ID         uuid.UUID
+	OrderId    uuid.UUID
+	FromStatus OrderStatus
+	ToStatus   OrderStatus
+	// ChangedBy is nil for changes made by the system, such as expired holds
+	ChangedBy *uuid.UUID
+	CreatedAt time.Time
+}
@@ -0,0 +1,53 @@
+package models
+
+import "testing"
+
+func TestOrderStatusTransitions(t *testing.T) {
+	statuses := []OrderStatus{
+		OrderStatusPending, OrderStatusPaid, OrderStatusFulfilled, OrderStatusShipped,
+		OrderStatusDelivered, OrderStatusCancelled, OrderStatusRefunded, OrderStatusPartiallyRefunded,
+	}
+	allowed := map[OrderStatus][]OrderStatus{
+		OrderStatusPending:           {OrderStatusPaid, OrderStatusCancelled},
+		OrderStatusPaid:              {OrderStatusFulfilled, OrderStatusCancelled, OrderStatusRefunded, OrderStatusPartiallyRefunded},
+		OrderStatusFulfilled:         {OrderStatusShipped, OrderStatusRefunded, OrderStatusPartiallyRefunded},
+		OrderStatusShipped:           {OrderStatusDelivered, OrderStatusRefunded, OrderStatusPartiallyRefunded},
+		OrderStatusDelivered:         {OrderStatusRefunded, OrderStatusPartiallyRefunded},
+		OrderStatusPartiallyRefunded: {OrderStatusRefunded},
+		// cancelled and refunded orders are final
+	}
+	for _, from := range statuses {
+		for _, to := range statuses {
+			want := false
+			for _, next := range allowed[from] {
+				want = want || next == to
+			}
+			if got := from.CanTransitionTo(to); got != want {
+				t.Errorf("%s can transition to %s is %t, want %t", from, to, got, want)
+			}
+		}
+	}
+}
+
+func TestOrderStatusIsValid(t *testing.T) {
+	tests := []struct {
+		status OrderStatus
+		valid  bool
+		refund bool
+	}{
+		{status: OrderStatusPending, valid: true},
+		{status: OrderStatusDelivered, valid: true},
+		{status: OrderStatusRefunded, valid: true, refund: true},
+		{status: OrderStatusPartiallyRefunded, valid: true, refund: true},
+		{status: "returned", valid: false},
+		{status: "", valid: false},
+	}
+	for _, tt := range tests {
+		if got := tt.status.IsValid(); got != tt.valid {
+			t.Errorf("%q is valid is %t, want %t", tt.status, got, tt.valid)
+		}
+		if got := tt.status.IsRefund(); got != tt.refund {
+			t.Errorf("%q is a refund is %t, want %t", tt.status, got, tt.refund)
+		}
+	}
+}
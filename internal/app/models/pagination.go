@@ -1,7 +1,37 @@
 package models
 
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
 type Pagination struct {
 	Page   int
 	Limit  int
 	Offset int
+	// After resumes a listing ordered by creation time, newest first, right
+	// after the row it points to. Offset is ignored when it is set.
+	After *Cursor
+}
+
+// Cursor points to a row of a listing by its position in the
+// (created_at, id) order, so rows inserted while paging can't shift pages.
+type Cursor struct {
+	CreatedAt time.Time `json:"t"`
+	ID        uuid.UUID `json:"id"`
+}
+
+// Full tells whether a page of rows used up the whole limit, only then can
+// there be rows after it.
+func (p *Pagination) Full(rows int) bool {
+	return rows > 0 && rows >= p.Limit
+}
+
+// TotalPages is how many pages total items fill.
+func (p *Pagination) TotalPages(total int64) int {
+	if p.Limit <= 0 {
+		return 0
+	}
+	return int((total + int64(p.Limit) - 1) / int64(p.Limit))
 }
@@ -0,0 +1,118 @@
+package models
+
+import (
+	"errors"
+	"net/mail"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// MaxPasswordBytes is how much of a password bcrypt looks at, anything past
+// it would be silently ignored.
+const MaxPasswordBytes = 72
+
+// PasswordPolicy is what new passwords have to satisfy.
+type PasswordPolicy struct {
+	// MinLength is counted in characters
+	MinLength     int  `json:"min_length"`
+	RequireLower  bool `json:"require_lower"`
+	RequireUpper  bool `json:"require_upper"`
+	RequireDigit  bool `json:"require_digit"`
+	RequireSymbol bool `json:"require_symbol"`
+	// DenylistFile lists common or breached passwords, one per line, that are
+	// refused regardless of case
+	DenylistFile string `json:"denylist_file"`
+	denylist     map[string]bool
+}
+
+func (p *PasswordPolicy) Validate() error {
+	if p.MinLength < 1 {
+		return errors.New("password policy needs a min_length of at least 1")
+	}
+	if p.MinLength > MaxPasswordBytes {
+		return errors.New("password policy min_length can't be more than 72")
+	}
+	return nil
+}
+
+// SetDenylist replaces the passwords the policy refuses.
+func (p *PasswordPolicy) SetDenylist(passwords []string) {
+	p.denylist = make(map[string]bool, len(passwords))
+	for _, password := range passwords {
+		p.denylist[strings.ToLower(password)] = true
+	}
+}
+
+// Check returns why the password breaks the policy, or an empty string when
+// it doesn't.
+func (p *PasswordPolicy) Check(password string) string {
+	if utf8.RuneCountInString(password) < p.MinLength {
+		return "password must be at least " + strconv.Itoa(p.MinLength) + " characters long"
+	}
+	if len(password) > MaxPasswordBytes {
+		return "password must be at most 72 bytes long"
+	}
+
+	var lower, upper, digit, symbol bool
+	for _, r := range password {
+		switch {
+		case unicode.IsLower(r):
+			lower = true
+		case unicode.IsUpper(r):
+			upper = true
+		case unicode.IsDigit(r):
+			digit = true
+		case unicode.IsPunct(r) || unicode.IsSymbol(r) || unicode.IsSpace(r):
+			symbol = true
+		}
+	}
+	missing := make([]string, 0, 4)
+	if p.RequireLower && !lower {
+		missing = append(missing, "a lowercase letter")
+	}
+	if p.RequireUpper && !upper {
+		missing = append(missing, "an uppercase letter")
+	}
+	if p.RequireDigit && !digit {
+		missing = append(missing, "a digit")
+	}
+	if p.RequireSymbol && !symbol {
+		missing = append(missing, "a symbol")
+	}
+	if len(missing) > 0 {
+		return "password must contain " + joinWithAnd(missing)
+	}
+
+	if p.denylist[strings.ToLower(password)] {
+		return "password is too common, choose another one"
+	}
+	return ""
+}
+
+// NormalizeEmail trims the email and folds it to lower case, so addresses
+// that only differ in case belong to the same account.
+func NormalizeEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
+
+// validateEmail checks that email is a bare address, without a display name
+// or angle brackets.
+func validateEmail(errs map[string]string, field string, email string) {
+	if len(email) > 254 {
+		errs[field] = field + " must be at most 254 characters long"
+		return
+	}
+	address, err := mail.ParseAddress(email)
+	if err != nil || address.Address != email || !strings.Contains(address.Address, "@") {
+		errs[field] = field + " must be a valid email address"
+	}
+}
+
+func joinWithAnd(items []string) string {
+	if len(items) == 1 {
+		return items[0]
+	}
+	return strings.Join(items[:len(items)-1], ", ") + " and " + items[len(items)-1]
+}
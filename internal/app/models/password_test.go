@@ -0,0 +1,61 @@
+package models
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestPasswordPolicyCheck(t *testing.T) {
+	strict := &PasswordPolicy{MinLength: 10, RequireLower: true, RequireUpper: true, RequireDigit: true, RequireSymbol: true}
+	strict.SetDenylist([]string{"Password123!"})
+	lenient := &PasswordPolicy{MinLength: 4}
+
+	tests := []struct {
+		name     string
+		policy   *PasswordPolicy
+		password string
+		want     string
+	}{
+		{name: "satisfies every rule", policy: strict, password: "Correct-Horse9", want: ""},
+		{name: "too short", policy: strict, password: "Sh0rt!", want: "password must be at least 10 characters long"},
+		{name: "length counts characters, not bytes", policy: lenient, password: "ñññ", want: "password must be at least 4 characters long"},
+		{name: "multi byte characters are enough", policy: lenient, password: "ññññ", want: ""},
+		{name: "longer than bcrypt reads", policy: lenient, password: strings.Repeat("a", MaxPasswordBytes+1), want: "password must be at most 72 bytes long"},
+		{name: "bcrypt limit counts bytes", policy: lenient, password: strings.Repeat("ñ", MaxPasswordBytes/2+1), want: "password must be at most 72 bytes long"},
+		{name: "at the bcrypt limit", policy: lenient, password: strings.Repeat("a", MaxPasswordBytes), want: ""},
+		{name: "missing a lowercase letter", policy: strict, password: "CORRECT-HORSE9", want: "password must contain a lowercase letter"},
+		{name: "missing an uppercase letter", policy: strict, password: "correct-horse9", want: "password must contain an uppercase letter"},
+		{name: "missing a digit", policy: strict, password: "Correct-Horse", want: "password must contain a digit"},
+		{name: "missing a symbol", policy: strict, password: "CorrectHorse9", want: "password must contain a symbol"},
+		{name: "a space is a symbol", policy: strict, password: "Correct Horse9", want: ""},
+		{name: "missing several", policy: strict, password: "correcthorse", want: "password must contain an uppercase letter, a digit and a symbol"},
+		{name: "denylisted", policy: strict, password: "Password123!", want: "password is too common, choose another one"},
+		{name: "denylist ignores case", policy: strict, password: "pASSWORD123!", want: "password is too common, choose another one"},
+		{name: "lenient policy", policy: lenient, password: "abcd", want: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.policy.Check(tt.password); got != tt.want {
+				t.Errorf("Check is %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPasswordPolicyValidate(t *testing.T) {
+	tests := []struct {
+		minLength int
+		valid     bool
+	}{
+		{minLength: 0, valid: false},
+		{minLength: 1, valid: true},
+		{minLength: MaxPasswordBytes, valid: true},
+		{minLength: MaxPasswordBytes + 1, valid: false},
+	}
+	for _, tt := range tests {
+		policy := &PasswordPolicy{MinLength: tt.minLength}
+		if err := policy.Validate(); (err == nil) != tt.valid {
+			t.Errorf("min_length %d validates with %v, want valid %t", tt.minLength, err, tt.valid)
+		}
+	}
+}
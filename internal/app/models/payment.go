@@ -0,0 +1,83 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type PaymentStatus string
+
+const (
+	// PaymentStatusPending the provider didn't give an answer yet
+	PaymentStatusPending    PaymentStatus = "pending"
+	PaymentStatusAuthorized PaymentStatus = "authorized"
+	PaymentStatusCaptured   PaymentStatus = "captured"
+	PaymentStatusVoided     PaymentStatus = "voided"
+	PaymentStatusRefunded   PaymentStatus = "refunded"
+	PaymentStatusDeclined   PaymentStatus = "declined"
+	PaymentStatusFailed     PaymentStatus = "failed"
+	// PaymentStatusCancelled the order was cancelled while the provider
+	// still owed an answer, a capture reported later is refunded
+	PaymentStatusCancelled PaymentStatus = "cancelled"
+)
+
+// PaymentProviderNone records payments of orders with nothing to charge,
+// they never reach a payment provider.
+const PaymentProviderNone = "none"
+
+// Payment is a single attempt at charging an order through a payment
+// provider.
+type Payment struct {
+	ID                uuid.UUID
+	OrderId           uuid.UUID
+	Provider          string
+	ProviderReference *string
+	Amount            Money `gorm:"embedded"`
+	RefundedAmount    Money `gorm:"embedded;embeddedPrefix:refunded_"`
+	Status            PaymentStatus
+	FailureReason     *string
+	CreatedAt         time.Time
+	UpdatedAt         time.Time
+}
+
+func NewPayment(order *Order, provider string) *Payment {
+	return &Payment{
+		OrderId:        order.ID,
+		Provider:       provider,
+		Amount:         order.Total,
+		RefundedAmount: Zero(order.Total.Currency),
+		Status:         PaymentStatusPending,
+	}
+}
+
+// Refundable is the part of the captured amount that was not refunded yet.
+func (p *Payment) Refundable() Money {
+	if p.Status != PaymentStatusCaptured {
+		return Zero(p.Amount.Currency)
+	}
+	return p.Amount.Sub(p.RefundedAmount)
+}
+
+func (p *Payment) Fail(status PaymentStatus, reason string) {
+	p.Status = status
+	p.FailureReason = &reason
+}
+
+type PaymentEventType string
+
+const (
+	PaymentEventCaptured PaymentEventType = "payment.captured"
+	PaymentEventFailed   PaymentEventType = "payment.failed"
+	PaymentEventRefunded PaymentEventType = "payment.refunded"
+)
+
+// WebhookEvent remembers a provider event that was already processed so a
+// redelivery of it is ignored.
+type WebhookEvent struct {
+	ID        uuid.UUID
+	Provider  string
+	EventId   string
+	Type      PaymentEventType
+	CreatedAt time.Time
+}
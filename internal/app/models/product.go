@@ -7,17 +7,105 @@ import (
 )
 
 type Product struct {
-	ID            uuid.UUID
-	Name          string
-	Description   *string
-	Price         int64
+	ID          uuid.UUID
+	Name        string
+	Description *string
+	// Price is the base price, Prices overrides it for other currencies
+	Price      Money            `gorm:"embedded;embeddedPrefix:price_"`
+	Prices     []ProductPrice   `gorm:"foreignKey:ProductId"`
+	Categories []Category       `gorm:"many2many:product_categories;"`
+	Options    []ProductOption  `gorm:"foreignKey:ProductId"`
+	Variants   []ProductVariant `gorm:"foreignKey:ProductId"`
+	// StockQuantity is only tracked for products without variants
 	StockQuantity int
+	WeightGrams   int
+	LengthMm      int
+	WidthMm       int
+	HeightMm      int
+	TaxClass      string
 	CreatedAt     time.Time
 	UpdatedAt     time.Time
 }
 
+// PriceIn returns the product's price in currency, ok is false when the
+// product isn't sold in it.
+func (p *Product) PriceIn(currency string) (price Money, ok bool) {
+	if p.Price.Currency == currency {
+		return p.Price, true
+	}
+	for _, override := range p.Prices {
+		if override.Price.Currency == currency {
+			return override.Price, true
+		}
+	}
+	return Money{}, false
+}
+
+// CategoryIds returns the ids of the categories the product is directly
+// assigned to.
+func (p *Product) CategoryIds() []uuid.UUID {
+	ids := make([]uuid.UUID, len(p.Categories))
+	for idx, category := range p.Categories {
+		ids[idx] = category.ID
+	}
+	return ids
+}
+
+// Cursor points to the product in listings ordered by creation time.
+func (p *Product) Cursor() Cursor {
+	return Cursor{CreatedAt: p.CreatedAt, ID: p.ID}
+}
+
+// ProductPrice overrides the price of a product in a single currency.
+type ProductPrice struct {
+	ID        uuid.UUID
+	ProductId uuid.UUID
+	Price     Money `gorm:"embedded;embeddedPrefix:price_"`
+}
+
 type ProductRepo interface {
 	Get(uuid.UUID) (*Product, error)
 	GetPaged() ([]*Product, error)
 	Create(*Product) error
 }
+
+type ProductSort string
+
+const (
+	ProductSortPrice     ProductSort = "price"
+	ProductSortPriceDesc ProductSort = "-price"
+	ProductSortName      ProductSort = "name"
+	ProductSortNameDesc  ProductSort = "-name"
+	ProductSortNewest    ProductSort = "newest"
+	// ProductSortPopularity puts the products that sold the most units first
+	ProductSortPopularity ProductSort = "popularity"
+)
+
+func (s ProductSort) IsValid() bool {
+	switch s {
+	case ProductSortPrice, ProductSortPriceDesc, ProductSortName, ProductSortNameDesc,
+		ProductSortNewest, ProductSortPopularity:
+		return true
+	}
+	return false
+}
+
+// SortsByPrice tells whether the sort needs a currency to compare prices in.
+func (s ProductSort) SortsByPrice() bool {
+	return s == ProductSortPrice || s == ProductSortPriceDesc
+}
+
+// ProductFilter narrows down and orders a product listing, zero fields don't
+// filter anything.
+type ProductFilter struct {
+	// MinPrice and MaxPrice are minor units of Currency, only products sold
+	// in Currency match them
+	MinPrice *int64
+	MaxPrice *int64
+	Currency string
+	InStock  bool
+	// CategoryIds matches products in any of these categories
+	CategoryIds  []uuid.UUID
+	CreatedAfter *time.Time
+	Sort         ProductSort
+}
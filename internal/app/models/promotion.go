@@ -0,0 +1,180 @@
+package models
+
+import (
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type PromotionType string
+
+const (
+	// PromotionTypePercentage takes PercentBps off every eligible line
+	PromotionTypePercentage PromotionType = "percentage"
+	// PromotionTypeBuyXGetY gives FreeQuantity units away for every
+	// BuyQuantity units bought of the same eligible line
+	PromotionTypeBuyXGetY PromotionType = "buy_x_get_y"
+	// PromotionTypeSpendTiers takes the amount of the highest tier the
+	// eligible lines reach off them
+	PromotionTypeSpendTiers PromotionType = "spend_tiers"
+)
+
+func (t PromotionType) IsValid() bool {
+	switch t {
+	case PromotionTypePercentage, PromotionTypeBuyXGetY, PromotionTypeSpendTiers:
+		return true
+	}
+	return false
+}
+
+// SpendTier is reached once the eligible lines add up to Threshold, both are
+// minor units of the promotion's currency.
+type SpendTier struct {
+	Threshold int64 `json:"threshold"`
+	Amount    int64 `json:"amount"`
+}
+
+// Promotion is a discount rule that applies to carts automatically, without
+// a code.
+type Promotion struct {
+	ID   uuid.UUID
+	Name string
+	Type PromotionType
+	// Priority orders the evaluation, lower values go first
+	Priority int
+	// Exclusive stops the evaluation of every later promotion once this one
+	// discounts the cart
+	Exclusive    bool
+	PercentBps   int64
+	BuyQuantity  int
+	FreeQuantity int
+	// Currency and Tiers are only used by spend tier promotions
+	Currency string
+	Tiers    []SpendTier `gorm:"serializer:json"`
+	// ProductIds and CategoryIds limit the promotion to these products and
+	// to the products of these categories or their subcategories, both empty
+	// means all products
+	ProductIds  []uuid.UUID `gorm:"serializer:json"`
+	CategoryIds []uuid.UUID `gorm:"serializer:json"`
+	StartsAt    *time.Time
+	EndsAt      *time.Time
+	Active      bool
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+}
+
+// ActiveAt tells whether the promotion is switched on and now falls in its
+// validity window.
+func (p *Promotion) ActiveAt(now time.Time) bool {
+	if !p.Active {
+		return false
+	}
+	if p.StartsAt != nil && now.Before(*p.StartsAt) {
+		return false
+	}
+	if p.EndsAt != nil && !now.Before(*p.EndsAt) {
+		return false
+	}
+	return true
+}
+
+func (p *Promotion) eligible(item *CartItem) bool {
+	if len(p.ProductIds) == 0 && len(p.CategoryIds) == 0 {
+		return true
+	}
+	for _, id := range p.ProductIds {
+		if id == item.ProductId {
+			return true
+		}
+	}
+	for _, id := range p.CategoryIds {
+		for _, categoryId := range item.CategoryIds {
+			if id == categoryId {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// shares computes what the promotion takes off every line of the cart, based
+// on what earlier discounts left of them. A share never exceeds what is left
+// of its line.
+func (p *Promotion) shares(cart *Cart) []int64 {
+	shares := make([]int64, len(cart.Items))
+	bases := make([]int64, len(cart.Items))
+	for idx, item := range cart.Items {
+		if p.eligible(item) {
+			bases[idx] = item.DiscountedSubTotal().Amount
+		}
+	}
+
+	switch p.Type {
+	case PromotionTypePercentage:
+		for idx, base := range bases {
+			shares[idx] = min(base, (base*p.PercentBps+5000)/10000)
+		}
+	case PromotionTypeBuyXGetY:
+		group := p.BuyQuantity + p.FreeQuantity
+		if group <= 0 {
+			return shares
+		}
+		for idx, item := range cart.Items {
+			free := item.Quantity / group * p.FreeQuantity
+			shares[idx] = min(bases[idx], item.Price.Amount*int64(free))
+		}
+	case PromotionTypeSpendTiers:
+		if !strings.EqualFold(p.Currency, cart.Currency) {
+			return shares
+		}
+		var spent, amount int64
+		for _, base := range bases {
+			spent += base
+		}
+		for _, tier := range p.Tiers {
+			if spent >= tier.Threshold && tier.Amount > amount {
+				amount = tier.Amount
+			}
+		}
+		return allocate(amount, bases)
+	}
+	return shares
+}
+
+// ApplyPromotions discounts the cart with every promotion active at now.
+// Promotions are evaluated by ascending priority, ties are broken by creation
+// time and then id, so the outcome never depends on the order they were
+// loaded in. Each promotion only discounts what earlier ones left of a line,
+// and an exclusive promotion that discounts anything ends the evaluation.
+func ApplyPromotions(cart *Cart, promotions []Promotion, now time.Time) {
+	ordered := make([]*Promotion, 0, len(promotions))
+	for idx := range promotions {
+		if promotions[idx].ActiveAt(now) {
+			ordered = append(ordered, &promotions[idx])
+		}
+	}
+	sort.SliceStable(ordered, func(i, j int) bool {
+		a, b := ordered[i], ordered[j]
+		if a.Priority != b.Priority {
+			return a.Priority < b.Priority
+		}
+		if !a.CreatedAt.Equal(b.CreatedAt) {
+			return a.CreatedAt.Before(b.CreatedAt)
+		}
+		return a.ID.String() < b.ID.String()
+	})
+
+	for _, promotion := range ordered {
+		before := len(cart.Discounts)
+		cart.applyShares(CartDiscount{
+			Source:      DiscountSourcePromotion,
+			Code:        promotion.Name,
+			PromotionId: &promotion.ID,
+		}, promotion.shares(cart))
+		if promotion.Exclusive && len(cart.Discounts) > before {
+			return
+		}
+	}
+}
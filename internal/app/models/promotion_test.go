@@ -0,0 +1,226 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type promotionTestLine struct {
+	price    int64
+	quantity int
+}
+
+// promotionTestProduct is the product of the idx-th test line.
+func promotionTestProduct(idx int) uuid.UUID {
+	return uuid.UUID{byte(idx + 1)}
+}
+
+func promotionTestCart(lines ...promotionTestLine) *Cart {
+	cart := NewCart(uuid.New(), "USD")
+	for idx, line := range lines {
+		product := &Product{ID: promotionTestProduct(idx), Name: "product"}
+		cart.AddQuantityOrInsert(product, nil, NewMoney(line.price, "USD"), line.quantity)
+	}
+	return cart
+}
+
+func TestApplyPromotions(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	percentage := func(name string, priority int, percentBps int64) Promotion {
+		return Promotion{
+			ID:         uuid.New(),
+			Name:       name,
+			Type:       PromotionTypePercentage,
+			Priority:   priority,
+			PercentBps: percentBps,
+			Active:     true,
+			CreatedAt:  now.Add(-time.Hour),
+		}
+	}
+	buyXGetY := func(name string, buy int, free int) Promotion {
+		return Promotion{
+			ID:           uuid.New(),
+			Name:         name,
+			Type:         PromotionTypeBuyXGetY,
+			Priority:     10,
+			BuyQuantity:  buy,
+			FreeQuantity: free,
+			Active:       true,
+		}
+	}
+	spendTiers := func(name string, currency string, tiers ...SpendTier) Promotion {
+		return Promotion{
+			ID:       uuid.New(),
+			Name:     name,
+			Type:     PromotionTypeSpendTiers,
+			Currency: currency,
+			Tiers:    tiers,
+			Active:   true,
+		}
+	}
+	with := func(promotion Promotion, change func(*Promotion)) Promotion {
+		change(&promotion)
+		return promotion
+	}
+
+	tests := []struct {
+		name       string
+		lines      []promotionTestLine
+		promotions []Promotion
+		// wantCodes are the promotions that discounted the cart, in the
+		// order they were applied
+		wantCodes []string
+		// wantShares is what was taken off every line
+		wantShares []int64
+	}{
+		{
+			name:       "promotions stack by priority on what is left",
+			lines:      []promotionTestLine{{price: 1000, quantity: 1}},
+			promotions: []Promotion{percentage("half off", 2, 5000), percentage("ten off", 1, 1000)},
+			wantCodes:  []string{"ten off", "half off"},
+			wantShares: []int64{550},
+		},
+		{
+			name:  "equal priorities go by creation time",
+			lines: []promotionTestLine{{price: 1000, quantity: 1}},
+			promotions: []Promotion{
+				percentage("newer", 1, 1000),
+				with(percentage("older", 1, 5000), func(p *Promotion) { p.CreatedAt = now.Add(-2 * time.Hour) }),
+			},
+			wantCodes:  []string{"older", "newer"},
+			wantShares: []int64{550},
+		},
+		{
+			name:  "inactive promotions are skipped",
+			lines: []promotionTestLine{{price: 1000, quantity: 1}},
+			promotions: []Promotion{
+				with(percentage("switched off", 1, 1000), func(p *Promotion) { p.Active = false }),
+				with(percentage("ended", 1, 1000), func(p *Promotion) { p.EndsAt = &now }),
+				percentage("running", 2, 2000),
+			},
+			wantCodes:  []string{"running"},
+			wantShares: []int64{200},
+		},
+		{
+			name:  "exclusive promotion ends the evaluation",
+			lines: []promotionTestLine{{price: 1000, quantity: 1}},
+			promotions: []Promotion{
+				with(percentage("exclusive", 1, 1000), func(p *Promotion) { p.Exclusive = true }),
+				percentage("half off", 2, 5000),
+			},
+			wantCodes:  []string{"exclusive"},
+			wantShares: []int64{100},
+		},
+		{
+			name:  "exclusive promotion that discounts nothing does not end it",
+			lines: []promotionTestLine{{price: 1000, quantity: 1}},
+			promotions: []Promotion{
+				with(percentage("exclusive", 1, 1000), func(p *Promotion) {
+					p.Exclusive = true
+					p.ProductIds = []uuid.UUID{promotionTestProduct(5)}
+				}),
+				percentage("half off", 2, 5000),
+			},
+			wantCodes:  []string{"half off"},
+			wantShares: []int64{500},
+		},
+		{
+			name:       "percentage share never exceeds the line",
+			lines:      []promotionTestLine{{price: 1000, quantity: 1}},
+			promotions: []Promotion{percentage("half off", 1, 5000), percentage("too much", 2, 15000)},
+			wantCodes:  []string{"half off", "too much"},
+			wantShares: []int64{1000},
+		},
+		{
+			name:       "buy two get one free",
+			lines:      []promotionTestLine{{price: 100, quantity: 7}},
+			promotions: []Promotion{buyXGetY("3 for 2", 2, 1)},
+			wantCodes:  []string{"3 for 2"},
+			wantShares: []int64{200},
+		},
+		{
+			name:  "buy x get y only discounts eligible lines",
+			lines: []promotionTestLine{{price: 100, quantity: 3}, {price: 200, quantity: 3}},
+			promotions: []Promotion{
+				with(buyXGetY("3 for 2", 2, 1), func(p *Promotion) { p.ProductIds = []uuid.UUID{promotionTestProduct(1)} }),
+			},
+			wantCodes:  []string{"3 for 2"},
+			wantShares: []int64{0, 200},
+		},
+		{
+			name:       "buy x get y gives away what earlier promotions left",
+			lines:      []promotionTestLine{{price: 100, quantity: 3}},
+			promotions: []Promotion{percentage("ninety off", 1, 9000), buyXGetY("3 for 2", 2, 1)},
+			wantCodes:  []string{"ninety off", "3 for 2"},
+			wantShares: []int64{300},
+		},
+		{
+			name:  "highest spend tier reached is split over the lines",
+			lines: []promotionTestLine{{price: 6000, quantity: 1}, {price: 3000, quantity: 2}},
+			promotions: []Promotion{
+				spendTiers("spend more", "USD", SpendTier{Threshold: 5000, Amount: 500}, SpendTier{Threshold: 10000, Amount: 1500}),
+			},
+			wantCodes:  []string{"spend more"},
+			wantShares: []int64{750, 750},
+		},
+		{
+			name:  "spend tier rounding leftovers go to the first line",
+			lines: []promotionTestLine{{price: 1000, quantity: 1}, {price: 2000, quantity: 1}},
+			promotions: []Promotion{
+				spendTiers("spend more", "USD", SpendTier{Threshold: 3000, Amount: 100}),
+			},
+			wantCodes:  []string{"spend more"},
+			wantShares: []int64{34, 66},
+		},
+		{
+			name:  "spend tiers count what earlier promotions left",
+			lines: []promotionTestLine{{price: 5000, quantity: 1}},
+			promotions: []Promotion{
+				percentage("ten off", 1, 1000),
+				with(spendTiers("spend more", "USD", SpendTier{Threshold: 5000, Amount: 500}), func(p *Promotion) { p.Priority = 2 }),
+			},
+			wantCodes:  []string{"ten off"},
+			wantShares: []int64{500},
+		},
+		{
+			name:  "spend tiers in another currency don't apply",
+			lines: []promotionTestLine{{price: 6000, quantity: 1}},
+			promotions: []Promotion{
+				spendTiers("spend more", "EUR", SpendTier{Threshold: 5000, Amount: 500}),
+			},
+			wantShares: []int64{0},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cart := promotionTestCart(tt.lines...)
+			ApplyPromotions(cart, tt.promotions, now)
+
+			if len(cart.Discounts) != len(tt.wantCodes) {
+				t.Fatalf("cart has %d discounts, want %d", len(cart.Discounts), len(tt.wantCodes))
+			}
+			var discounted int64
+			for idx, discount := range cart.Discounts {
+				if discount.Code != tt.wantCodes[idx] {
+					t.Errorf("discount %d is %q, want %q", idx, discount.Code, tt.wantCodes[idx])
+				}
+				discounted += discount.Amount.Amount
+			}
+			var wantDiscounted int64
+			for idx, item := range cart.Items {
+				if share := item.DiscountTotal().Amount; share != tt.wantShares[idx] {
+					t.Errorf("line %d is discounted %d, want %d", idx, share, tt.wantShares[idx])
+				}
+				wantDiscounted += tt.wantShares[idx]
+			}
+			if discounted != wantDiscounted {
+				t.Errorf("discounts add up to %d, want %d", discounted, wantDiscounted)
+			}
+			if want := cart.Total.Amount - wantDiscounted; cart.DiscountedTotal.Amount != want {
+				t.Errorf("discounted total is %d, want %d", cart.DiscountedTotal.Amount, want)
+			}
+		})
+	}
+}
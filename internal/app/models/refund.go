@@ -0,0 +1,76 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type RefundStatus string
+
+const (
+	// RefundStatusPending the refund is recorded and its amount set aside on
+	// the payment, but the provider didn't confirm it yet
+	RefundStatusPending   RefundStatus = "pending"
+	RefundStatusCompleted RefundStatus = "completed"
+	// RefundStatusFailed the provider refused the refund, no money moved
+	RefundStatusFailed RefundStatus = "failed"
+)
+
+type Refund struct {
+	ID        uuid.UUID
+	OrderId   uuid.UUID
+	PaymentId uuid.UUID
+	// Amount is what the items give back plus Adjustment
+	Amount Money `gorm:"embedded"`
+	// Adjustment is what the refund gives back beyond its items, the
+	// shipping and the rounding leftovers a full refund settles
+	Adjustment Money `gorm:"embedded;embeddedPrefix:adjustment_"`
+	Status     RefundStatus
+	Reason     *string
+	// Restock puts the refunded quantities back into the product stock
+	Restock   bool
+	CreatedBy uuid.UUID
+	Items     []RefundItem `gorm:"foreignKey:RefundId"`
+	CreatedAt time.Time
+}
+
+type RefundItem struct {
+	ID          uuid.UUID
+	RefundId    uuid.UUID
+	OrderItemId uuid.UUID
+	ProductId   uuid.UUID
+	VariantId   *uuid.UUID
+	Quantity    int
+	Amount      Money `gorm:"embedded"`
+}
+
+func NewRefund(order *Order, payment *Payment, actorId uuid.UUID) *Refund {
+	return &Refund{
+		OrderId:    order.ID,
+		PaymentId:  payment.ID,
+		Amount:     Zero(payment.Amount.Currency),
+		Adjustment: Zero(payment.Amount.Currency),
+		Status:     RefundStatusPending,
+		CreatedBy:  actorId,
+		Items:      make([]RefundItem, 0),
+	}
+}
+
+func (r *Refund) AddItem(item *OrderItem, quantity int, amount Money) {
+	r.Items = append(r.Items, RefundItem{
+		OrderItemId: item.ID,
+		ProductId:   item.ProductId,
+		VariantId:   item.VariantId,
+		Quantity:    quantity,
+		Amount:      amount,
+	})
+	r.Amount = r.Amount.Add(amount)
+}
+
+// SettleRemainder makes the refund give back all of refundable, what its
+// items don't cover is recorded as Adjustment.
+func (r *Refund) SettleRemainder(refundable Money) {
+	r.Adjustment = r.Adjustment.Add(refundable.Sub(r.Amount))
+	r.Amount = refundable
+}
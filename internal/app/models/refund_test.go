@@ -0,0 +1,45 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func TestRefundSettleRemainder(t *testing.T) {
+	order := NewOrder(uuid.New(), "USD")
+	order.AddItem(&Product{ID: uuid.New()}, nil, 3, NewMoney(1000, "USD"), NewMoney(100, "USD"))
+	order.AddItem(&Product{ID: uuid.New()}, nil, 1, NewMoney(500, "USD"), Zero("USD"))
+	order.SetShipping(&ShippingQuote{Method: "standard", Cost: NewMoney(700, "USD")})
+	for idx := range order.Items {
+		order.Items[idx].ID = uuid.New()
+	}
+	payment := NewPayment(order, "fake")
+	payment.Status = PaymentStatusCaptured
+
+	// the first line was partially refunded already
+	first := &order.Items[0]
+	partial := order.ItemRefundAmount(first, 1)
+	payment.RefundedAmount = partial
+
+	refund := NewRefund(order, payment, uuid.New())
+	refund.AddItem(first, 2, order.ItemRefundAmount(first, 2))
+	refund.AddItem(&order.Items[1], 1, order.ItemRefundAmount(&order.Items[1], 1))
+	refund.SettleRemainder(payment.Refundable())
+
+	if refund.Amount != payment.Refundable() {
+		t.Errorf("refund amount is %s, want %s", refund.Amount, payment.Refundable())
+	}
+	items := Zero("USD")
+	for _, item := range refund.Items {
+		items = items.Add(item.Amount)
+	}
+	if sum := items.Add(refund.Adjustment); sum != refund.Amount {
+		t.Errorf("items and adjustment add up to %s, want %s", sum, refund.Amount)
+	}
+	// the lines take 33 and 66 of the 100 discount off, one cent less than
+	// the order did, so the adjustment is the shipping minus that cent
+	if want := NewMoney(700-1, "USD"); refund.Adjustment != want {
+		t.Errorf("adjustment is %s, want %s", refund.Adjustment, want)
+	}
+}
@@ -1,22 +1,88 @@
 package models
 
-import "github.com/google/uuid"
+import (
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
 
 type RegisterUser struct {
 	Email    string `json:"email" binding:"required"`
 	Password string `json:"password" binding:"required"`
 }
 
+// Validate checks the email, as it will be stored, and the password against
+// policy.
+func (r *RegisterUser) Validate(policy *PasswordPolicy) (bool, map[string]string) {
+	errs := make(map[string]string)
+	validateEmail(errs, "email", NormalizeEmail(r.Email))
+	if violation := policy.Check(r.Password); violation != "" {
+		errs["password"] = violation
+	}
+	return len(errs) == 0, errs
+}
+
 type Login struct {
 	Email    string `json:"email" binding:"required"`
 	Password string `json:"password" binding:"required"`
 }
 
+type ForgotPassword struct {
+	Email string `json:"email" binding:"required"`
+}
+
+type ResetPassword struct {
+	Token    string `json:"token" binding:"required"`
+	Password string `json:"password" binding:"required"`
+}
+
+func (r *ResetPassword) Validate(policy *PasswordPolicy) (bool, map[string]string) {
+	errs := make(map[string]string)
+	if violation := policy.Check(r.Password); violation != "" {
+		errs["password"] = violation
+	}
+	return len(errs) == 0, errs
+}
+
+// UserRolesUpdate replaces every role of a user, an empty list takes them
+// all away.
+type UserRolesUpdate struct {
+	Roles []string `json:"roles" binding:"required"`
+}
+
+func (u *UserRolesUpdate) Validate() (bool, map[string]string) {
+	errs := make(map[string]string)
+	for _, name := range u.Roles {
+		if strings.TrimSpace(name) == "" {
+			errs["roles"] = "role names can't be empty"
+			break
+		}
+	}
+	return len(errs) == 0, errs
+}
+
+// RefreshTokenRequest carries the refresh token to rotate or to log out
+// with.
+type RefreshTokenRequest struct {
+	RefreshToken string `json:"refresh_token" binding:"required"`
+}
+
 type ProductCreate struct {
-	Name          string  `json:"name" binding:"required"`
-	Description   *string `json:"description"`
-	Price         int64   `json:"price" binding:"required"`
+	Name        string  `json:"name" binding:"required"`
+	Description *string `json:"description"`
+	Price       Money   `json:"price"`
+	// Prices optionally overrides Price in other currencies
+	Prices        []Money `json:"prices"`
 	StockQuantity int     `json:"stock_quantity" binding:"required"`
+	WeightGrams   int     `json:"weight_grams"`
+	LengthMm      int     `json:"length_mm"`
+	WidthMm       int     `json:"width_mm"`
+	HeightMm      int     `json:"height_mm"`
+	TaxClass      string  `json:"tax_class"`
+	// CategoryIds assigns the product to these categories
+	CategoryIds []uuid.UUID `json:"category_ids"`
 }
 
 func (p *ProductCreate) Validate() (bool, map[string]string) {
@@ -27,21 +93,30 @@ func (p *ProductCreate) Validate() (bool, map[string]string) {
 	if p.Description != nil && len(*p.Description) < 10 {
 		errs["description"] = "description must have more than 10 characters"
 	}
-	if p.Price <= 0 {
-		errs["price"] = "price must be greater than 0"
-	}
+	validatePrice(errs, "price", p.Price)
+	validatePriceOverrides(errs, p.Price.Currency, p.Prices)
 
 	if p.StockQuantity <= 0 {
 		errs["stock_quantity"] = "stock quantity must be greater than 0"
 	}
+	validateProductDimensions(errs, &p.WeightGrams, &p.LengthMm, &p.WidthMm, &p.HeightMm)
+	if len(p.TaxClass) > 50 {
+		errs["tax_class"] = "tax class must have less than 50 characters"
+	}
+	validateCategoryIds(errs, p.CategoryIds)
 	return len(errs) == 0, errs
 }
 
 type ProductUpdateRequest struct {
 	Name          *string `json:"name"`
 	Description   *string `json:"description"`
-	Price         *int64  `json:"price"`
+	Price         *Money  `json:"price"`
 	StockQuantity *int    `json:"stock_quantity"`
+	WeightGrams   *int    `json:"weight_grams"`
+	LengthMm      *int    `json:"length_mm"`
+	WidthMm       *int    `json:"width_mm"`
+	HeightMm      *int    `json:"height_mm"`
+	TaxClass      *string `json:"tax_class"`
 }
 
 func (p *ProductUpdateRequest) Validate() (bool, map[string]string) {
@@ -52,13 +127,17 @@ func (p *ProductUpdateRequest) Validate() (bool, map[string]string) {
 	if p.Description != nil && len(*p.Description) < 10 {
 		errs["description"] = "description must have more than 10 characters"
 	}
-	if p.Price != nil && *p.Price <= 0 {
-		errs["price"] = "price must be greater than 0"
+	if p.Price != nil {
+		validatePrice(errs, "price", *p.Price)
 	}
 
 	if p.StockQuantity != nil && *p.StockQuantity <= 0 {
 		errs["stock_quantity"] = "stock quantity must be greater than 0"
 	}
+	validateProductDimensions(errs, p.WeightGrams, p.LengthMm, p.WidthMm, p.HeightMm)
+	if p.TaxClass != nil && (len(*p.TaxClass) == 0 || len(*p.TaxClass) > 50) {
+		errs["tax_class"] = "tax class must have between 1 and 50 characters"
+	}
 	return len(errs) == 0, errs
 }
 
@@ -71,17 +150,207 @@ func (p *ProductUpdateRequest) ToMap() map[string]any {
 		result["description"] = *p.Description
 	}
 	if p.Price != nil {
-		result["price"] = *p.Price
+		result["price_amount"] = p.Price.Amount
+		result["price_currency"] = strings.ToUpper(p.Price.Currency)
 	}
 	if p.StockQuantity != nil {
 		result["stock_quantity"] = *p.StockQuantity
 	}
+	if p.WeightGrams != nil {
+		result["weight_grams"] = *p.WeightGrams
+	}
+	if p.LengthMm != nil {
+		result["length_mm"] = *p.LengthMm
+	}
+	if p.WidthMm != nil {
+		result["width_mm"] = *p.WidthMm
+	}
+	if p.HeightMm != nil {
+		result["height_mm"] = *p.HeightMm
+	}
+	if p.TaxClass != nil {
+		result["tax_class"] = *p.TaxClass
+	}
 	return result
 }
 
+// ProductPricesUpdate replaces every per-currency price override of a
+// product, an empty list removes them all.
+type ProductPricesUpdate struct {
+	Prices []Money `json:"prices"`
+}
+
+func (p *ProductPricesUpdate) Validate() (bool, map[string]string) {
+	errs := make(map[string]string)
+	validatePriceOverrides(errs, "", p.Prices)
+	return len(errs) == 0, errs
+}
+
+// ProductCategoriesUpdate replaces every category assignment of a product, an
+// empty list removes them all.
+type ProductCategoriesUpdate struct {
+	CategoryIds []uuid.UUID `json:"category_ids"`
+}
+
+func (p *ProductCategoriesUpdate) Validate() (bool, map[string]string) {
+	errs := make(map[string]string)
+	validateCategoryIds(errs, p.CategoryIds)
+	return len(errs) == 0, errs
+}
+
+func validateCategoryIds(errs map[string]string, ids []uuid.UUID) {
+	seen := make(map[uuid.UUID]bool)
+	for _, id := range ids {
+		if seen[id] {
+			errs["category_ids"] = "a category can only be listed once"
+		}
+		seen[id] = true
+	}
+}
+
+func validatePrice(errs map[string]string, field string, price Money) {
+	if price.Amount <= 0 {
+		errs[field] = "price must be greater than 0"
+	}
+	if !ValidCurrency(price.Currency) {
+		errs[field] = "price needs a three letter currency code"
+	}
+}
+
+// validatePriceOverrides checks that every override is a valid price in its
+// own currency, other than the base currency.
+func validatePriceOverrides(errs map[string]string, baseCurrency string, prices []Money) {
+	seen := map[string]bool{strings.ToUpper(baseCurrency): true}
+	for _, price := range prices {
+		validatePrice(errs, "prices", price)
+		currency := strings.ToUpper(price.Currency)
+		if seen[currency] {
+			errs["prices"] = "only one price per currency is allowed"
+		}
+		seen[currency] = true
+	}
+}
+
+// validateProductDimensions checks the shipping related fields of a product,
+// nil fields are skipped.
+func validateProductDimensions(errs map[string]string, weightGrams, lengthMm, widthMm, heightMm *int) {
+	if weightGrams != nil && *weightGrams < 0 {
+		errs["weight_grams"] = "weight can't be negative"
+	}
+	if lengthMm != nil && *lengthMm < 0 {
+		errs["length_mm"] = "length can't be negative"
+	}
+	if widthMm != nil && *widthMm < 0 {
+		errs["width_mm"] = "width can't be negative"
+	}
+	if heightMm != nil && *heightMm < 0 {
+		errs["height_mm"] = "height can't be negative"
+	}
+}
+
+type ProductOptionRequest struct {
+	Name   string   `json:"name" binding:"required"`
+	Values []string `json:"values" binding:"required"`
+}
+
+// ProductOptionsUpdate replaces every option of a product, in the order they
+// are listed.
+type ProductOptionsUpdate struct {
+	Options []ProductOptionRequest `json:"options"`
+}
+
+func (p *ProductOptionsUpdate) Validate() (bool, map[string]string) {
+	errs := make(map[string]string)
+	names := make(map[string]bool)
+	for _, option := range p.Options {
+		if len(option.Name) == 0 || len(option.Name) > 50 {
+			errs["options"] = "option names must have between 1 and 50 characters"
+		}
+		if names[option.Name] {
+			errs["options"] = "option names must be unique"
+		}
+		names[option.Name] = true
+		if len(option.Values) == 0 {
+			errs["options"] = "every option needs at least one value"
+		}
+		values := make(map[string]bool)
+		for _, value := range option.Values {
+			if len(value) == 0 || len(value) > 50 {
+				errs["options"] = "option values must have between 1 and 50 characters"
+			}
+			if values[value] {
+				errs["options"] = "the values of an option must be unique"
+			}
+			values[value] = true
+		}
+	}
+	return len(errs) == 0, errs
+}
+
+type VariantCreate struct {
+	Sku     string            `json:"sku" binding:"required"`
+	Options map[string]string `json:"options"`
+	// Price overrides the product's price in its currency
+	Price         *Money `json:"price"`
+	StockQuantity int    `json:"stock_quantity"`
+}
+
+func (v *VariantCreate) Validate() (bool, map[string]string) {
+	errs := make(map[string]string)
+	validateVariantFields(errs, &v.Sku, v.Price, &v.StockQuantity)
+	if len(v.Options) == 0 {
+		errs["options"] = "options must hold a value for every option of the product"
+	}
+	return len(errs) == 0, errs
+}
+
+type VariantUpdateRequest struct {
+	Sku           *string `json:"sku"`
+	Price         *Money  `json:"price"`
+	StockQuantity *int    `json:"stock_quantity"`
+}
+
+func (v *VariantUpdateRequest) Validate() (bool, map[string]string) {
+	errs := make(map[string]string)
+	validateVariantFields(errs, v.Sku, v.Price, v.StockQuantity)
+	return len(errs) == 0, errs
+}
+
+func (v *VariantUpdateRequest) ToMap() map[string]any {
+	result := make(map[string]any)
+	if v.Sku != nil {
+		result["sku"] = *v.Sku
+	}
+	if v.Price != nil {
+		result["price_amount"] = v.Price.Amount
+		result["price_currency"] = strings.ToUpper(v.Price.Currency)
+	}
+	if v.StockQuantity != nil {
+		result["stock_quantity"] = *v.StockQuantity
+	}
+	return result
+}
+
+func validateVariantFields(errs map[string]string, sku *string, price *Money, stockQuantity *int) {
+	if sku != nil && (len(*sku) == 0 || len(*sku) > 64) {
+		errs["sku"] = "sku must have between 1 and 64 characters"
+	}
+	if price != nil {
+		validatePrice(errs, "price", *price)
+	}
+	if stockQuantity != nil && *stockQuantity < 0 {
+		errs["stock_quantity"] = "stock quantity can't be negative"
+	}
+}
+
 type ItemCartRequest struct {
 	ProductId uuid.UUID `json:"product_id" binding:"required"`
-	Quantity  int       `json:"quantity" binding:"required"`
+	// VariantId is required for products sold through variants
+	VariantId *uuid.UUID `json:"variant_id"`
+	Quantity  int        `json:"quantity" binding:"required"`
+	// Currency picks the currency of a new cart, for an existing cart it
+	// must match the cart's currency
+	Currency string `json:"currency"`
 }
 
 func (i *ItemCartRequest) Validate() (bool, map[string]string) {
@@ -89,6 +358,9 @@ func (i *ItemCartRequest) Validate() (bool, map[string]string) {
 	if i.Quantity <= 0 {
 		errs["quantity"] = "quantity should be greater than 0"
 	}
+	if i.Currency != "" && !ValidCurrency(i.Currency) {
+		errs["currency"] = "currency must be a three letter code"
+	}
 	return len(errs) == 0, errs
 }
 
@@ -103,3 +375,428 @@ func (i *ItemQuantityUpdate) Validate() (bool, map[string]string) {
 	}
 	return len(errs) == 0, errs
 }
+
+// CheckoutRequest ships to the saved address AddressId, or to the free form
+// ShippingAddress. Without either the user's default address is used.
+type CheckoutRequest struct {
+	AddressId       *uuid.UUID `json:"address_id"`
+	ShippingAddress *string    `json:"shipping_address"`
+	// ShippingCountry is required along with a free form shipping address,
+	// ShippingRegion is optional. The order is taxed for them, without a
+	// region only the country wide rules apply
+	ShippingCountry *string `json:"shipping_country"`
+	ShippingRegion  *string `json:"shipping_region"`
+	ShippingMethod  string  `json:"shipping_method" binding:"required"`
+	PaymentToken    string  `json:"payment_token" binding:"required"`
+}
+
+func (c *CheckoutRequest) Validate() (bool, map[string]string) {
+	errs := make(map[string]string)
+	if c.AddressId != nil && c.ShippingAddress != nil {
+		errs["address_id"] = "address_id and shipping_address can't be used together"
+	}
+	if c.ShippingAddress != nil && len(*c.ShippingAddress) < 10 {
+		errs["shipping_address"] = "shipping address must have more than 10 characters"
+	}
+	if c.ShippingAddress != nil && (c.ShippingCountry == nil || len(*c.ShippingCountry) != 2) {
+		errs["shipping_country"] = "shipping_country must be a two letter ISO code when shipping_address is used"
+	}
+	if c.ShippingAddress == nil && (c.ShippingCountry != nil || c.ShippingRegion != nil) {
+		errs["shipping_country"] = "shipping_country and shipping_region only go along with shipping_address"
+	}
+	return len(errs) == 0, errs
+}
+
+type OrderStatusUpdate struct {
+	Status OrderStatus `json:"status" binding:"required"`
+}
+
+func (o *OrderStatusUpdate) Validate() (bool, map[string]string) {
+	errs := make(map[string]string)
+	if !o.Status.IsValid() {
+		errs["status"] = "unknown order status"
+	} else if o.Status.IsRefund() {
+		errs["status"] = "orders are only marked refunded by refunding them"
+	}
+	return len(errs) == 0, errs
+}
+
+type PaymentEvent struct {
+	ID   string           `json:"id" binding:"required"`
+	Type PaymentEventType `json:"type" binding:"required"`
+	Data PaymentEventData `json:"data" binding:"required"`
+}
+
+type PaymentEventData struct {
+	OrderId   uuid.UUID `json:"order_id" binding:"required"`
+	Reference string    `json:"reference"`
+	Reason    string    `json:"reason"`
+}
+
+func (p *PaymentEvent) Validate() (bool, map[string]string) {
+	errs := make(map[string]string)
+	switch p.Type {
+	case PaymentEventCaptured, PaymentEventFailed, PaymentEventRefunded:
+	default:
+		errs["type"] = "unsupported event type"
+	}
+	if p.Type == PaymentEventCaptured && p.Data.Reference == "" {
+		errs["data.reference"] = "reference is required for captured payments"
+	}
+	return len(errs) == 0, errs
+}
+
+type RefundItemRequest struct {
+	OrderItemId uuid.UUID `json:"order_item_id" binding:"required"`
+	Quantity    int       `json:"quantity" binding:"required"`
+}
+
+// RefundRequest refunds the listed order lines, or everything that is left
+// on the order when Items is empty.
+type RefundRequest struct {
+	Items   []RefundItemRequest `json:"items"`
+	Restock bool                `json:"restock"`
+	Reason  *string             `json:"reason"`
+}
+
+func (r *RefundRequest) Validate() (bool, map[string]string) {
+	errs := make(map[string]string)
+	seen := make(map[uuid.UUID]bool)
+	for _, item := range r.Items {
+		if item.Quantity <= 0 {
+			errs["items"] = "quantity should be greater than 0"
+		}
+		if seen[item.OrderItemId] {
+			errs["items"] = "order items can only be listed once"
+		}
+		seen[item.OrderItemId] = true
+	}
+	if r.Reason != nil && len(*r.Reason) > 500 {
+		errs["reason"] = "reason must have less than 500 characters"
+	}
+	return len(errs) == 0, errs
+}
+
+type AddressCreate struct {
+	FullName   string  `json:"full_name" binding:"required"`
+	Line1      string  `json:"line1" binding:"required"`
+	Line2      *string `json:"line2"`
+	City       string  `json:"city" binding:"required"`
+	Region     *string `json:"region"`
+	PostalCode string  `json:"postal_code" binding:"required"`
+	Country    string  `json:"country" binding:"required"`
+	Phone      *string `json:"phone"`
+	IsDefault  bool    `json:"is_default"`
+}
+
+func (a *AddressCreate) Validate() (bool, map[string]string) {
+	errs := make(map[string]string)
+	validateAddressFields(errs, &a.FullName, &a.Line1, &a.City, &a.PostalCode, &a.Country)
+	return len(errs) == 0, errs
+}
+
+type AddressUpdateRequest struct {
+	FullName   *string `json:"full_name"`
+	Line1      *string `json:"line1"`
+	Line2      *string `json:"line2"`
+	City       *string `json:"city"`
+	Region     *string `json:"region"`
+	PostalCode *string `json:"postal_code"`
+	Country    *string `json:"country"`
+	Phone      *string `json:"phone"`
+	IsDefault  *bool   `json:"is_default"`
+}
+
+func (a *AddressUpdateRequest) Validate() (bool, map[string]string) {
+	errs := make(map[string]string)
+	validateAddressFields(errs, a.FullName, a.Line1, a.City, a.PostalCode, a.Country)
+	if a.IsDefault != nil && !*a.IsDefault {
+		errs["is_default"] = "set another address as default instead"
+	}
+	return len(errs) == 0, errs
+}
+
+func (a *AddressUpdateRequest) ToMap() map[string]any {
+	result := make(map[string]any)
+	if a.FullName != nil {
+		result["full_name"] = *a.FullName
+	}
+	if a.Line1 != nil {
+		result["line1"] = *a.Line1
+	}
+	if a.Line2 != nil {
+		result["line2"] = *a.Line2
+	}
+	if a.City != nil {
+		result["city"] = *a.City
+	}
+	if a.Region != nil {
+		result["region"] = *a.Region
+	}
+	if a.PostalCode != nil {
+		result["postal_code"] = *a.PostalCode
+	}
+	if a.Country != nil {
+		result["country"] = strings.ToUpper(*a.Country)
+	}
+	if a.Phone != nil {
+		result["phone"] = *a.Phone
+	}
+	if a.IsDefault != nil {
+		result["is_default"] = *a.IsDefault
+	}
+	return result
+}
+
+// validateAddressFields checks the fields shared by address requests, nil
+// fields are skipped.
+func validateAddressFields(errs map[string]string, fullName, line1, city, postalCode, country *string) {
+	if fullName != nil && len(*fullName) < 2 {
+		errs["full_name"] = "full name must have more than 2 characters"
+	}
+	if line1 != nil && len(*line1) < 3 {
+		errs["line1"] = "line1 must have more than 3 characters"
+	}
+	if city != nil && len(*city) < 2 {
+		errs["city"] = "city must have more than 2 characters"
+	}
+	if postalCode != nil && (len(*postalCode) < 2 || len(*postalCode) > 20) {
+		errs["postal_code"] = "postal code must have between 2 and 20 characters"
+	}
+	if country != nil && len(*country) != 2 {
+		errs["country"] = "country must be a two letter ISO code"
+	}
+}
+
+type ShippingQuoteRequest struct {
+	AddressId string `form:"address_id"`
+	Country   string `form:"country"`
+}
+
+func (s *ShippingQuoteRequest) Validate() (bool, map[string]string) {
+	errs := make(map[string]string)
+	if s.AddressId != "" {
+		if _, err := uuid.Parse(s.AddressId); err != nil {
+			errs["address_id"] = "address_id must be a valid id"
+		}
+		if s.Country != "" {
+			errs["address_id"] = "address_id and country can't be used together"
+		}
+	}
+	if s.Country != "" && len(s.Country) != 2 {
+		errs["country"] = "country must be a two letter ISO code"
+	}
+	return len(errs) == 0, errs
+}
+
+type CouponCreate struct {
+	Code       string     `json:"code" binding:"required"`
+	Type       CouponType `json:"type" binding:"required"`
+	PercentBps int64      `json:"percent_bps"`
+	// FixedAmount is required for fixed coupons only
+	FixedAmount           *Money     `json:"fixed_amount"`
+	MinTotal              *Money     `json:"min_total"`
+	StartsAt              *time.Time `json:"starts_at"`
+	EndsAt                *time.Time `json:"ends_at"`
+	MaxRedemptions        *int       `json:"max_redemptions"`
+	MaxRedemptionsPerUser *int       `json:"max_redemptions_per_user"`
+}
+
+func (c *CouponCreate) Validate() (bool, map[string]string) {
+	errs := make(map[string]string)
+	if len(c.Code) < 3 || len(c.Code) > 50 {
+		errs["code"] = "code must have between 3 and 50 characters"
+	}
+	switch c.Type {
+	case CouponTypePercentage:
+		if c.PercentBps <= 0 || c.PercentBps > 10000 {
+			errs["percent_bps"] = "percent_bps must be between 1 and 10000"
+		}
+	case CouponTypeFixed:
+		if c.FixedAmount == nil {
+			errs["fixed_amount"] = "fixed_amount is required for fixed coupons"
+		} else {
+			validatePrice(errs, "fixed_amount", *c.FixedAmount)
+		}
+	case CouponTypeFreeShipping:
+	default:
+		errs["type"] = "type must be one of percentage, fixed or free_shipping"
+	}
+	if c.MinTotal != nil {
+		validatePrice(errs, "min_total", *c.MinTotal)
+		if c.Type == CouponTypeFixed && c.FixedAmount != nil &&
+			!strings.EqualFold(c.MinTotal.Currency, c.FixedAmount.Currency) {
+			errs["min_total"] = "min_total must be in the currency of fixed_amount"
+		}
+	}
+	if c.StartsAt != nil && c.EndsAt != nil && !c.EndsAt.After(*c.StartsAt) {
+		errs["ends_at"] = "ends_at must be after starts_at"
+	}
+	if c.MaxRedemptions != nil && *c.MaxRedemptions <= 0 {
+		errs["max_redemptions"] = "max_redemptions must be greater than 0"
+	}
+	if c.MaxRedemptionsPerUser != nil && *c.MaxRedemptionsPerUser <= 0 {
+		errs["max_redemptions_per_user"] = "max_redemptions_per_user must be greater than 0"
+	}
+	return len(errs) == 0, errs
+}
+
+type CouponApplyRequest struct {
+	Code string `json:"code" binding:"required"`
+}
+
+type PromotionCreate struct {
+	Name         string        `json:"name" binding:"required"`
+	Type         PromotionType `json:"type" binding:"required"`
+	Priority     int           `json:"priority"`
+	Exclusive    bool          `json:"exclusive"`
+	PercentBps   int64         `json:"percent_bps"`
+	BuyQuantity  int           `json:"buy_quantity"`
+	FreeQuantity int           `json:"free_quantity"`
+	Currency     string        `json:"currency"`
+	Tiers        []SpendTier   `json:"tiers"`
+	ProductIds   []uuid.UUID   `json:"product_ids"`
+	CategoryIds  []uuid.UUID   `json:"category_ids"`
+	StartsAt     *time.Time    `json:"starts_at"`
+	EndsAt       *time.Time    `json:"ends_at"`
+	// Active defaults to true
+	Active *bool `json:"active"`
+}
+
+func (p *PromotionCreate) Validate() (bool, map[string]string) {
+	errs := make(map[string]string)
+	if len(p.Name) < 2 || len(p.Name) > 100 {
+		errs["name"] = "name must have between 2 and 100 characters"
+	}
+	switch p.Type {
+	case PromotionTypePercentage:
+		if p.PercentBps <= 0 || p.PercentBps > 10000 {
+			errs["percent_bps"] = "percent_bps must be between 1 and 10000"
+		}
+	case PromotionTypeBuyXGetY:
+		if p.BuyQuantity <= 0 {
+			errs["buy_quantity"] = "buy_quantity must be greater than 0"
+		}
+		if p.FreeQuantity <= 0 {
+			errs["free_quantity"] = "free_quantity must be greater than 0"
+		}
+	case PromotionTypeSpendTiers:
+		if !ValidCurrency(p.Currency) {
+			errs["currency"] = "currency must be a three letter code"
+		}
+		if len(p.Tiers) == 0 {
+			errs["tiers"] = "spend tier promotions need at least one tier"
+		}
+		for _, tier := range p.Tiers {
+			if tier.Threshold <= 0 || tier.Amount <= 0 {
+				errs["tiers"] = "tier thresholds and amounts must be greater than 0"
+			}
+		}
+	default:
+		errs["type"] = "type must be one of percentage, buy_x_get_y or spend_tiers"
+	}
+	if p.StartsAt != nil && p.EndsAt != nil && !p.EndsAt.After(*p.StartsAt) {
+		errs["ends_at"] = "ends_at must be after starts_at"
+	}
+	return len(errs) == 0, errs
+}
+
+var categorySlugRegex = regexp.MustCompile(`^[a-z0-9]+(-[a-z0-9]+)*$`)
+
+type CategoryCreate struct {
+	Name        string  `json:"name" binding:"required"`
+	Slug        string  `json:"slug" binding:"required"`
+	Description *string `json:"description"`
+	// ParentId nests the category, nil makes it a top level category
+	ParentId *uuid.UUID `json:"parent_id"`
+}
+
+func (c *CategoryCreate) Validate() (bool, map[string]string) {
+	errs := make(map[string]string)
+	validateCategoryFields(errs, &c.Name, &c.Slug)
+	return len(errs) == 0, errs
+}
+
+type CategoryUpdateRequest struct {
+	Name        *string    `json:"name"`
+	Slug        *string    `json:"slug"`
+	Description *string    `json:"description"`
+	ParentId    *uuid.UUID `json:"parent_id"`
+	// Root moves the category to the top level, it can't be combined with
+	// ParentId
+	Root bool `json:"root"`
+}
+
+func (c *CategoryUpdateRequest) Validate() (bool, map[string]string) {
+	errs := make(map[string]string)
+	validateCategoryFields(errs, c.Name, c.Slug)
+	if c.Root && c.ParentId != nil {
+		errs["parent_id"] = "parent_id can't be set along with root"
+	}
+	return len(errs) == 0, errs
+}
+
+func (c *CategoryUpdateRequest) ToMap() map[string]any {
+	result := make(map[string]any)
+	if c.Name != nil {
+		result["name"] = *c.Name
+	}
+	if c.Slug != nil {
+		result["slug"] = *c.Slug
+	}
+	if c.Description != nil {
+		result["description"] = *c.Description
+	}
+	if c.ParentId != nil {
+		result["parent_id"] = *c.ParentId
+	}
+	if c.Root {
+		result["parent_id"] = nil
+	}
+	return result
+}
+
+func validateCategoryFields(errs map[string]string, name, slug *string) {
+	if name != nil && (len(*name) < 2 || len(*name) > 100) {
+		errs["name"] = "name must have between 2 and 100 characters"
+	}
+	if slug != nil && (len(*slug) > 100 || !categorySlugRegex.MatchString(*slug)) {
+		errs["slug"] = "slug must be lowercase letters, digits and dashes, up to 100 characters"
+	}
+}
+
+// ProductListQuery holds the query parameters of a product listing.
+type ProductListQuery struct {
+	MinPrice *int64 `form:"min_price"`
+	MaxPrice *int64 `form:"max_price"`
+	// Currency is required to filter or sort by price
+	Currency string `form:"currency"`
+	InStock  bool   `form:"in_stock"`
+	// Category is a category slug, its subcategories match too
+	Category     string     `form:"category"`
+	CreatedAfter *time.Time `form:"created_after" time_format:"2006-01-02T15:04:05Z07:00"`
+	Sort         string     `form:"sort"`
+}
+
+func (p *ProductListQuery) Validate() (bool, map[string]string) {
+	errs := make(map[string]string)
+	if p.MinPrice != nil && *p.MinPrice < 0 {
+		errs["min_price"] = "min_price can't be negative"
+	}
+	if p.MaxPrice != nil && *p.MaxPrice < 0 {
+		errs["max_price"] = "max_price can't be negative"
+	}
+	if p.MinPrice != nil && p.MaxPrice != nil && *p.MinPrice > *p.MaxPrice {
+		errs["max_price"] = "max_price must not be below min_price"
+	}
+	if p.Sort != "" && !ProductSort(p.Sort).IsValid() {
+		errs["sort"] = "sort must be one of price, -price, name, -name, newest or popularity"
+	}
+	filtersByPrice := p.MinPrice != nil || p.MaxPrice != nil || ProductSort(p.Sort).SortsByPrice()
+	if p.Currency != "" && !ValidCurrency(p.Currency) {
+		errs["currency"] = "currency must be a three letter code"
+	} else if p.Currency == "" && filtersByPrice {
+		errs["currency"] = "currency is required to filter or sort by price"
+	}
+	return len(errs) == 0, errs
+}
@@ -0,0 +1,32 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type ReservationStatus string
+
+const (
+	// ReservationStatusHeld stock is taken out of the product but the order
+	// is not paid yet, the hold is released once it expires.
+	ReservationStatusHeld ReservationStatus = "held"
+	// ReservationStatusCommitted the order was paid and the stock is sold.
+	ReservationStatusCommitted ReservationStatus = "committed"
+	// ReservationStatusReleased the stock was given back to the product.
+	ReservationStatusReleased ReservationStatus = "released"
+)
+
+type StockReservation struct {
+	ID        uuid.UUID
+	OrderId   uuid.UUID
+	ProductId uuid.UUID
+	// VariantId is set when the stock was taken out of a variant
+	VariantId *uuid.UUID
+	Quantity  int
+	Status    ReservationStatus
+	ExpiresAt time.Time
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
@@ -1,27 +1,113 @@
 package models
 
 import (
+	"time"
+
 	"github.com/google/uuid"
 )
 
 type ProductResponse struct {
-	ID            uuid.UUID `json:"id"`
-	Name          string    `json:"name"`
-	Description   *string   `json:"description,omitempty"`
-	Price         int64     `json:"price"`
-	StockQuantity int       `json:"stock_quantity"`
+	ID            uuid.UUID                `json:"id"`
+	Name          string                   `json:"name"`
+	Description   *string                  `json:"description,omitempty"`
+	Price         Money                    `json:"price"`
+	Prices        []Money                  `json:"prices"`
+	StockQuantity int                      `json:"stock_quantity"`
+	WeightGrams   int                      `json:"weight_grams"`
+	LengthMm      int                      `json:"length_mm"`
+	WidthMm       int                      `json:"width_mm"`
+	HeightMm      int                      `json:"height_mm"`
+	TaxClass      string                   `json:"tax_class"`
+	Categories    []CategoryResponse       `json:"categories"`
+	Options       []ProductOptionResponse  `json:"options"`
+	Variants      []ProductVariantResponse `json:"variants"`
+}
+
+type ProductOptionResponse struct {
+	Name   string   `json:"name"`
+	Values []string `json:"values"`
+}
+
+type ProductVariantResponse struct {
+	ID      uuid.UUID         `json:"id"`
+	Sku     string            `json:"sku"`
+	Options map[string]string `json:"options"`
+	// Price is only set when the variant overrides the product's price
+	Price         *Money `json:"price,omitempty"`
+	StockQuantity int    `json:"stock_quantity"`
+}
+
+func VariantToVariantResponse(variant ProductVariant) ProductVariantResponse {
+	response := ProductVariantResponse{
+		ID:            variant.ID,
+		Sku:           variant.Sku,
+		Options:       variant.Options,
+		StockQuantity: variant.StockQuantity,
+	}
+	if !variant.Price.IsZero() {
+		price := variant.Price
+		response.Price = &price
+	}
+	return response
 }
 
 func ProductToProductResponse(product Product) ProductResponse {
+	prices := make([]Money, len(product.Prices))
+	for idx, override := range product.Prices {
+		prices[idx] = override.Price
+	}
+	options := make([]ProductOptionResponse, len(product.Options))
+	for idx, option := range product.Options {
+		options[idx] = ProductOptionResponse{Name: option.Name, Values: option.Values}
+	}
+	variants := make([]ProductVariantResponse, len(product.Variants))
+	for idx, variant := range product.Variants {
+		variants[idx] = VariantToVariantResponse(variant)
+	}
 	return ProductResponse{
 		ID:            product.ID,
 		Name:          product.Name,
 		Description:   product.Description,
 		Price:         product.Price,
+		Prices:        prices,
 		StockQuantity: product.StockQuantity,
+		WeightGrams:   product.WeightGrams,
+		LengthMm:      product.LengthMm,
+		WidthMm:       product.WidthMm,
+		HeightMm:      product.HeightMm,
+		TaxClass:      product.TaxClass,
+		Categories:    CategoriesToCategoriesResponse(product.Categories),
+		Options:       options,
+		Variants:      variants,
 	}
 }
 
+type ProductSearchHitResponse struct {
+	ProductResponse
+	Rank       float64                 `json:"rank"`
+	Highlights SearchHighlightResponse `json:"highlights"`
+}
+
+type SearchHighlightResponse struct {
+	Name        string  `json:"name"`
+	Description *string `json:"description,omitempty"`
+}
+
+func SearchHitsToSearchHitsResponse(hits []ProductSearchHit) []ProductSearchHitResponse {
+	result := make([]ProductSearchHitResponse, len(hits))
+	for idx, hit := range hits {
+		result[idx] = ProductSearchHitResponse{
+			ProductResponse: ProductToProductResponse(hit.Product),
+			Rank:            hit.Rank,
+			Highlights: SearchHighlightResponse{
+				Name:        hit.NameHighlight,
+				Description: hit.DescriptionHighlight,
+			},
+		}
+	}
+	return result
+}
+
 func ProductsToProductsResponse(products []Product) []ProductResponse {
 	result := make([]ProductResponse, len(products))
 	for idx, p := range products {
@@ -29,3 +115,338 @@ func ProductsToProductsResponse(products []Product) []ProductResponse {
 	}
 	return result
 }
+
+type OrderItemResponse struct {
+	ID        uuid.UUID  `json:"id"`
+	ProductId uuid.UUID  `json:"product_id"`
+	VariantId *uuid.UUID `json:"variant_id,omitempty"`
+	Sku       *string    `json:"sku,omitempty"`
+	Quantity  int        `json:"quantity"`
+	UnitPrice Money      `json:"unit_price"`
+	SubTotal  Money      `json:"subtotal"`
+	Discount  Money      `json:"discount"`
+	Tax       Money      `json:"tax"`
+}
+
+type OrderResponse struct {
+	ID              uuid.UUID           `json:"id"`
+	Status          OrderStatus         `json:"status"`
+	Total           Money               `json:"total"`
+	ShippingMethod  *string             `json:"shipping_method,omitempty"`
+	ShippingCost    Money               `json:"shipping_cost"`
+	Discount        Money               `json:"discount"`
+	CouponCode      *string             `json:"coupon_code,omitempty"`
+	TaxTotal        Money               `json:"tax_total"`
+	Tax             *TaxBreakdown       `json:"tax,omitempty"`
+	ShippingAddress string              `json:"shipping_address"`
+	ShippingTo      *AddressSnapshot    `json:"shipping_to,omitempty"`
+	Items           []OrderItemResponse `json:"items"`
+	CreatedAt       time.Time           `json:"created_at"`
+	UpdatedAt       time.Time           `json:"updated_at"`
+}
+
+func OrderToOrderResponse(order Order) OrderResponse {
+	items := make([]OrderItemResponse, len(order.Items))
+	for idx, item := range order.Items {
+		items[idx] = OrderItemResponse{
+			ID:        item.ID,
+			ProductId: item.ProductId,
+			VariantId: item.VariantId,
+			Sku:       item.Sku,
+			Quantity:  item.Quantity,
+			UnitPrice: item.UnitPrice,
+			SubTotal:  item.UnitPrice.Mul(int64(item.Quantity)),
+			Discount:  item.Discount,
+			Tax:       item.Tax,
+		}
+	}
+	return OrderResponse{
+		ID:              order.ID,
+		Status:          order.Status,
+		Total:           order.Total,
+		ShippingMethod:  order.ShippingMethod,
+		ShippingCost:    order.ShippingCost,
+		Discount:        order.Discount,
+		CouponCode:      order.CouponCode,
+		TaxTotal:        order.Tax,
+		Tax:             order.TaxBreakdown,
+		ShippingAddress: order.ShippingAddress,
+		ShippingTo:      order.ShippingAddressSnapshot,
+		Items:           items,
+		CreatedAt:       order.CreatedAt,
+		UpdatedAt:       order.UpdatedAt,
+	}
+}
+
+func OrdersToOrdersResponse(orders []Order) []OrderResponse {
+	result := make([]OrderResponse, len(orders))
+	for idx, o := range orders {
+		result[idx] = OrderToOrderResponse(o)
+	}
+	return result
+}
+
+type OrderStatusChangeResponse struct {
+	FromStatus OrderStatus `json:"from_status"`
+	ToStatus   OrderStatus `json:"to_status"`
+	ChangedBy  *uuid.UUID  `json:"changed_by"`
+	CreatedAt  time.Time   `json:"created_at"`
+}
+
+func OrderStatusChangesToResponse(changes []OrderStatusChange) []OrderStatusChangeResponse {
+	result := make([]OrderStatusChangeResponse, len(changes))
+	for idx, c := range changes {
+		result[idx] = OrderStatusChangeResponse{
+			FromStatus: c.FromStatus,
+			ToStatus:   c.ToStatus,
+			ChangedBy:  c.ChangedBy,
+			CreatedAt:  c.CreatedAt,
+		}
+	}
+	return result
+}
+
+type RefundItemResponse struct {
+	OrderItemId uuid.UUID  `json:"order_item_id"`
+	ProductId   uuid.UUID  `json:"product_id"`
+	VariantId   *uuid.UUID `json:"variant_id,omitempty"`
+	Quantity    int        `json:"quantity"`
+	Amount      Money      `json:"amount"`
+}
+
+type RefundResponse struct {
+	ID         uuid.UUID            `json:"id"`
+	OrderId    uuid.UUID            `json:"order_id"`
+	Amount     Money                `json:"amount"`
+	Adjustment Money                `json:"adjustment"`
+	Status     RefundStatus         `json:"status"`
+	Reason     *string              `json:"reason,omitempty"`
+	Restock    bool                 `json:"restock"`
+	CreatedBy  uuid.UUID            `json:"created_by"`
+	Items      []RefundItemResponse `json:"items"`
+	CreatedAt  time.Time            `json:"created_at"`
+}
+
+func RefundToRefundResponse(refund Refund) RefundResponse {
+	items := make([]RefundItemResponse, len(refund.Items))
+	for idx, item := range refund.Items {
+		items[idx] = RefundItemResponse{
+			OrderItemId: item.OrderItemId,
+			ProductId:   item.ProductId,
+			VariantId:   item.VariantId,
+			Quantity:    item.Quantity,
+			Amount:      item.Amount,
+		}
+	}
+	return RefundResponse{
+		ID:         refund.ID,
+		OrderId:    refund.OrderId,
+		Amount:     refund.Amount,
+		Adjustment: refund.Adjustment,
+		Status:     refund.Status,
+		Reason:     refund.Reason,
+		Restock:    refund.Restock,
+		CreatedBy:  refund.CreatedBy,
+		Items:      items,
+		CreatedAt:  refund.CreatedAt,
+	}
+}
+
+func RefundsToRefundsResponse(refunds []Refund) []RefundResponse {
+	result := make([]RefundResponse, len(refunds))
+	for idx, r := range refunds {
+		result[idx] = RefundToRefundResponse(r)
+	}
+	return result
+}
+
+type AddressResponse struct {
+	ID         uuid.UUID `json:"id"`
+	FullName   string    `json:"full_name"`
+	Line1      string    `json:"line1"`
+	Line2      *string   `json:"line2,omitempty"`
+	City       string    `json:"city"`
+	Region     *string   `json:"region,omitempty"`
+	PostalCode string    `json:"postal_code"`
+	Country    string    `json:"country"`
+	Phone      *string   `json:"phone,omitempty"`
+	IsDefault  bool      `json:"is_default"`
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+func AddressToAddressResponse(address Address) AddressResponse {
+	return AddressResponse{
+		ID:         address.ID,
+		FullName:   address.FullName,
+		Line1:      address.Line1,
+		Line2:      address.Line2,
+		City:       address.City,
+		Region:     address.Region,
+		PostalCode: address.PostalCode,
+		Country:    address.Country,
+		Phone:      address.Phone,
+		IsDefault:  address.IsDefault,
+		CreatedAt:  address.CreatedAt,
+		UpdatedAt:  address.UpdatedAt,
+	}
+}
+
+func AddressesToAddressesResponse(addresses []Address) []AddressResponse {
+	result := make([]AddressResponse, len(addresses))
+	for idx, a := range addresses {
+		result[idx] = AddressToAddressResponse(a)
+	}
+	return result
+}
+
+type CouponResponse struct {
+	ID                    uuid.UUID  `json:"id"`
+	Code                  string     `json:"code"`
+	Type                  CouponType `json:"type"`
+	PercentBps            int64      `json:"percent_bps,omitempty"`
+	FixedAmount           *Money     `json:"fixed_amount,omitempty"`
+	MinTotal              *Money     `json:"min_total,omitempty"`
+	StartsAt              *time.Time `json:"starts_at,omitempty"`
+	EndsAt                *time.Time `json:"ends_at,omitempty"`
+	MaxRedemptions        *int       `json:"max_redemptions,omitempty"`
+	MaxRedemptionsPerUser *int       `json:"max_redemptions_per_user,omitempty"`
+	Redemptions           int        `json:"redemptions"`
+	CreatedAt             time.Time  `json:"created_at"`
+}
+
+func CouponToCouponResponse(coupon Coupon) CouponResponse {
+	response := CouponResponse{
+		ID:                    coupon.ID,
+		Code:                  coupon.Code,
+		Type:                  coupon.Type,
+		PercentBps:            coupon.PercentBps,
+		StartsAt:              coupon.StartsAt,
+		EndsAt:                coupon.EndsAt,
+		MaxRedemptions:        coupon.MaxRedemptions,
+		MaxRedemptionsPerUser: coupon.MaxRedemptionsPerUser,
+		Redemptions:           coupon.Redemptions,
+		CreatedAt:             coupon.CreatedAt,
+	}
+	if coupon.Type == CouponTypeFixed {
+		response.FixedAmount = &coupon.FixedAmount
+	}
+	if !coupon.MinTotal.IsZero() {
+		response.MinTotal = &coupon.MinTotal
+	}
+	return response
+}
+
+func CouponsToCouponsResponse(coupons []Coupon) []CouponResponse {
+	result := make([]CouponResponse, len(coupons))
+	for idx, c := range coupons {
+		result[idx] = CouponToCouponResponse(c)
+	}
+	return result
+}
+
+type PromotionResponse struct {
+	ID           uuid.UUID     `json:"id"`
+	Name         string        `json:"name"`
+	Type         PromotionType `json:"type"`
+	Priority     int           `json:"priority"`
+	Exclusive    bool          `json:"exclusive"`
+	PercentBps   int64         `json:"percent_bps,omitempty"`
+	BuyQuantity  int           `json:"buy_quantity,omitempty"`
+	FreeQuantity int           `json:"free_quantity,omitempty"`
+	Currency     string        `json:"currency,omitempty"`
+	Tiers        []SpendTier   `json:"tiers,omitempty"`
+	ProductIds   []uuid.UUID   `json:"product_ids"`
+	CategoryIds  []uuid.UUID   `json:"category_ids"`
+	StartsAt     *time.Time    `json:"starts_at,omitempty"`
+	EndsAt       *time.Time    `json:"ends_at,omitempty"`
+	Active       bool          `json:"active"`
+	CreatedAt    time.Time     `json:"created_at"`
+}
+
+func PromotionToPromotionResponse(promotion Promotion) PromotionResponse {
+	productIds := promotion.ProductIds
+	if productIds == nil {
+		productIds = make([]uuid.UUID, 0)
+	}
+	categoryIds := promotion.CategoryIds
+	if categoryIds == nil {
+		categoryIds = make([]uuid.UUID, 0)
+	}
+	return PromotionResponse{
+		ID:           promotion.ID,
+		Name:         promotion.Name,
+		Type:         promotion.Type,
+		Priority:     promotion.Priority,
+		Exclusive:    promotion.Exclusive,
+		PercentBps:   promotion.PercentBps,
+		BuyQuantity:  promotion.BuyQuantity,
+		FreeQuantity: promotion.FreeQuantity,
+		Currency:     promotion.Currency,
+		Tiers:        promotion.Tiers,
+		ProductIds:   productIds,
+		CategoryIds:  categoryIds,
+		StartsAt:     promotion.StartsAt,
+		EndsAt:       promotion.EndsAt,
+		Active:       promotion.Active,
+		CreatedAt:    promotion.CreatedAt,
+	}
+}
+
+func PromotionsToPromotionsResponse(promotions []Promotion) []PromotionResponse {
+	result := make([]PromotionResponse, len(promotions))
+	for idx, p := range promotions {
+		result[idx] = PromotionToPromotionResponse(p)
+	}
+	return result
+}
+
+type CategoryResponse struct {
+	ID          uuid.UUID  `json:"id"`
+	ParentId    *uuid.UUID `json:"parent_id"`
+	Name        string     `json:"name"`
+	Slug        string     `json:"slug"`
+	Description *string    `json:"description,omitempty"`
+}
+
+func CategoryToCategoryResponse(category Category) CategoryResponse {
+	return CategoryResponse{
+		ID:          category.ID,
+		ParentId:    category.ParentId,
+		Name:        category.Name,
+		Slug:        category.Slug,
+		Description: category.Description,
+	}
+}
+
+func CategoriesToCategoriesResponse(categories []Category) []CategoryResponse {
+	result := make([]CategoryResponse, len(categories))
+	for idx, c := range categories {
+		result[idx] = CategoryToCategoryResponse(c)
+	}
+	return result
+}
+
+type RoleResponse struct {
+	ID          uuid.UUID `json:"id"`
+	Name        string    `json:"name"`
+	Description string    `json:"description"`
+	Permissions []string  `json:"permissions"`
+}
+
+func RoleToRoleResponse(role Role) RoleResponse {
+	return RoleResponse{
+		ID:          role.ID,
+		Name:        role.Name,
+		Description: role.Description,
+		Permissions: PermissionNames([]Role{role}),
+	}
+}
+
+func RolesToRolesResponse(roles []Role) []RoleResponse {
+	result := make([]RoleResponse, len(roles))
+	for idx, r := range roles {
+		result[idx] = RoleToRoleResponse(r)
+	}
+	return result
+}
@@ -0,0 +1,60 @@
+package models
+
+import (
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Permissions the admin endpoints require, see the permissions table for
+// what each of them grants.
+const (
+	PermissionProductsWrite   = "products:write"
+	PermissionCategoriesWrite = "categories:write"
+	PermissionOrdersRead      = "orders:read"
+	PermissionOrdersWrite     = "orders:write"
+	PermissionRefundsWrite    = "refunds:write"
+	PermissionCouponsRead     = "coupons:read"
+	PermissionCouponsWrite    = "coupons:write"
+	PermissionPromotionsRead  = "promotions:read"
+	PermissionPromotionsWrite = "promotions:write"
+	PermissionRolesRead       = "roles:read"
+	PermissionRolesWrite      = "roles:write"
+)
+
+// RoleSuperAdmin grants every permission, at least one user always keeps it.
+const RoleSuperAdmin = "super_admin"
+
+type Permission struct {
+	Name        string `gorm:"primaryKey"`
+	Description string
+}
+
+// Role is a named set of permissions, a user has every permission of every
+// role they are given.
+type Role struct {
+	ID          uuid.UUID
+	Name        string
+	Description string
+	Permissions []Permission `gorm:"many2many:role_permissions;"`
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+}
+
+// PermissionNames returns the names of the permissions the roles grant,
+// each listed once and sorted.
+func PermissionNames(roles []Role) []string {
+	seen := make(map[string]bool)
+	names := make([]string, 0)
+	for _, role := range roles {
+		for _, permission := range role.Permissions {
+			if !seen[permission.Name] {
+				seen[permission.Name] = true
+				names = append(names, permission.Name)
+			}
+		}
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,39 @@
+package models
+
+import (
+	"regexp"
+	"strings"
+)
+
+// searchTermRegex picks the words out of a search query, everything else,
+// tsquery operators included, is dropped.
+var searchTermRegex = regexp.MustCompile(`[\p{L}\p{N}]+`)
+
+// maxSearchTerms bounds how many words of a query are searched for
+const maxSearchTerms = 10
+
+// ProductSearchHit is a product matching a search along with how well it
+// matched and the parts of it that did.
+type ProductSearchHit struct {
+	Product Product
+	Rank    float64
+	// NameHighlight and DescriptionHighlight are HTML escaped and wrap the
+	// matching words in <mark> tags
+	NameHighlight        string
+	DescriptionHighlight *string
+}
+
+// PrefixSearchQuery turns free text into a tsquery matching products that
+// have a word starting with every word of it, so results show up while the
+// user is still typing. It returns an empty string when the text holds no
+// word.
+func PrefixSearchQuery(text string) string {
+	terms := searchTermRegex.FindAllString(strings.ToLower(text), maxSearchTerms)
+	if len(terms) == 0 {
+		return ""
+	}
+	for idx := range terms {
+		terms[idx] += ":*"
+	}
+	return strings.Join(terms, " & ")
+}
@@ -0,0 +1,32 @@
+package models
+
+import "testing"
+
+func TestPrefixSearchQuery(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want string
+	}{
+		{name: "single word", text: "shoe", want: "shoe:*"},
+		{name: "every word must match", text: "Red  Running shoes", want: "red:* & running:* & shoes:*"},
+		{name: "tsquery operators are dropped", text: "shoe | !boot & (sock):*", want: "shoe:* & boot:* & sock:*"},
+		{name: "quotes and backslashes are dropped", text: `o'neil \ "tee"`, want: "o:* & neil:* & tee:*"},
+		{name: "digits are words", text: "iphone 15", want: "iphone:* & 15:*"},
+		{name: "letters beyond ascii", text: "Café Straße", want: "café:* & straße:*"},
+		{name: "no words", text: " &|!:* ", want: ""},
+		{name: "empty", text: "", want: ""},
+		{
+			name: "at most ten words",
+			text: "a b c d e f g h i j k l",
+			want: "a:* & b:* & c:* & d:* & e:* & f:* & g:* & h:* & i:* & j:*",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := PrefixSearchQuery(tt.text); got != tt.want {
+				t.Errorf("PrefixSearchQuery(%q) is %q, want %q", tt.text, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,142 @@
+package models
+
+import (
+	"errors"
+	"strings"
+)
+
+type ShippingRateType string
+
+const (
+	// ShippingRateFlat charges Cost for every order
+	ShippingRateFlat ShippingRateType = "flat"
+	// ShippingRateWeightTiers charges the cost of the first tier the parcel
+	// weight fits in
+	ShippingRateWeightTiers ShippingRateType = "weight_tiers"
+	// ShippingRateFreeOver charges Cost unless the subtotal, after discounts,
+	// reaches FreeOver
+	ShippingRateFreeOver ShippingRateType = "free_over"
+)
+
+// volumetricDivisor turns a volume in cubic millimeters into a weight in
+// grams, bulky but light parcels are charged by their volume.
+const volumetricDivisor = 5000
+
+type WeightTier struct {
+	MaxGrams int   `json:"max_grams"`
+	Cost     int64 `json:"cost"`
+}
+
+// ShippingMethod prices parcels in a single currency, its costs and
+// thresholds are minor units of Currency.
+type ShippingMethod struct {
+	Code     string           `json:"code"`
+	Name     string           `json:"name"`
+	Type     ShippingRateType `json:"type"`
+	Currency string           `json:"currency"`
+	Cost     int64            `json:"cost"`
+	FreeOver int64            `json:"free_over"`
+	// Tiers must be sorted by MaxGrams, parcels heavier than the last tier
+	// can't be shipped with the method
+	Tiers []WeightTier `json:"tiers"`
+	// Countries limits the method to these ISO codes, empty means everywhere
+	Countries []string `json:"countries"`
+}
+
+func (m *ShippingMethod) Validate() error {
+	if m.Code == "" || m.Name == "" {
+		return errors.New("shipping method needs a code and a name")
+	}
+	if !ValidCurrency(m.Currency) {
+		return errors.New("shipping method " + m.Code + " needs a currency")
+	}
+	switch m.Type {
+	case ShippingRateFlat:
+	case ShippingRateFreeOver:
+		if m.FreeOver <= 0 {
+			return errors.New("shipping method " + m.Code + " needs a free_over threshold")
+		}
+	case ShippingRateWeightTiers:
+		if len(m.Tiers) == 0 {
+			return errors.New("shipping method " + m.Code + " needs weight tiers")
+		}
+		for idx := 1; idx < len(m.Tiers); idx++ {
+			if m.Tiers[idx].MaxGrams <= m.Tiers[idx-1].MaxGrams {
+				return errors.New("weight tiers of " + m.Code + " must be sorted by max_grams")
+			}
+		}
+	default:
+		return errors.New("shipping method " + m.Code + " has an unknown type")
+	}
+	return nil
+}
+
+func (m *ShippingMethod) shipsTo(country string) bool {
+	if len(m.Countries) == 0 {
+		return true
+	}
+	for _, c := range m.Countries {
+		if strings.EqualFold(c, country) {
+			return true
+		}
+	}
+	return false
+}
+
+// Quote prices the parcel shipped to country, ok is false when the method
+// can't ship it or doesn't price in the parcel's currency.
+func (m *ShippingMethod) Quote(parcel *Parcel, country string) (cost Money, ok bool) {
+	if !m.shipsTo(country) || !strings.EqualFold(m.Currency, parcel.Subtotal.Currency) {
+		return Money{}, false
+	}
+	switch m.Type {
+	case ShippingRateFlat:
+		return NewMoney(m.Cost, m.Currency), true
+	case ShippingRateFreeOver:
+		if parcel.Subtotal.Amount >= m.FreeOver {
+			return Zero(m.Currency), true
+		}
+		return NewMoney(m.Cost, m.Currency), true
+	case ShippingRateWeightTiers:
+		for _, tier := range m.Tiers {
+			if parcel.WeightGrams <= tier.MaxGrams {
+				return NewMoney(tier.Cost, m.Currency), true
+			}
+		}
+	}
+	return Money{}, false
+}
+
+// Parcel is what a shipping method prices: the goods' value after discounts
+// and their billable weight.
+type Parcel struct {
+	Subtotal    Money
+	WeightGrams int
+}
+
+func NewParcel(currency string) *Parcel {
+	return &Parcel{Subtotal: Zero(currency)}
+}
+
+// Add puts quantity units of the product into the parcel, value is what the
+// customer pays for them after discounts. It fails with ErrCurrencyMismatch
+// when value isn't in the parcel's currency.
+func (p *Parcel) Add(product *Product, quantity int, value Money) error {
+	subtotal, err := p.Subtotal.CheckedAdd(value)
+	if err != nil {
+		return err
+	}
+	weight := product.WeightGrams
+	if volumetric := product.LengthMm * product.WidthMm * product.HeightMm / volumetricDivisor; volumetric > weight {
+		weight = volumetric
+	}
+	p.WeightGrams += weight * quantity
+	p.Subtotal = subtotal
+	return nil
+}
+
+type ShippingQuote struct {
+	Method string `json:"method"`
+	Name   string `json:"name"`
+	Cost   Money  `json:"cost"`
+}
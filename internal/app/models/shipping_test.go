@@ -0,0 +1,133 @@
+package models
+
+import "testing"
+
+func TestShippingMethodQuote(t *testing.T) {
+	flat := ShippingMethod{Code: "flat", Name: "Flat", Type: ShippingRateFlat, Currency: "USD", Cost: 500}
+	freeOver := ShippingMethod{Code: "free", Name: "Free over", Type: ShippingRateFreeOver, Currency: "USD", Cost: 700, FreeOver: 5000}
+	tiers := ShippingMethod{
+		Code:     "tiers",
+		Name:     "By weight",
+		Type:     ShippingRateWeightTiers,
+		Currency: "usd",
+		Tiers:    []WeightTier{{MaxGrams: 1000, Cost: 400}, {MaxGrams: 5000, Cost: 900}},
+	}
+	domestic := flat
+	domestic.Countries = []string{"us", "CA"}
+
+	small := &Product{WeightGrams: 300}
+	// 400 x 300 x 100 mm bill as 2400g, more than they weigh
+	bulky := &Product{WeightGrams: 500, LengthMm: 400, WidthMm: 300, HeightMm: 100}
+	heavy := &Product{WeightGrams: 6000}
+
+	type parcelLine struct {
+		product  *Product
+		quantity int
+		value    int64
+	}
+	tests := []struct {
+		name     string
+		method   ShippingMethod
+		lines    []parcelLine
+		currency string
+		country  string
+		wantCost int64
+		wantOk   bool
+	}{
+		{
+			name:     "flat rate",
+			method:   flat,
+			lines:    []parcelLine{{small, 1, 1000}},
+			wantCost: 500,
+			wantOk:   true,
+		},
+		{
+			name:     "below the free threshold",
+			method:   freeOver,
+			lines:    []parcelLine{{small, 1, 4999}},
+			wantCost: 700,
+			wantOk:   true,
+		},
+		{
+			name:     "at the free threshold",
+			method:   freeOver,
+			lines:    []parcelLine{{small, 2, 2500}, {small, 1, 2500}},
+			wantCost: 0,
+			wantOk:   true,
+		},
+		{
+			name:     "discounts keep the parcel below the free threshold",
+			method:   freeOver,
+			lines:    []parcelLine{{small, 1, 6000 - 1500}},
+			wantCost: 700,
+			wantOk:   true,
+		},
+		{
+			name:     "first weight tier",
+			method:   tiers,
+			lines:    []parcelLine{{small, 3, 3000}},
+			wantCost: 400,
+			wantOk:   true,
+		},
+		{
+			name:     "volumetric weight picks the tier",
+			method:   tiers,
+			lines:    []parcelLine{{bulky, 1, 1000}},
+			wantCost: 900,
+			wantOk:   true,
+		},
+		{
+			name:   "heavier than the last tier",
+			method: tiers,
+			lines:  []parcelLine{{heavy, 1, 1000}},
+			wantOk: false,
+		},
+		{
+			name:     "ships to a listed country",
+			method:   domestic,
+			lines:    []parcelLine{{small, 1, 1000}},
+			country:  "CA",
+			wantCost: 500,
+			wantOk:   true,
+		},
+		{
+			name:    "doesn't ship to other countries",
+			method:  domestic,
+			lines:   []parcelLine{{small, 1, 1000}},
+			country: "DE",
+			wantOk:  false,
+		},
+		{
+			name:     "doesn't price other currencies",
+			method:   flat,
+			lines:    []parcelLine{{small, 1, 1000}},
+			currency: "EUR",
+			wantOk:   false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			currency := tt.currency
+			if currency == "" {
+				currency = "USD"
+			}
+			country := tt.country
+			if country == "" {
+				country = "US"
+			}
+			parcel := NewParcel(currency)
+			for _, line := range tt.lines {
+				if err := parcel.Add(line.product, line.quantity, NewMoney(line.value, currency)); err != nil {
+					t.Fatalf("Add: %v", err)
+				}
+			}
+			cost, ok := tt.method.Quote(parcel, country)
+			if ok != tt.wantOk {
+				t.Fatalf("Quote ok is %t, want %t", ok, tt.wantOk)
+			}
+			if ok && cost != NewMoney(tt.wantCost, "USD") {
+				t.Errorf("Quote is %s, want %d USD", cost, tt.wantCost)
+			}
+		})
+	}
+}
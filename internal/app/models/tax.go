@@ -0,0 +1,124 @@
+package models
+
+import (
+	"errors"
+	"strings"
+)
+
+const DefaultTaxClass = "standard"
+
+// TaxRule applies RateBps (hundredths of a percent) to products of TaxClass
+// shipped to Country, or to a single Region of it when Region is set.
+type TaxRule struct {
+	Name     string `json:"name"`
+	Country  string `json:"country"`
+	Region   string `json:"region"`
+	TaxClass string `json:"tax_class"`
+	RateBps  int64  `json:"rate_bps"`
+}
+
+type TaxConfig struct {
+	// PricesIncludeTax tells whether catalog prices already contain the tax
+	PricesIncludeTax bool      `json:"prices_include_tax"`
+	Rules            []TaxRule `json:"rules"`
+}
+
+func (c *TaxConfig) Validate() error {
+	for _, rule := range c.Rules {
+		if len(rule.Country) != 2 {
+			return errors.New("tax rule " + rule.Name + " needs a two letter country code")
+		}
+		if rule.TaxClass == "" {
+			return errors.New("tax rule " + rule.Name + " needs a tax class")
+		}
+		if rule.RateBps < 0 || rule.RateBps > 10000 {
+			return errors.New("tax rule " + rule.Name + " has a rate out of range")
+		}
+	}
+	return nil
+}
+
+// Rule finds the most specific rule for the destination and tax class, a
+// region rule wins over a country wide one.
+func (c *TaxConfig) Rule(destination TaxDestination, taxClass string) *TaxRule {
+	var match *TaxRule
+	for idx := range c.Rules {
+		rule := &c.Rules[idx]
+		if !strings.EqualFold(rule.Country, destination.Country) || rule.TaxClass != taxClass {
+			continue
+		}
+		if rule.Region == "" && match == nil {
+			match = rule
+		}
+		if rule.Region != "" && strings.EqualFold(rule.Region, destination.Region) {
+			return rule
+		}
+	}
+	return match
+}
+
+// Tax returns the tax contained in, or to add on top of, amount.
+func (c *TaxConfig) Tax(amount Money, rateBps int64) Money {
+	if c.PricesIncludeTax {
+		// amount * rate / (1 + rate), rounded half up
+		divisor := 10000 + rateBps
+		return NewMoney((amount.Amount*rateBps*2+divisor)/(2*divisor), amount.Currency)
+	}
+	return NewMoney((amount.Amount*rateBps+5000)/10000, amount.Currency)
+}
+
+type TaxDestination struct {
+	Country string
+	Region  string
+}
+
+type TaxLine struct {
+	Name     string `json:"name"`
+	TaxClass string `json:"tax_class"`
+	RateBps  int64  `json:"rate_bps"`
+	Taxable  Money  `json:"taxable"`
+	Amount   Money  `json:"amount"`
+}
+
+// TaxBreakdown sums the tax of an order or a cart up per applied rule.
+type TaxBreakdown struct {
+	PricesIncludeTax bool      `json:"prices_include_tax"`
+	Lines            []TaxLine `json:"lines"`
+	Total            Money     `json:"total"`
+}
+
+func NewTaxBreakdown(pricesIncludeTax bool, currency string) *TaxBreakdown {
+	return &TaxBreakdown{
+		PricesIncludeTax: pricesIncludeTax,
+		Lines:            make([]TaxLine, 0),
+		Total:            Zero(currency),
+	}
+}
+
+func (b *TaxBreakdown) Add(rule *TaxRule, taxable Money, amount Money) {
+	b.Total = b.Total.Add(amount)
+	for idx := range b.Lines {
+		line := &b.Lines[idx]
+		if line.Name == rule.Name && line.TaxClass == rule.TaxClass && line.RateBps == rule.RateBps {
+			line.Taxable = line.Taxable.Add(taxable)
+			line.Amount = line.Amount.Add(amount)
+			return
+		}
+	}
+	b.Lines = append(b.Lines, TaxLine{
+		Name:     rule.Name,
+		TaxClass: rule.TaxClass,
+		RateBps:  rule.RateBps,
+		Taxable:  taxable,
+		Amount:   amount,
+	})
+}
+
+// Payable is what is charged on top of the prices, nothing when they
+// already include the tax.
+func (b *TaxBreakdown) Payable() Money {
+	if b.PricesIncludeTax {
+		return Zero(b.Total.Currency)
+	}
+	return b.Total
+}
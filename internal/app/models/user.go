@@ -10,18 +10,86 @@ type User struct {
 	ID           uuid.UUID
 	Email        string
 	PasswordHash string
-	IsAdmin      bool
-	CreatedAt    time.Time
-	UpdatedAt    time.Time
+	// Roles decide what the user can do besides shopping
+	Roles []Role `gorm:"many2many:user_roles;"`
+	// EmailVerifiedAt is nil until the user confirms they own the email
+	EmailVerifiedAt *time.Time
+	CreatedAt       time.Time
+	UpdatedAt       time.Time
+}
+
+func (u *User) IsEmailVerified() bool {
+	return u.EmailVerifiedAt != nil
+}
+
+// Permissions returns what the roles of the user allow them to do.
+func (u *User) Permissions() []string {
+	return PermissionNames(u.Roles)
+}
+
+func (u *User) RoleNames() []string {
+	names := make([]string, len(u.Roles))
+	for idx, role := range u.Roles {
+		names[idx] = role.Name
+	}
+	return names
+}
+
+// RefreshToken is the server side record of an opaque refresh token, only
+// its hash is stored. Every refresh swaps the token for a new one of the same
+// family, so a family is the chain of tokens of a single login.
+type RefreshToken struct {
+	ID        uuid.UUID
+	UserId    uuid.UUID
+	FamilyId  uuid.UUID
+	TokenHash string
+	ExpiresAt time.Time
+	// RevokedAt is set once the token is rotated or its family is revoked
+	RevokedAt *time.Time
+	// ReplacedBy is the token this one was rotated into
+	ReplacedBy *uuid.UUID
+	CreatedAt  time.Time
+}
+
+// PasswordResetToken lets whoever holds it choose a new password for the
+// user, once and only until it expires. Only its hash is stored.
+type PasswordResetToken struct {
+	ID        uuid.UUID
+	UserId    uuid.UUID
+	TokenHash string
+	ExpiresAt time.Time
+	UsedAt    *time.Time
+	CreatedAt time.Time
+}
+
+// EmailVerificationToken is mailed to the user to confirm they own their
+// email. Only its hash is stored.
+type EmailVerificationToken struct {
+	ID        uuid.UUID
+	UserId    uuid.UUID
+	TokenHash string
+	ExpiresAt time.Time
+	CreatedAt time.Time
+}
+
+// TokenPair is what a client gets on login and on every refresh.
+type TokenPair struct {
+	AccessToken  string
+	RefreshToken string
+	// ExpiresIn is how long the access token is valid for
+	ExpiresIn time.Duration
 }
 
 type UserSvc interface {
 	RegisterUser(*RegisterUser) (*User, error)
-	Authenticate(*Login) (string, error)
+	Authenticate(*Login) (*TokenPair, error)
+	RefreshSession(string) (*TokenPair, error)
+	Logout(string) error
 	GetUser(uuid.UUID) (*User, error)
 }
 
 type UserRepo interface {
+	// Get loads the user along with their roles and permissions
 	Get(string) (*User, error)
 	GetByEmail(string) (*User, error)
 	Create(*User) error
@@ -0,0 +1,134 @@
+package models
+
+import (
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ProductOption is a dimension the variants of a product differ in, like
+// size or color, along with the values it can take.
+type ProductOption struct {
+	ID        uuid.UUID
+	ProductId uuid.UUID
+	Name      string
+	Values    []string `gorm:"serializer:json"`
+	Position  int
+}
+
+// ProductVariant is a sellable version of a product, e.g. the red shirt in
+// size M. Products with variants are only sold through them, each keeping
+// its own stock.
+type ProductVariant struct {
+	ID        uuid.UUID
+	ProductId uuid.UUID
+	Sku       string
+	// Options holds a value for every option of the product
+	Options map[string]string `gorm:"serializer:json"`
+	// OptionsKey is the canonical form of Options, no two variants of a
+	// product share it
+	OptionsKey string
+	// Price overrides the product's price in its own currency, a zero price
+	// means the variant sells at the product's price
+	Price         Money `gorm:"embedded;embeddedPrefix:price_"`
+	StockQuantity int
+	CreatedAt     time.Time
+	UpdatedAt     time.Time
+}
+
+// PriceIn returns the variant's price in currency, ok is false when the
+// product isn't sold in it.
+func (v *ProductVariant) PriceIn(product *Product, currency string) (price Money, ok bool) {
+	if !v.Price.IsZero() && v.Price.Currency == currency {
+		return v.Price, true
+	}
+	return product.PriceIn(currency)
+}
+
+// Title names the variant by its option values, in the order of the
+// product's options.
+func (v *ProductVariant) Title(product *Product) string {
+	values := make([]string, 0, len(v.Options))
+	for _, option := range product.Options {
+		if value, ok := v.Options[option.Name]; ok {
+			values = append(values, value)
+		}
+	}
+	return strings.Join(values, " / ")
+}
+
+// VariantOptionsKey turns the option values of a variant into a string that
+// doesn't depend on the order of the map.
+func VariantOptionsKey(options map[string]string) string {
+	names := make([]string, 0, len(options))
+	for name := range options {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	pairs := make([]string, len(names))
+	for idx, name := range names {
+		pairs[idx] = name + "=" + options[name]
+	}
+	return strings.Join(pairs, ";")
+}
+
+// PriceOf returns the price of the product, or of its variant when it is
+// sold through one, in currency.
+func (p *Product) PriceOf(variant *ProductVariant, currency string) (Money, bool) {
+	if variant != nil {
+		return variant.PriceIn(p, currency)
+	}
+	return p.PriceIn(currency)
+}
+
+// HasVariants tells whether the product can only be sold through one of its
+// variants.
+func (p *Product) HasVariants() bool {
+	return len(p.Variants) > 0
+}
+
+func (p *Product) FindVariant(id uuid.UUID) *ProductVariant {
+	for idx := range p.Variants {
+		if p.Variants[idx].ID == id {
+			return &p.Variants[idx]
+		}
+	}
+	return nil
+}
+
+// StockOf is how many units of the product, or of the variant when it is
+// sold through one, are in stock.
+func (p *Product) StockOf(variant *ProductVariant) int {
+	if variant != nil {
+		return variant.StockQuantity
+	}
+	return p.StockQuantity
+}
+
+// MatchesOptions tells whether options holds exactly one allowed value for
+// every option of the product.
+func (p *Product) MatchesOptions(options map[string]string) bool {
+	if len(options) != len(p.Options) {
+		return false
+	}
+	for _, option := range p.Options {
+		value, ok := options[option.Name]
+		if !ok {
+			return false
+		}
+		allowed := false
+		for _, candidate := range option.Values {
+			if candidate == value {
+				allowed = true
+				break
+			}
+		}
+		if !allowed {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,116 @@
+package services
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/rezbow/ecommerce/internal/app/models"
+	"github.com/rezbow/ecommerce/internal/platform/database"
+)
+
+var (
+	ErrAddressNotFound = errors.New("address not found")
+)
+
+type IAddressService interface {
+	CreateAddress(uuid.UUID, *models.AddressCreate) (*models.Address, error)
+	ListAddresses(uuid.UUID) ([]models.Address, error)
+	GetAddress(uuid.UUID, uuid.UUID) (*models.Address, error)
+	GetDefaultAddress(uuid.UUID) (*models.Address, error)
+	UpdateAddress(uuid.UUID, uuid.UUID, *models.AddressUpdateRequest) (*models.Address, error)
+	DeleteAddress(uuid.UUID, uuid.UUID) error
+}
+
+type AddressService struct {
+	addressRepo database.IAddressRepo
+}
+
+func NewAddressService(addressRepo database.IAddressRepo) *AddressService {
+	return &AddressService{
+		addressRepo: addressRepo,
+	}
+}
+
+func (svc *AddressService) CreateAddress(userId uuid.UUID, addressCreate *models.AddressCreate) (*models.Address, error) {
+	address := &models.Address{
+		UserId:     userId,
+		FullName:   addressCreate.FullName,
+		Line1:      addressCreate.Line1,
+		Line2:      addressCreate.Line2,
+		City:       addressCreate.City,
+		Region:     addressCreate.Region,
+		PostalCode: addressCreate.PostalCode,
+		Country:    strings.ToUpper(addressCreate.Country),
+		Phone:      addressCreate.Phone,
+		IsDefault:  addressCreate.IsDefault,
+	}
+
+	// the first address a user saves is their default
+	if !address.IsDefault {
+		_, err := svc.addressRepo.GetUserDefault(userId)
+		if errors.Is(err, database.ErrRecordNotFound) {
+			address.IsDefault = true
+		} else if err != nil {
+			return nil, ErrInternal
+		}
+	}
+
+	if err := svc.addressRepo.Create(address); err != nil {
+		return nil, ErrInternal
+	}
+	return address, nil
+}
+
+func (svc *AddressService) ListAddresses(userId uuid.UUID) ([]models.Address, error) {
+	addresses, err := svc.addressRepo.GetUserAddresses(userId)
+	if err != nil {
+		return nil, ErrInternal
+	}
+	return addresses, nil
+}
+
+func (svc *AddressService) GetAddress(userId uuid.UUID, addressId uuid.UUID) (*models.Address, error) {
+	address, err := svc.addressRepo.GetUserAddress(addressId, userId)
+	if err != nil {
+		if errors.Is(err, database.ErrRecordNotFound) {
+			return nil, ErrAddressNotFound
+		}
+		return nil, ErrInternal
+	}
+	return address, nil
+}
+
+func (svc *AddressService) GetDefaultAddress(userId uuid.UUID) (*models.Address, error) {
+	address, err := svc.addressRepo.GetUserDefault(userId)
+	if err != nil {
+		if errors.Is(err, database.ErrRecordNotFound) {
+			return nil, ErrAddressNotFound
+		}
+		return nil, ErrInternal
+	}
+	return address, nil
+}
+
+func (svc *AddressService) UpdateAddress(userId uuid.UUID, addressId uuid.UUID, addressUpdate *models.AddressUpdateRequest) (*models.Address, error) {
+	address, err := svc.addressRepo.Update(addressId, userId, addressUpdate.ToMap())
+	if err != nil {
+		if errors.Is(err, database.ErrRecordNotFound) {
+			return nil, ErrAddressNotFound
+		}
+		return nil, ErrInternal
+	}
+	return address, nil
+}
+
+// DeleteAddress removes a saved address. Orders keep their own copy of the
+// address, so they are not affected.
+func (svc *AddressService) DeleteAddress(userId uuid.UUID, addressId uuid.UUID) error {
+	if err := svc.addressRepo.Delete(addressId, userId); err != nil {
+		if errors.Is(err, database.ErrRecordNotFound) {
+			return ErrAddressNotFound
+		}
+		return ErrInternal
+	}
+	return nil
+}
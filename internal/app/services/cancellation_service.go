@@ -0,0 +1,111 @@
+package services
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rezbow/ecommerce/internal/app/models"
+	"github.com/rezbow/ecommerce/internal/platform/database"
+)
+
+var (
+	ErrOrderNotCancellable = errors.New("order can no longer be cancelled")
+)
+
+// expiredHoldsBatchSize caps how many expired orders a single sweep releases
+const expiredHoldsBatchSize = 100
+
+type ICancellationService interface {
+	CancelUserOrder(uuid.UUID, uuid.UUID) (*models.Order, error)
+	CancelOrder(uuid.UUID, uuid.UUID) (*models.Order, error)
+	ReleaseExpiredHolds() (int, error)
+}
+
+type CancellationService struct {
+	orderSvc        IOrderService
+	paymentSvc      IPaymentService
+	reservationRepo database.IReservationRepo
+}
+
+func NewCancellationService(orderSvc IOrderService, paymentSvc IPaymentService, reservationRepo database.IReservationRepo) *CancellationService {
+	return &CancellationService{
+		orderSvc:        orderSvc,
+		paymentSvc:      paymentSvc,
+		reservationRepo: reservationRepo,
+	}
+}
+
+// CancelUserOrder lets a user cancel their own order as long as it wasn't
+// fulfilled yet. Cancelling gives the stock back in the same transaction as
+// the status change, any payment is voided or refunded afterwards.
+func (svc *CancellationService) CancelUserOrder(userId uuid.UUID, orderId uuid.UUID) (*models.Order, error) {
+	order, err := svc.orderSvc.GetUserOrder(userId, orderId)
+	if err != nil {
+		return nil, err
+	}
+	return svc.cancel(order, &userId)
+}
+
+// CancelOrder is how admins cancel any order that wasn't fulfilled yet, the
+// payment is released just like for cancellations by the user.
+func (svc *CancellationService) CancelOrder(orderId uuid.UUID, actorId uuid.UUID) (*models.Order, error) {
+	order, err := svc.orderSvc.GetOrder(orderId)
+	if err != nil {
+		return nil, err
+	}
+	return svc.cancel(order, &actorId)
+}
+
+// ReleaseExpiredHolds cancels the pending orders whose stock holds have
+// expired, which gives their stock back, and releases their payments like
+// any other cancellation so a capture reported later is refunded. It returns
+// how many orders were cancelled.
+func (svc *CancellationService) ReleaseExpiredHolds() (int, error) {
+	orderIds, err := svc.reservationRepo.GetExpiredOrderIds(time.Now(), expiredHoldsBatchSize)
+	if err != nil {
+		return 0, ErrInternal
+	}
+	released := 0
+	for _, orderId := range orderIds {
+		order, err := svc.orderSvc.GetOrder(orderId)
+		if err != nil {
+			return released, err
+		}
+		// the order was paid or cancelled in the meantime
+		if order.Status != models.OrderStatusPending {
+			continue
+		}
+		if _, err := svc.cancel(order, nil); err != nil {
+			if errors.Is(err, ErrOrderNotCancellable) || errors.Is(err, ErrOrderModified) {
+				continue
+			}
+			return released, err
+		}
+		released++
+	}
+	return released, nil
+}
+
+// cancel moves the order to cancelled and releases its payments, actorId is
+// nil when the system cancels the order by itself.
+func (svc *CancellationService) cancel(order *models.Order, actorId *uuid.UUID) (*models.Order, error) {
+	if order.Status != models.OrderStatusPending && order.Status != models.OrderStatusPaid {
+		return nil, ErrOrderNotCancellable
+	}
+
+	cancelledOrder, err := svc.orderSvc.TransitionStatus(order.ID, models.OrderStatusCancelled, actorId)
+	if err != nil {
+		if errors.Is(err, ErrInvalidStatusTransition) {
+			return nil, ErrOrderNotCancellable
+		}
+		return nil, err
+	}
+
+	// the order is cancelled at this point, a failure here leaves the
+	// payment to be settled by an admin
+	if err := svc.paymentSvc.ReleasePayments(order.ID); err != nil {
+		return nil, err
+	}
+	return cancelledOrder, nil
+}
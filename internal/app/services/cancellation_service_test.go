@@ -0,0 +1,47 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rezbow/ecommerce/internal/app/models"
+	"github.com/rezbow/ecommerce/internal/platform/payment"
+)
+
+type fakeReservationRepo struct {
+	expired []uuid.UUID
+}
+
+func (repo *fakeReservationRepo) GetExpiredOrderIds(time.Time, int) ([]uuid.UUID, error) {
+	return repo.expired, nil
+}
+
+func TestReleaseExpiredHoldsReleasesPayments(t *testing.T) {
+	test := newPaymentTest(models.NewMoney(2500, "USD"))
+	if _, err := test.svc.Pay(test.order, payment.FakeTokenTimeout); err != nil {
+		t.Fatalf("Pay: %v", err)
+	}
+	paid := newPaymentTest(models.NewMoney(1000, "USD"))
+	test.orderSvc.orders[paid.order.ID] = paid.order
+	paid.order.Status = models.OrderStatusPaid
+
+	reservationRepo := &fakeReservationRepo{expired: []uuid.UUID{test.order.ID, paid.order.ID}}
+	svc := NewCancellationService(test.orderSvc, test.svc, reservationRepo)
+	released, err := svc.ReleaseExpiredHolds()
+	if err != nil {
+		t.Fatalf("ReleaseExpiredHolds: %v", err)
+	}
+	if released != 1 {
+		t.Errorf("released %d orders, want 1", released)
+	}
+	if status := test.orderStatus(); status != models.OrderStatusCancelled {
+		t.Errorf("expired order status is %s, want %s", status, models.OrderStatusCancelled)
+	}
+	if status := test.paymentStatus(t); status != models.PaymentStatusCancelled {
+		t.Errorf("payment status is %s, want %s", status, models.PaymentStatusCancelled)
+	}
+	if status := test.orderSvc.orders[paid.order.ID].Status; status != models.OrderStatusPaid {
+		t.Errorf("paid order status is %s, want %s", status, models.OrderStatusPaid)
+	}
+}
@@ -2,6 +2,9 @@ package services
 
 import (
 	"errors"
+	"log"
+	"maps"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
@@ -15,55 +18,293 @@ var (
 	ErrCartNotFound         = errors.New("cart not found")
 	ErrInsufficientQuantity = errors.New("not enoguth product qunatity in stock")
 	ErrItemNotFound         = errors.New("item not found in cart")
+	ErrCartCurrencyMismatch = errors.New("cart already holds items in another currency")
+	ErrPriceUnavailable     = errors.New("product is not sold in the cart's currency")
+	ErrCartChanged          = errors.New("cart changed, products sold out, are no longer available or changed price, review it before checking out")
+	ErrVariantRequired      = errors.New("product is sold through variants, a variant_id is required")
+	ErrVariantNotFound      = errors.New("variant not found")
 )
 
 type ICartService interface {
 	GetUserCart(uuid.UUID) (*models.Cart, error)
+	GetCheckoutCart(uuid.UUID) (*models.Cart, error)
 	AddToUserCart(uuid.UUID, *models.ItemCartRequest) error
 	RemoveItemFromCart(uuid.UUID, uuid.UUID) error
 	ClearCart(uuid.UUID) error
 	UpdateItemQuantity(uuid.UUID, uuid.UUID, *models.ItemQuantityUpdate) error
+	ApplyCoupon(uuid.UUID, *models.CouponApplyRequest) (*models.Cart, error)
+	RemoveCoupon(uuid.UUID) error
 }
 
 type CartService struct {
-	cartRepo    database.ICartRepo
-	productRepo database.IProductRepo
+	cartRepo      database.ICartRepo
+	productRepo   database.IProductRepo
+	couponRepo    database.ICouponRepo
+	promotionRepo database.IPromotionRepo
+	categoryRepo  database.ICategoryRepo
+	taxSvc        ITaxService
+	addressSvc    IAddressService
+	// defaultCurrency is used for new carts that don't ask for a currency
+	defaultCurrency string
 }
 
-func NewCartService(cartRepo database.ICartRepo, productRepo database.IProductRepo) *CartService {
+func NewCartService(
+	cartRepo database.ICartRepo,
+	productRepo database.IProductRepo,
+	couponRepo database.ICouponRepo,
+	promotionRepo database.IPromotionRepo,
+	categoryRepo database.ICategoryRepo,
+	taxSvc ITaxService,
+	addressSvc IAddressService,
+	defaultCurrency string,
+) *CartService {
 	return &CartService{
-		cartRepo:    cartRepo,
-		productRepo: productRepo,
+		cartRepo:        cartRepo,
+		productRepo:     productRepo,
+		couponRepo:      couponRepo,
+		promotionRepo:   promotionRepo,
+		categoryRepo:    categoryRepo,
+		taxSvc:          taxSvc,
+		addressSvc:      addressSvc,
+		defaultCurrency: defaultCurrency,
 	}
 }
 
+// cartKey is where the user's cart is cached. The prefix keeps carts saved
+// before prices carried a currency from being read back as they are, see
+// loadCart.
+func cartKey(userId uuid.UUID) string {
+	return "cart:" + userId.String()
+}
+
+// legacyCartKey is where carts were cached before prices carried a currency.
+func legacyCartKey(userId uuid.UUID) string {
+	return userId.String()
+}
+
+// loadCart reads the user's cart. A cart still saved under the legacy key is
+// moved to cartKey in the default currency, synced with the live prices
+// first since it had none.
+func (svc *CartService) loadCart(userId uuid.UUID) (*models.Cart, error) {
+	cart, err := svc.cartRepo.Get(cartKey(userId))
+	if !errors.Is(err, database.ErrRecordNotFound) {
+		return cart, err
+	}
+	cart, err = svc.cartRepo.GetLegacy(legacyCartKey(userId), svc.defaultCurrency)
+	if err != nil {
+		return nil, err
+	}
+	cart.UserId = userId
+	if err := svc.SyncCart(cart); err != nil {
+		return nil, err
+	}
+	if err := svc.cartRepo.Save(cartKey(userId), cart, cacheDuration); err != nil {
+		return nil, ErrInternal
+	}
+	if err := svc.cartRepo.Delete(legacyCartKey(userId)); err != nil && !errors.Is(err, database.ErrRecordNotFound) {
+		log.Printf("deleting legacy cart of user %s: %v", userId, err)
+	}
+	return cart, nil
+}
+
 func (svc *CartService) GetUserCart(userId uuid.UUID) (*models.Cart, error) {
-	cart, err := svc.cartRepo.Get(userId.String())
+	cart, _, err := svc.priceUserCart(userId)
+	return cart, err
+}
+
+// GetCheckoutCart prices the user's cart like GetUserCart, but fails with
+// ErrCartChanged when syncing it dropped lines, lowered their quantities or
+// changed their prices.
+// The synced cart is still saved, so the user sees what changed.
+func (svc *CartService) GetCheckoutCart(userId uuid.UUID) (*models.Cart, error) {
+	cart, changed, err := svc.priceUserCart(userId)
+	if err != nil {
+		return nil, err
+	}
+	if changed {
+		return nil, ErrCartChanged
+	}
+	return cart, nil
+}
+
+// priceUserCart syncs, discounts and taxes the user's cart and saves it,
+// changed tells whether syncing altered the quantity or price of its lines.
+func (svc *CartService) priceUserCart(userId uuid.UUID) (cart *models.Cart, changed bool, err error) {
+	cart, err = svc.loadCart(userId)
 	if err != nil {
 		if errors.Is(err, database.ErrRecordNotFound) {
-			return nil, ErrCartNotFound
+			return nil, false, ErrCartNotFound
 		}
-		return nil, ErrInternal
+		return nil, false, ErrInternal
 	}
 	// sync the entire cart with database
-	svc.SyncCart(cart)
+	stored := cart.Lines()
+	if err := svc.SyncCart(cart); err != nil {
+		return nil, false, err
+	}
+	changed = !maps.Equal(stored, cart.Lines())
+	if err := svc.applyCoupon(userId, cart); err != nil {
+		return nil, false, err
+	}
+	if err := svc.taxCart(userId, cart); err != nil {
+		return nil, false, err
+	}
 	// save it to cache
-	if err := svc.cartRepo.Save(userId.String(), cart, cacheDuration); err != nil {
+	if err := svc.cartRepo.Save(cartKey(userId), cart, cacheDuration); err != nil {
+		return nil, false, ErrInternal
+	}
+	return cart, changed, nil
+}
+
+// ApplyCoupon puts the coupon code on the user's cart, replacing any other
+// code, and returns the cart priced with it.
+func (svc *CartService) ApplyCoupon(userId uuid.UUID, couponApplyRequest *models.CouponApplyRequest) (*models.Cart, error) {
+	cart, err := svc.loadCart(userId)
+	if err != nil {
+		if errors.Is(err, database.ErrRecordNotFound) {
+			return nil, ErrCartNotFound
+		}
+		return nil, ErrInternal
+	}
+
+	if err := svc.SyncCart(cart); err != nil {
+		return nil, err
+	}
+	coupon, err := svc.checkCoupon(userId, cart, normalizeCouponCode(couponApplyRequest.Code))
+	if err != nil {
+		return nil, err
+	}
+	cart.Coupon = &models.CartCoupon{ID: coupon.ID, Code: coupon.Code}
+	discountWithCoupon(cart, coupon)
+	if err := svc.taxCart(userId, cart); err != nil {
+		return nil, err
+	}
+
+	if err := svc.cartRepo.Save(cartKey(userId), cart, cacheDuration); err != nil {
 		return nil, ErrInternal
 	}
 	return cart, nil
 }
 
+func (svc *CartService) RemoveCoupon(userId uuid.UUID) error {
+	cart, err := svc.loadCart(userId)
+	if errors.Is(err, database.ErrRecordNotFound) {
+		return ErrCartNotFound
+	}
+	if err != nil {
+		return ErrInternal
+	}
+	if cart.Coupon == nil {
+		return ErrCouponNotFound
+	}
+
+	cart.Coupon = nil
+	cart.SetItems(cart.Items)
+	if err := svc.cartRepo.Save(cartKey(userId), cart, cacheDuration); err != nil {
+		return ErrInternal
+	}
+	return nil
+}
+
+// applyCoupon discounts the cart with the coupon the user applied to it. A
+// coupon that stopped applying, e.g. because it expired or the cart dropped
+// below its minimum, is taken off the cart.
+func (svc *CartService) applyCoupon(userId uuid.UUID, cart *models.Cart) error {
+	if cart.Coupon == nil {
+		return nil
+	}
+	coupon, err := svc.checkCoupon(userId, cart, cart.Coupon.Code)
+	if err != nil {
+		if errors.Is(err, ErrInternal) {
+			return err
+		}
+		cart.Coupon = nil
+		return nil
+	}
+	discountWithCoupon(cart, coupon)
+	return nil
+}
+
+// checkCoupon finds the coupon by its code and makes sure the user can use
+// it on the cart right now. Redeeming it at checkout checks the usage limits
+// again, atomically.
+func (svc *CartService) checkCoupon(userId uuid.UUID, cart *models.Cart, code string) (*models.Coupon, error) {
+	coupon, err := svc.couponRepo.GetByCode(code)
+	if err != nil {
+		if errors.Is(err, database.ErrRecordNotFound) {
+			return nil, ErrCouponNotFound
+		}
+		return nil, ErrInternal
+	}
+	if !coupon.ActiveAt(time.Now()) {
+		return nil, ErrCouponNotActive
+	}
+	if !coupon.AppliesTo(cart.Currency) {
+		return nil, ErrCouponCurrency
+	}
+	if !coupon.MinTotal.IsZero() {
+		below, err := cart.DiscountedTotal.CheckedLessThan(coupon.MinTotal)
+		if err != nil {
+			return nil, ErrCouponCurrency
+		}
+		if below {
+			return nil, ErrCouponMinimumTotal
+		}
+	}
+	if coupon.MaxRedemptions != nil && coupon.Redemptions >= *coupon.MaxRedemptions {
+		return nil, ErrCouponLimitReached
+	}
+	if coupon.MaxRedemptionsPerUser != nil {
+		used, err := svc.couponRepo.CountUserRedemptions(coupon.ID, userId)
+		if err != nil {
+			return nil, ErrInternal
+		}
+		if used >= int64(*coupon.MaxRedemptionsPerUser) {
+			return nil, ErrCouponLimitReached
+		}
+	}
+	return coupon, nil
+}
+
+func discountWithCoupon(cart *models.Cart, coupon *models.Coupon) {
+	if coupon.Type == models.CouponTypeFreeShipping {
+		cart.WaiveShipping(models.DiscountSourceCoupon, coupon.Code)
+		return
+	}
+	cart.ApplyDiscount(models.DiscountSourceCoupon, coupon.Code, coupon.Discount(cart.DiscountedTotal))
+}
+
+// taxCart prices the tax of the cart for the user's default address, carts
+// of users without one are not taxed yet.
+func (svc *CartService) taxCart(userId uuid.UUID, cart *models.Cart) error {
+	address, err := svc.addressSvc.GetDefaultAddress(userId)
+	if err != nil {
+		if errors.Is(err, ErrAddressNotFound) {
+			return nil
+		}
+		return ErrInternal
+	}
+	svc.taxSvc.TaxCart(cart, address.TaxDestination())
+	return nil
+}
+
 func (svc *CartService) AddToUserCart(userId uuid.UUID, itemCartRequest *models.ItemCartRequest) error {
 	// get users cart
-	userCart, err := svc.cartRepo.Get(userId.String())
+	userCart, err := svc.loadCart(userId)
 	if err != nil {
 		if errors.Is(err, database.ErrRecordNotFound) {
-			userCart = models.NewCart(userId)
+			userCart = models.NewCart(userId, svc.defaultCurrency)
 		} else {
 			return ErrInternal
 		}
 	}
+	// a cart holds a single currency, it can only change while empty
+	if currency := strings.ToUpper(itemCartRequest.Currency); currency != "" && currency != userCart.Currency {
+		if len(userCart.Items) > 0 {
+			return ErrCartCurrencyMismatch
+		}
+		userCart.SetCurrency(currency)
+	}
 
 	// build an item
 	// call db for live info
@@ -74,23 +315,39 @@ func (svc *CartService) AddToUserCart(userId uuid.UUID, itemCartRequest *models.
 		}
 		return ErrInternal
 	}
+	variant, err := variantOf(product, itemCartRequest.VariantId)
+	if err != nil {
+		return err
+	}
 
-	if itemCartRequest.Quantity+userCart.ItemQuantity(product.ID) > product.StockQuantity {
+	price, ok := product.PriceOf(variant, userCart.Currency)
+	if !ok {
+		return ErrPriceUnavailable
+	}
+
+	// stock is checked on the variant, products with variants keep none
+	lineId := product.ID
+	if variant != nil {
+		lineId = variant.ID
+	}
+	if itemCartRequest.Quantity+userCart.ItemQuantity(lineId) > product.StockOf(variant) {
 		return ErrInsufficientQuantity
 	}
 
 	// add to item quantity or insert if item with product.ID
 	// doesn't exists in our cart
-	userCart.AddQuantityOrInsert(product, itemCartRequest.Quantity)
+	userCart.AddQuantityOrInsert(product, variant, price, itemCartRequest.Quantity)
 
-	if err := svc.cartRepo.Save(userId.String(), userCart, cacheDuration); err != nil {
+	if err := svc.cartRepo.Save(cartKey(userId), userCart, cacheDuration); err != nil {
 		return ErrInternal
 	}
 	return nil
 }
 
-func (svc *CartService) RemoveItemFromCart(userId uuid.UUID, productId uuid.UUID) error {
-	userCart, err := svc.cartRepo.Get(userId.String())
+// RemoveItemFromCart takes the line with lineId off the cart, see
+// models.CartItem.LineId.
+func (svc *CartService) RemoveItemFromCart(userId uuid.UUID, lineId uuid.UUID) error {
+	userCart, err := svc.loadCart(userId)
 	if errors.Is(err, database.ErrRecordNotFound) {
 		return ErrCartNotFound
 	}
@@ -98,18 +355,21 @@ func (svc *CartService) RemoveItemFromCart(userId uuid.UUID, productId uuid.UUID
 		return ErrInternal
 	}
 
-	if !userCart.Remove(productId) {
+	if !userCart.Remove(lineId) {
 		return ErrItemNotFound
 	}
 
-	if err := svc.cartRepo.Save(userId.String(), userCart, cacheDuration); err != nil {
+	if err := svc.cartRepo.Save(cartKey(userId), userCart, cacheDuration); err != nil {
 		return ErrInternal
 	}
 	return nil
 }
 
 func (svc *CartService) ClearCart(userId uuid.UUID) error {
-	err := svc.cartRepo.Delete(userId.String())
+	err := svc.cartRepo.Delete(cartKey(userId))
+	if errors.Is(err, database.ErrRecordNotFound) {
+		err = svc.cartRepo.Delete(legacyCartKey(userId))
+	}
 	if errors.Is(err, database.ErrRecordNotFound) {
 		return ErrCartNotFound
 	}
@@ -119,35 +379,87 @@ func (svc *CartService) ClearCart(userId uuid.UUID) error {
 	return nil
 }
 
-func (svc *CartService) SyncCart(cart *models.Cart) {
+// SyncCart refreshes the cart lines with live product data and discounts
+// them with the promotions running right now.
+func (svc *CartService) SyncCart(cart *models.Cart) error {
 	refreshedItems := make([]*models.CartItem, 0)
 	for _, item := range cart.Items {
 		product, err := svc.productRepo.Get(item.ProductId)
 		if err != nil {
 			continue
 		}
-		if product.StockQuantity == 0 {
+		// the variant is gone or the product started being sold through
+		// variants after the line was added
+		variant, err := variantOf(product, item.VariantId)
+		if err != nil {
+			continue
+		}
+		stock := product.StockOf(variant)
+		if stock == 0 {
+			continue
+		}
+		// the product stopped being sold in the cart's currency
+		price, ok := product.PriceOf(variant, cart.Currency)
+		if !ok {
 			continue
 		}
 
-		newItem := models.NewCartItem(item.ProductId)
-		if product.StockQuantity < item.Quantity {
-			newItem.Quantity = product.StockQuantity
+		newItem := models.NewCartItem(item.ProductId, item.VariantId)
+		if stock < item.Quantity {
+			newItem.Quantity = stock
 		} else {
 			newItem.Quantity = item.Quantity
 		}
-		newItem.Price = product.Price
-		newItem.Name = product.Name
-		newItem.SubTotal = int64(newItem.Quantity) * newItem.Price
+		newItem.Price = price
+		newItem.Describe(product, variant)
+		newItem.SubTotal = newItem.Price.Mul(int64(newItem.Quantity))
 
 		refreshedItems = append(refreshedItems, newItem)
 
 	}
 	cart.SetItems(refreshedItems)
+
+	now := time.Now()
+	promotions, err := svc.promotionRepo.GetActive(now)
+	if err != nil {
+		return ErrInternal
+	}
+	if err := svc.expandItemCategories(cart, promotions); err != nil {
+		return err
+	}
+	models.ApplyPromotions(cart, promotions, now)
+	return nil
 }
 
-func (svc *CartService) UpdateItemQuantity(userId uuid.UUID, productId uuid.UUID, itemQuantityUpdate *models.ItemQuantityUpdate) error {
-	userCart, err := svc.cartRepo.Get(userId.String())
+// expandItemCategories adds the ancestors of every line's categories to it,
+// so promotions on a category also discount products of its subcategories.
+// The category tree is only loaded when a promotion targets categories.
+func (svc *CartService) expandItemCategories(cart *models.Cart, promotions []models.Promotion) error {
+	needed := false
+	for _, promotion := range promotions {
+		if len(promotion.CategoryIds) > 0 {
+			needed = true
+			break
+		}
+	}
+	if !needed {
+		return nil
+	}
+
+	categories, err := svc.categoryRepo.GetAll()
+	if err != nil {
+		return ErrInternal
+	}
+	for _, item := range cart.Items {
+		item.CategoryIds = models.WithAncestors(categories, item.CategoryIds)
+	}
+	return nil
+}
+
+// UpdateItemQuantity sets the quantity of the line with lineId, see
+// models.CartItem.LineId.
+func (svc *CartService) UpdateItemQuantity(userId uuid.UUID, lineId uuid.UUID, itemQuantityUpdate *models.ItemQuantityUpdate) error {
+	userCart, err := svc.loadCart(userId)
 	if errors.Is(err, database.ErrRecordNotFound) {
 		return ErrCartNotFound
 	} else if err != nil {
@@ -157,7 +469,7 @@ func (svc *CartService) UpdateItemQuantity(userId uuid.UUID, productId uuid.UUID
 	updatedItems := make([]*models.CartItem, 0)
 	found := false
 	for _, item := range userCart.Items {
-		if item.ProductId != productId {
+		if item.LineId() != lineId {
 			updatedItems = append(updatedItems, item)
 			continue
 		}
@@ -165,27 +477,33 @@ func (svc *CartService) UpdateItemQuantity(userId uuid.UUID, productId uuid.UUID
 		found = true
 
 		if itemQuantityUpdate.NewQuantity == 0 {
-			break
+			continue
 		}
-		product, err := svc.productRepo.Get(productId)
+		product, err := svc.productRepo.Get(item.ProductId)
 		if errors.Is(err, database.ErrRecordNotFound) {
 			return ErrProductNotFound
 		} else if err != nil {
 			return ErrInternal
 		}
+		variant, err := variantOf(product, item.VariantId)
+		if err != nil {
+			return err
+		}
 
-		if itemQuantityUpdate.NewQuantity > product.StockQuantity {
+		if itemQuantityUpdate.NewQuantity > product.StockOf(variant) {
 			return ErrInsufficientQuantity
 		}
+		price, ok := product.PriceOf(variant, userCart.Currency)
+		if !ok {
+			return ErrPriceUnavailable
+		}
 
 		item.Quantity = itemQuantityUpdate.NewQuantity
-		item.Name = product.Name
-		item.Price = product.Price
-		item.SubTotal = int64(item.Quantity) * item.Price
+		item.Price = price
+		item.Describe(product, variant)
+		item.SubTotal = item.Price.Mul(int64(item.Quantity))
 
 		updatedItems = append(updatedItems, item)
-
-		break
 	}
 
 	if !found {
@@ -194,8 +512,24 @@ func (svc *CartService) UpdateItemQuantity(userId uuid.UUID, productId uuid.UUID
 
 	// cache it
 	userCart.SetItems(updatedItems)
-	if err := svc.cartRepo.Save(userId.String(), userCart, cacheDuration); err != nil {
+	if err := svc.cartRepo.Save(cartKey(userId), userCart, cacheDuration); err != nil {
 		return ErrInternal
 	}
 	return nil
 }
+
+// variantOf finds the variant a cart line or request refers to. Products
+// with variants can only be sold through one of them.
+func variantOf(product *models.Product, variantId *uuid.UUID) (*models.ProductVariant, error) {
+	if variantId == nil {
+		if product.HasVariants() {
+			return nil, ErrVariantRequired
+		}
+		return nil, nil
+	}
+	variant := product.FindVariant(*variantId)
+	if variant == nil {
+		return nil, ErrVariantNotFound
+	}
+	return variant, nil
+}
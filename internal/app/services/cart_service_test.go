@@ -0,0 +1,104 @@
+package services
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rezbow/ecommerce/internal/app/models"
+	"github.com/rezbow/ecommerce/internal/platform/database"
+)
+
+type fakeCartRepo struct {
+	database.ICartRepo
+	carts map[string]*models.Cart
+}
+
+func (repo *fakeCartRepo) Get(key string) (*models.Cart, error) {
+	cart, ok := repo.carts[key]
+	if !ok {
+		return nil, database.ErrRecordNotFound
+	}
+	return cart, nil
+}
+
+func (repo *fakeCartRepo) Save(key string, cart *models.Cart, _ time.Duration) error {
+	repo.carts[key] = cart
+	return nil
+}
+
+type fakeProductRepo struct {
+	database.IProductRepo
+	products map[uuid.UUID]*models.Product
+}
+
+func (repo *fakeProductRepo) Get(id uuid.UUID) (*models.Product, error) {
+	product, ok := repo.products[id]
+	if !ok {
+		return nil, database.ErrRecordNotFound
+	}
+	return product, nil
+}
+
+type fakePromotionRepo struct {
+	database.IPromotionRepo
+}
+
+func (repo *fakePromotionRepo) GetActive(time.Time) ([]models.Promotion, error) {
+	return nil, nil
+}
+
+type fakeAddressService struct {
+	IAddressService
+}
+
+func (svc *fakeAddressService) GetDefaultAddress(uuid.UUID) (*models.Address, error) {
+	return nil, ErrAddressNotFound
+}
+
+func TestGetCheckoutCart(t *testing.T) {
+	tests := []struct {
+		name        string
+		stock       int
+		price       int64
+		wantChanged bool
+	}{
+		{name: "unchanged", stock: 5, price: 1000},
+		{name: "sold out", stock: 0, price: 1000, wantChanged: true},
+		{name: "less stock than in the cart", stock: 1, price: 1000, wantChanged: true},
+		{name: "price went up", stock: 5, price: 1200, wantChanged: true},
+		{name: "price went down", stock: 5, price: 800, wantChanged: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			userId := uuid.New()
+			product := &models.Product{ID: uuid.New(), Name: "product", Price: models.NewMoney(1000, "USD"), StockQuantity: 5}
+			cart := models.NewCart(userId, "USD")
+			cart.AddQuantityOrInsert(product, nil, product.Price, 2)
+			cartRepo := &fakeCartRepo{carts: map[string]*models.Cart{cartKey(userId): cart}}
+
+			// the product changed after the user last saw the cart
+			live := *product
+			live.StockQuantity = tt.stock
+			live.Price = models.NewMoney(tt.price, "USD")
+			productRepo := &fakeProductRepo{products: map[uuid.UUID]*models.Product{product.ID: &live}}
+
+			svc := NewCartService(cartRepo, productRepo, nil, &fakePromotionRepo{}, nil,
+				NewTaxService(&models.TaxConfig{}), &fakeAddressService{}, "USD")
+			_, err := svc.GetCheckoutCart(userId)
+			if tt.wantChanged {
+				if !errors.Is(err, ErrCartChanged) {
+					t.Fatalf("GetCheckoutCart error is %v, want %v", err, ErrCartChanged)
+				}
+			} else if err != nil {
+				t.Fatalf("GetCheckoutCart: %v", err)
+			}
+
+			// the synced cart is saved either way, so a second try goes through
+			if _, err := svc.GetCheckoutCart(userId); err != nil {
+				t.Errorf("second GetCheckoutCart: %v", err)
+			}
+		})
+	}
+}
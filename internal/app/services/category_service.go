@@ -0,0 +1,131 @@
+package services
+
+import (
+	"errors"
+
+	"github.com/google/uuid"
+	"github.com/rezbow/ecommerce/internal/app/models"
+	"github.com/rezbow/ecommerce/internal/platform/database"
+)
+
+var (
+	ErrCategoryNotFound    = errors.New("category not found")
+	ErrCategoryExists      = errors.New("a category with this slug already exists")
+	ErrCategoryParent      = errors.New("parent category doesn't exist or is inside the category")
+	ErrCategoryHasChildren = errors.New("category still has subcategories")
+)
+
+type ICategoryService interface {
+	GetCategoryTree() ([]*models.CategoryNode, error)
+	ListCategoryProducts(string, *models.Pagination) ([]models.Product, int64, error)
+	CreateCategory(*models.CategoryCreate) (*models.Category, error)
+	UpdateCategory(uuid.UUID, *models.CategoryUpdateRequest) (*models.Category, error)
+	DeleteCategory(uuid.UUID) error
+}
+
+type CategoryService struct {
+	categoryRepo database.ICategoryRepo
+	productRepo  database.IProductRepo
+}
+
+func NewCategoryService(categoryRepo database.ICategoryRepo, productRepo database.IProductRepo) *CategoryService {
+	return &CategoryService{
+		categoryRepo: categoryRepo,
+		productRepo:  productRepo,
+	}
+}
+
+func (svc *CategoryService) GetCategoryTree() ([]*models.CategoryNode, error) {
+	categories, err := svc.categoryRepo.GetAll()
+	if err != nil {
+		return nil, ErrInternal
+	}
+	return models.BuildCategoryTree(categories), nil
+}
+
+// ListCategoryProducts pages through the products of the category with the
+// slug and of all its subcategories, along with how many there are in total.
+func (svc *CategoryService) ListCategoryProducts(slug string, pagination *models.Pagination) ([]models.Product, int64, error) {
+	category, err := svc.categoryRepo.GetBySlug(slug)
+	if err != nil {
+		if errors.Is(err, database.ErrRecordNotFound) {
+			return nil, 0, ErrCategoryNotFound
+		}
+		return nil, 0, ErrInternal
+	}
+	categoryIds, err := svc.categoryRepo.GetSubtreeIds(category.ID)
+	if err != nil {
+		if errors.Is(err, database.ErrRecordNotFound) {
+			return nil, 0, ErrCategoryNotFound
+		}
+		return nil, 0, ErrInternal
+	}
+	products, total, err := svc.productRepo.GetPaged(&models.ProductFilter{CategoryIds: categoryIds}, pagination)
+	if err != nil {
+		return nil, 0, ErrInternal
+	}
+	return products, total, nil
+}
+
+func (svc *CategoryService) CreateCategory(categoryCreate *models.CategoryCreate) (*models.Category, error) {
+	category := &models.Category{
+		ParentId:    categoryCreate.ParentId,
+		Name:        categoryCreate.Name,
+		Slug:        categoryCreate.Slug,
+		Description: categoryCreate.Description,
+	}
+	if err := svc.categoryRepo.Create(category); err != nil {
+		return nil, categoryWriteErr(err)
+	}
+	return category, nil
+}
+
+func (svc *CategoryService) UpdateCategory(categoryId uuid.UUID, categoryUpdateRequest *models.CategoryUpdateRequest) (*models.Category, error) {
+	// a category can't be moved under itself or one of its descendants,
+	// that would cut the subtree off the tree
+	if categoryUpdateRequest.ParentId != nil {
+		subtree, err := svc.categoryRepo.GetSubtreeIds(categoryId)
+		if err != nil {
+			if errors.Is(err, database.ErrRecordNotFound) {
+				return nil, ErrCategoryNotFound
+			}
+			return nil, ErrInternal
+		}
+		for _, id := range subtree {
+			if id == *categoryUpdateRequest.ParentId {
+				return nil, ErrCategoryParent
+			}
+		}
+	}
+
+	category, err := svc.categoryRepo.Update(categoryId, categoryUpdateRequest.ToMap())
+	if err != nil {
+		return nil, categoryWriteErr(err)
+	}
+	return category, nil
+}
+
+func (svc *CategoryService) DeleteCategory(categoryId uuid.UUID) error {
+	if err := svc.categoryRepo.Delete(categoryId); err != nil {
+		if errors.Is(err, database.ErrRecordNotFound) {
+			return ErrCategoryNotFound
+		}
+		if errors.Is(err, database.ErrForeignKeyViolation) {
+			return ErrCategoryHasChildren
+		}
+		return ErrInternal
+	}
+	return nil
+}
+
+func categoryWriteErr(err error) error {
+	switch {
+	case errors.Is(err, database.ErrRecordNotFound):
+		return ErrCategoryNotFound
+	case errors.Is(err, database.ErrDuplicateKey):
+		return ErrCategoryExists
+	case errors.Is(err, database.ErrForeignKeyViolation):
+		return ErrCategoryParent
+	}
+	return ErrInternal
+}
@@ -0,0 +1,181 @@
+package services
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rezbow/ecommerce/internal/app/models"
+	"github.com/rezbow/ecommerce/internal/platform/database"
+)
+
+// reservationHoldDuration is how long stock stays held for an order that
+// hasn't been paid
+const reservationHoldDuration = time.Minute * 15
+
+var (
+	ErrEmptyCart               = errors.New("cart is empty")
+	ErrShippingAddressRequired = errors.New("shipping address required, no default address saved")
+	ErrEmailNotVerified        = errors.New("verify your email before checking out")
+)
+
+type ICheckoutService interface {
+	Checkout(uuid.UUID, *models.CheckoutRequest) (*models.Order, error)
+}
+
+type CheckoutService struct {
+	cartSvc     ICartService
+	paymentSvc  IPaymentService
+	addressSvc  IAddressService
+	shippingSvc IShippingService
+	taxSvc      ITaxService
+	productRepo database.IProductRepo
+	orderRepo   database.IOrderRepo
+	userRepo    models.UserRepo
+}
+
+func NewCheckoutService(
+	cartSvc ICartService,
+	paymentSvc IPaymentService,
+	addressSvc IAddressService,
+	shippingSvc IShippingService,
+	taxSvc ITaxService,
+	productRepo database.IProductRepo,
+	orderRepo database.IOrderRepo,
+	userRepo models.UserRepo,
+) *CheckoutService {
+	return &CheckoutService{
+		cartSvc:     cartSvc,
+		paymentSvc:  paymentSvc,
+		addressSvc:  addressSvc,
+		shippingSvc: shippingSvc,
+		taxSvc:      taxSvc,
+		productRepo: productRepo,
+		orderRepo:   orderRepo,
+		userRepo:    userRepo,
+	}
+}
+
+func (svc *CheckoutService) Checkout(userId uuid.UUID, checkoutRequest *models.CheckoutRequest) (*models.Order, error) {
+	// only users who confirmed their email can place orders
+	user, err := svc.userRepo.Get(userId.String())
+	if err != nil {
+		if errors.Is(err, database.ErrRecordNotFound) {
+			return nil, ErrUserNotFound
+		}
+		return nil, ErrInternal
+	}
+	if !user.IsEmailVerified() {
+		return nil, ErrEmailNotVerified
+	}
+
+	// the order has to be for exactly what the user last saw in their cart
+	cart, err := svc.cartSvc.GetCheckoutCart(userId)
+	if err != nil {
+		if errors.Is(err, ErrCartNotFound) {
+			return nil, ErrEmptyCart
+		}
+		if errors.Is(err, ErrCartChanged) {
+			return nil, ErrCartChanged
+		}
+		return nil, ErrInternal
+	}
+	if len(cart.Items) == 0 {
+		return nil, ErrEmptyCart
+	}
+
+	// re-validate every line against live product data, the cart
+	// could have been synced a while ago
+	order := models.NewOrder(userId, cart.Currency)
+	if err := svc.shipTo(order, checkoutRequest); err != nil {
+		return nil, err
+	}
+	parcel := models.NewParcel(cart.Currency)
+	for _, item := range cart.Items {
+		product, err := svc.productRepo.Get(item.ProductId)
+		if err != nil {
+			if errors.Is(err, database.ErrRecordNotFound) {
+				return nil, ErrProductNotFound
+			}
+			return nil, ErrInternal
+		}
+		variant, err := variantOf(product, item.VariantId)
+		if err != nil {
+			return nil, err
+		}
+		if item.Quantity > product.StockOf(variant) {
+			return nil, ErrInsufficientQuantity
+		}
+		price, ok := product.PriceOf(variant, cart.Currency)
+		if !ok {
+			return nil, ErrPriceUnavailable
+		}
+		// the cart was priced just now, its discounts are up to date
+		order.AddItem(product, variant, item.Quantity, price, item.DiscountTotal())
+		if err := parcel.Add(product, item.Quantity, item.DiscountedSubTotal()); err != nil {
+			return nil, ErrCartCurrencyMismatch
+		}
+	}
+
+	quote, err := svc.shippingSvc.Quote(checkoutRequest.ShippingMethod, parcel, order.ShippingCountry)
+	if err != nil {
+		return nil, err
+	}
+	order.SetShipping(quote)
+	if cart.FreeShipping {
+		order.WaiveShipping()
+	}
+	if cart.Coupon != nil {
+		order.CouponId = &cart.Coupon.ID
+		order.CouponCode = &cart.Coupon.Code
+	}
+	if err := svc.taxSvc.TaxOrder(order); err != nil {
+		return nil, err
+	}
+
+	if err := svc.orderRepo.Create(order, time.Now().Add(reservationHoldDuration)); err != nil {
+		if errors.Is(err, database.ErrInsufficientStock) {
+			return nil, ErrInsufficientQuantity
+		}
+		if errors.Is(err, database.ErrLimitReached) {
+			return nil, ErrCouponLimitReached
+		}
+		return nil, ErrInternal
+	}
+
+	// a declined or failed payment cancels the order and keeps the cart,
+	// so the user can try again with another payment method
+	if _, err := svc.paymentSvc.Pay(order, checkoutRequest.PaymentToken); err != nil {
+		return nil, err
+	}
+
+	// the order is placed at this point, a stale cart is not worth failing for
+	_ = svc.cartSvc.ClearCart(userId)
+
+	return order, nil
+}
+
+// shipTo sets the order's shipping address from the request, falling back to
+// the user's default address.
+func (svc *CheckoutService) shipTo(order *models.Order, checkoutRequest *models.CheckoutRequest) error {
+	if checkoutRequest.ShippingAddress != nil {
+		order.ShipToFreeForm(*checkoutRequest.ShippingAddress, *checkoutRequest.ShippingCountry, checkoutRequest.ShippingRegion)
+		return nil
+	}
+
+	var address *models.Address
+	var err error
+	if checkoutRequest.AddressId != nil {
+		address, err = svc.addressSvc.GetAddress(order.UserId, *checkoutRequest.AddressId)
+	} else {
+		address, err = svc.addressSvc.GetDefaultAddress(order.UserId)
+		if errors.Is(err, ErrAddressNotFound) {
+			return ErrShippingAddressRequired
+		}
+	}
+	if err != nil {
+		return err
+	}
+	order.ShipTo(address)
+	return nil
+}
@@ -0,0 +1,155 @@
+package services
+
+import (
+	"errors"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rezbow/ecommerce/internal/app/models"
+	"github.com/rezbow/ecommerce/internal/platform/database"
+	"github.com/rezbow/ecommerce/internal/platform/payment"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// testDB connects to the Postgres database in TEST_DATABASE_DSN, which needs
+// every migration applied. CI provides one, elsewhere tests using it are
+// skipped when it isn't set.
+func testDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	dsn := os.Getenv("TEST_DATABASE_DSN")
+	if dsn == "" {
+		t.Skip("TEST_DATABASE_DSN is not set")
+	}
+	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{
+		TranslateError: true,
+		Logger:         logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		t.Fatalf("connecting to the test database: %v", err)
+	}
+	return db
+}
+
+// fakeCartService hands every checkout the same cart, the test has no use
+// for the Redis carts live in.
+type fakeCartService struct {
+	ICartService
+	cart *models.Cart
+}
+
+func (svc *fakeCartService) GetCheckoutCart(userId uuid.UUID) (*models.Cart, error) {
+	cart := *svc.cart
+	cart.UserId = userId
+	return &cart, nil
+}
+
+func (svc *fakeCartService) ClearCart(uuid.UUID) error {
+	return nil
+}
+
+// TestCheckoutLastUnit fires many checkouts at a product with a single unit
+// left, exactly one of them may get it.
+func TestCheckoutLastUnit(t *testing.T) {
+	db := testDB(t)
+	userRepo := database.NewUserRepo(db)
+	productRepo := database.NewProductRepo(db)
+	orderRepo := database.NewOrderRepo(db)
+
+	verifiedAt := time.Now()
+	user := &models.User{
+		Email:           "last-unit-" + uuid.NewString() + "@example.com",
+		PasswordHash:    "-",
+		EmailVerifiedAt: &verifiedAt,
+	}
+	if err := userRepo.Create(user); err != nil {
+		t.Fatalf("creating user: %v", err)
+	}
+	product := &models.Product{
+		Name:          "last unit",
+		Price:         models.NewMoney(1000, "USD"),
+		StockQuantity: 1,
+		TaxClass:      models.DefaultTaxClass,
+	}
+	if err := productRepo.Create(product); err != nil {
+		t.Fatalf("creating product: %v", err)
+	}
+	t.Cleanup(func() {
+		orders := db.Model(&models.Order{}).Select("id").Where("user_id = ?", user.ID)
+		db.Where("order_id IN (?)", orders).Delete(&models.Payment{})
+		db.Where("order_id IN (?)", orders).Delete(&models.OrderStatusChange{})
+		db.Where("order_id IN (?)", orders).Delete(&models.StockReservation{})
+		db.Where("order_id IN (?)", orders).Delete(&models.OrderItem{})
+		db.Where("user_id = ?", user.ID).Delete(&models.Order{})
+		db.Delete(&models.Product{}, "id = ?", product.ID)
+		db.Delete(&models.User{}, "id = ?", user.ID)
+	})
+
+	cart := models.NewCart(user.ID, "USD")
+	cart.AddQuantityOrInsert(product, nil, product.Price, 1)
+	cartSvc := &fakeCartService{cart: cart}
+	orderSvc := NewOrderService(orderRepo)
+	paymentSvc := NewPaymentService(payment.NewFakeProvider(), database.NewPaymentRepo(db), database.NewWebhookEventRepo(db), orderSvc)
+	addressSvc := NewAddressService(database.NewAddressRepo(db))
+	methods := []models.ShippingMethod{{Code: "standard", Name: "Standard", Type: models.ShippingRateFlat, Currency: "USD", Cost: 500}}
+	shippingSvc := NewShippingService(methods, cartSvc, addressSvc, productRepo)
+	taxSvc := NewTaxService(&models.TaxConfig{})
+	checkoutSvc := NewCheckoutService(cartSvc, paymentSvc, addressSvc, shippingSvc, taxSvc, productRepo, orderRepo, userRepo)
+
+	address := "1 Test Street, Testville"
+	country := "US"
+	request := &models.CheckoutRequest{
+		ShippingAddress: &address,
+		ShippingCountry: &country,
+		ShippingMethod:  "standard",
+		PaymentToken:    "tok_ok",
+	}
+
+	const checkouts = 25
+	var wg sync.WaitGroup
+	start := make(chan struct{})
+	results := make(chan error, checkouts)
+	for range checkouts {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			<-start
+			_, err := checkoutSvc.Checkout(user.ID, request)
+			results <- err
+		}()
+	}
+	close(start)
+	wg.Wait()
+	close(results)
+
+	placed := 0
+	for err := range results {
+		switch {
+		case err == nil:
+			placed++
+		case errors.Is(err, ErrInsufficientQuantity):
+		default:
+			t.Errorf("unexpected error: %v", err)
+		}
+	}
+	if placed != 1 {
+		t.Errorf("placed %d orders, want exactly 1", placed)
+	}
+
+	stored, err := productRepo.Get(product.ID)
+	if err != nil {
+		t.Fatalf("loading product: %v", err)
+	}
+	if stored.StockQuantity != 0 {
+		t.Errorf("stock is %d, want 0", stored.StockQuantity)
+	}
+	var paid int64
+	db.Model(&models.Order{}).Where("user_id = ? AND status = ?", user.ID, models.OrderStatusPaid).Count(&paid)
+	if paid != 1 {
+		t.Errorf("%d orders were paid, want 1", paid)
+	}
+}
@@ -0,0 +1,74 @@
+package services
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/rezbow/ecommerce/internal/app/models"
+	"github.com/rezbow/ecommerce/internal/platform/database"
+)
+
+var (
+	ErrCouponNotFound     = errors.New("coupon not found")
+	ErrCouponExists       = errors.New("a coupon with this code already exists")
+	ErrCouponNotActive    = errors.New("coupon is not valid at this time")
+	ErrCouponCurrency     = errors.New("coupon can't be used in the cart's currency")
+	ErrCouponMinimumTotal = errors.New("cart total is below the coupon's minimum")
+	ErrCouponLimitReached = errors.New("coupon usage limit reached")
+)
+
+type ICouponService interface {
+	CreateCoupon(*models.CouponCreate) (*models.Coupon, error)
+	ListCoupons(*models.Pagination) ([]models.Coupon, error)
+}
+
+type CouponService struct {
+	couponRepo database.ICouponRepo
+}
+
+func NewCouponService(couponRepo database.ICouponRepo) *CouponService {
+	return &CouponService{
+		couponRepo: couponRepo,
+	}
+}
+
+func (svc *CouponService) CreateCoupon(couponCreate *models.CouponCreate) (*models.Coupon, error) {
+	coupon := &models.Coupon{
+		Code:                  normalizeCouponCode(couponCreate.Code),
+		Type:                  couponCreate.Type,
+		StartsAt:              couponCreate.StartsAt,
+		EndsAt:                couponCreate.EndsAt,
+		MaxRedemptions:        couponCreate.MaxRedemptions,
+		MaxRedemptionsPerUser: couponCreate.MaxRedemptionsPerUser,
+	}
+	switch coupon.Type {
+	case models.CouponTypePercentage:
+		coupon.PercentBps = couponCreate.PercentBps
+	case models.CouponTypeFixed:
+		coupon.FixedAmount = models.NewMoney(couponCreate.FixedAmount.Amount, couponCreate.FixedAmount.Currency)
+	}
+	if couponCreate.MinTotal != nil {
+		coupon.MinTotal = models.NewMoney(couponCreate.MinTotal.Amount, couponCreate.MinTotal.Currency)
+	}
+
+	if err := svc.couponRepo.Create(coupon); err != nil {
+		if errors.Is(err, database.ErrDuplicateKey) {
+			return nil, ErrCouponExists
+		}
+		return nil, ErrInternal
+	}
+	return coupon, nil
+}
+
+func (svc *CouponService) ListCoupons(pagination *models.Pagination) ([]models.Coupon, error) {
+	coupons, err := svc.couponRepo.GetPaged(pagination)
+	if err != nil {
+		return nil, ErrInternal
+	}
+	return coupons, nil
+}
+
+// normalizeCouponCode makes codes case insensitive.
+func normalizeCouponCode(code string) string {
+	return strings.ToUpper(strings.TrimSpace(code))
+}
@@ -0,0 +1,117 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rezbow/ecommerce/internal/app/models"
+	"github.com/rezbow/ecommerce/internal/platform/authentication"
+	"github.com/rezbow/ecommerce/internal/platform/database"
+	"github.com/rezbow/ecommerce/internal/platform/mail"
+)
+
+var (
+	ErrInvalidVerificationToken = errors.New("invalid or expired email verification token")
+	ErrEmailAlreadyVerified     = errors.New("email is already verified")
+	ErrVerificationRateLimited  = errors.New("a verification email was sent recently, try again later")
+)
+
+type IEmailVerificationService interface {
+	SendVerification(*models.User) error
+	ResendVerification(uuid.UUID) error
+	VerifyEmail(string) error
+}
+
+type EmailVerificationService struct {
+	userRepo         models.UserRepo
+	verificationRepo database.IEmailVerificationRepo
+	mailer           mail.Mailer
+	// appURL is where the verification links in the emails point to
+	appURL   string
+	tokenTTL time.Duration
+	// resendInterval is how long a user has to wait between two emails
+	resendInterval time.Duration
+}
+
+func NewEmailVerificationService(
+	userRepo models.UserRepo,
+	verificationRepo database.IEmailVerificationRepo,
+	mailer mail.Mailer,
+	appURL string,
+	tokenTTL time.Duration,
+	resendInterval time.Duration,
+) *EmailVerificationService {
+	return &EmailVerificationService{
+		userRepo:         userRepo,
+		verificationRepo: verificationRepo,
+		mailer:           mailer,
+		appURL:           appURL,
+		tokenTTL:         tokenTTL,
+		resendInterval:   resendInterval,
+	}
+}
+
+// SendVerification mails the user a link that verifies their email, every
+// link sent before stops working.
+func (svc *EmailVerificationService) SendVerification(user *models.User) error {
+	token, hash, err := authentication.NewOpaqueToken()
+	if err != nil {
+		return ErrInternal
+	}
+	record := models.EmailVerificationToken{
+		UserId:    user.ID,
+		TokenHash: hash,
+		ExpiresAt: time.Now().Add(svc.tokenTTL),
+	}
+	if err := svc.verificationRepo.Create(&record); err != nil {
+		return ErrInternal
+	}
+
+	sendInBackground(svc.mailer, mail.Message{
+		To:      user.Email,
+		Subject: "Verify your email",
+		Body: fmt.Sprintf(
+			"Open the link below within %d hours to verify your email:\n\n%s/verify-email?token=%s\n\nIf you didn't sign up, you can ignore this email.",
+			int(svc.tokenTTL.Hours()), svc.appURL, url.QueryEscape(token),
+		),
+	})
+	return nil
+}
+
+// ResendVerification sends the user a new verification link, at most once
+// per resend interval.
+func (svc *EmailVerificationService) ResendVerification(userId uuid.UUID) error {
+	user, err := svc.userRepo.Get(userId.String())
+	if err != nil {
+		if errors.Is(err, database.ErrRecordNotFound) {
+			return ErrUserNotFound
+		}
+		return ErrInternal
+	}
+	if user.IsEmailVerified() {
+		return ErrEmailAlreadyVerified
+	}
+
+	latest, err := svc.verificationRepo.GetLatest(user.ID)
+	if err != nil && !errors.Is(err, database.ErrRecordNotFound) {
+		return ErrInternal
+	}
+	if latest != nil && time.Since(latest.CreatedAt) < svc.resendInterval {
+		return ErrVerificationRateLimited
+	}
+	return svc.SendVerification(user)
+}
+
+func (svc *EmailVerificationService) VerifyEmail(token string) error {
+	err := svc.verificationRepo.Verify(authentication.HashOpaqueToken(token), time.Now())
+	if err != nil {
+		if errors.Is(err, database.ErrRecordNotFound) || errors.Is(err, database.ErrExpired) {
+			return ErrInvalidVerificationToken
+		}
+		return ErrInternal
+	}
+	return nil
+}
@@ -0,0 +1,112 @@
+package services
+
+import (
+	"errors"
+
+	"github.com/google/uuid"
+	"github.com/rezbow/ecommerce/internal/app/models"
+	"github.com/rezbow/ecommerce/internal/platform/database"
+)
+
+var (
+	ErrOrderNotFound           = errors.New("order not found")
+	ErrInvalidOrderStatus      = errors.New("invalid order status")
+	ErrInvalidStatusTransition = errors.New("order status transition not allowed")
+	ErrOrderModified           = errors.New("order was modified concurrently")
+)
+
+type IOrderService interface {
+	ListUserOrders(uuid.UUID, *models.Pagination) ([]models.Order, error)
+	GetUserOrder(uuid.UUID, uuid.UUID) (*models.Order, error)
+	GetOrder(uuid.UUID) (*models.Order, error)
+	TransitionStatus(uuid.UUID, models.OrderStatus, *uuid.UUID) (*models.Order, error)
+	GetStatusHistory(uuid.UUID) ([]models.OrderStatusChange, error)
+}
+
+type OrderService struct {
+	orderRepo database.IOrderRepo
+}
+
+func NewOrderService(orderRepo database.IOrderRepo) *OrderService {
+	return &OrderService{
+		orderRepo: orderRepo,
+	}
+}
+
+func (svc *OrderService) ListUserOrders(userId uuid.UUID, pagination *models.Pagination) ([]models.Order, error) {
+	orders, err := svc.orderRepo.GetUserOrdersPaged(userId, pagination)
+	if err != nil {
+		return nil, ErrInternal
+	}
+	return orders, nil
+}
+
+// GetUserOrder reports orders of other users as not found so their
+// existence is not leaked.
+func (svc *OrderService) GetUserOrder(userId uuid.UUID, orderId uuid.UUID) (*models.Order, error) {
+	order, err := svc.orderRepo.GetUserOrder(orderId, userId)
+	if err != nil {
+		if errors.Is(err, database.ErrRecordNotFound) {
+			return nil, ErrOrderNotFound
+		}
+		return nil, ErrInternal
+	}
+	return order, nil
+}
+
+func (svc *OrderService) GetOrder(orderId uuid.UUID) (*models.Order, error) {
+	order, err := svc.orderRepo.Get(orderId)
+	if err != nil {
+		if errors.Is(err, database.ErrRecordNotFound) {
+			return nil, ErrOrderNotFound
+		}
+		return nil, ErrInternal
+	}
+	return order, nil
+}
+
+// TransitionStatus moves the order to the next status if the transition table
+// allows it and records actorId as the one who made the change. A nil actorId
+// marks the change as made by the system.
+func (svc *OrderService) TransitionStatus(orderId uuid.UUID, next models.OrderStatus, actorId *uuid.UUID) (*models.Order, error) {
+	if !next.IsValid() {
+		return nil, ErrInvalidOrderStatus
+	}
+	order, err := svc.orderRepo.Get(orderId)
+	if err != nil {
+		if errors.Is(err, database.ErrRecordNotFound) {
+			return nil, ErrOrderNotFound
+		}
+		return nil, ErrInternal
+	}
+	if !order.Status.CanTransitionTo(next) {
+		return nil, ErrInvalidStatusTransition
+	}
+
+	change := &models.OrderStatusChange{
+		FromStatus: order.Status,
+		ToStatus:   next,
+		ChangedBy:  actorId,
+	}
+	if err := svc.orderRepo.UpdateStatus(order, change); err != nil {
+		if errors.Is(err, database.ErrStaleRecord) {
+			return nil, ErrOrderModified
+		}
+		return nil, ErrInternal
+	}
+	return order, nil
+}
+
+func (svc *OrderService) GetStatusHistory(orderId uuid.UUID) ([]models.OrderStatusChange, error) {
+	if _, err := svc.orderRepo.Get(orderId); err != nil {
+		if errors.Is(err, database.ErrRecordNotFound) {
+			return nil, ErrOrderNotFound
+		}
+		return nil, ErrInternal
+	}
+	changes, err := svc.orderRepo.GetStatusHistory(orderId)
+	if err != nil {
+		return nil, ErrInternal
+	}
+	return changes, nil
+}
@@ -0,0 +1,112 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net/url"
+	"time"
+
+	"github.com/rezbow/ecommerce/internal/app/models"
+	"github.com/rezbow/ecommerce/internal/platform/authentication"
+	"github.com/rezbow/ecommerce/internal/platform/database"
+	"github.com/rezbow/ecommerce/internal/platform/mail"
+)
+
+var (
+	ErrInvalidResetToken = errors.New("invalid or expired password reset token")
+)
+
+type IPasswordResetService interface {
+	RequestReset(*models.ForgotPassword) error
+	ResetPassword(*models.ResetPassword) error
+}
+
+type PasswordResetService struct {
+	userRepo  models.UserRepo
+	resetRepo database.IPasswordResetRepo
+	mailer    mail.Mailer
+	// appURL is where the reset links in the emails point to
+	appURL   string
+	tokenTTL time.Duration
+}
+
+func NewPasswordResetService(
+	userRepo models.UserRepo,
+	resetRepo database.IPasswordResetRepo,
+	mailer mail.Mailer,
+	appURL string,
+	tokenTTL time.Duration,
+) *PasswordResetService {
+	return &PasswordResetService{
+		userRepo:  userRepo,
+		resetRepo: resetRepo,
+		mailer:    mailer,
+		appURL:    appURL,
+		tokenTTL:  tokenTTL,
+	}
+}
+
+// RequestReset mails a password reset link to the user with the email. It
+// succeeds the same way whether such a user exists or not, and the email is
+// sent in the background, so callers can't tell which accounts exist.
+func (svc *PasswordResetService) RequestReset(data *models.ForgotPassword) error {
+	user, err := svc.userRepo.GetByEmail(models.NormalizeEmail(data.Email))
+	if err != nil {
+		if errors.Is(err, database.ErrRecordNotFound) {
+			return nil
+		}
+		return ErrInternal
+	}
+
+	token, hash, err := authentication.NewOpaqueToken()
+	if err != nil {
+		return ErrInternal
+	}
+	record := models.PasswordResetToken{
+		UserId:    user.ID,
+		TokenHash: hash,
+		ExpiresAt: time.Now().Add(svc.tokenTTL),
+	}
+	if err := svc.resetRepo.Create(&record); err != nil {
+		return ErrInternal
+	}
+
+	message := mail.Message{
+		To:      user.Email,
+		Subject: "Reset your password",
+		Body: fmt.Sprintf(
+			"Someone asked to reset the password of your account. Open the link below within %d minutes to choose a new one:\n\n%s/reset-password?token=%s\n\nIf it wasn't you, you can ignore this email.",
+			int(svc.tokenTTL.Minutes()), svc.appURL, url.QueryEscape(token),
+		),
+	}
+	sendInBackground(svc.mailer, message)
+	return nil
+}
+
+// ResetPassword sets the new password of the user the token was issued to.
+// The token can't be used again, and every session of the user is revoked.
+func (svc *PasswordResetService) ResetPassword(data *models.ResetPassword) error {
+	passwordHash, err := authentication.HashPassword(data.Password)
+	if err != nil {
+		return ErrInternal
+	}
+	err = svc.resetRepo.ResetPassword(authentication.HashOpaqueToken(data.Token), passwordHash, time.Now())
+	if err != nil {
+		if errors.Is(err, database.ErrRecordNotFound) || errors.Is(err, database.ErrExpired) {
+			return ErrInvalidResetToken
+		}
+		return ErrInternal
+	}
+	return nil
+}
+
+// sendInBackground sends the message without making the caller wait for the
+// mailer, failures are only logged.
+func sendInBackground(mailer mail.Mailer, message mail.Message) {
+	go func() {
+		if err := mailer.Send(message); err != nil {
+			log.Printf("sending %q email: %v", message.Subject, err)
+		}
+	}()
+}
@@ -0,0 +1,340 @@
+package services
+
+import (
+	"errors"
+
+	"github.com/google/uuid"
+	"github.com/rezbow/ecommerce/internal/app/models"
+	"github.com/rezbow/ecommerce/internal/platform/database"
+	"github.com/rezbow/ecommerce/internal/platform/payment"
+)
+
+var (
+	ErrPaymentDeclined = errors.New("payment declined")
+	ErrPaymentFailed   = errors.New("payment failed")
+	ErrPaymentNotFound = errors.New("payment not found")
+	ErrDuplicateEvent  = errors.New("event already processed")
+)
+
+type IPaymentService interface {
+	Pay(*models.Order, string) (*models.Payment, error)
+	HandleEvent(*models.PaymentEvent) error
+	GetCapturedPayment(uuid.UUID) (*models.Payment, error)
+	Refund(*models.Payment, models.Money) error
+	ReleasePayments(uuid.UUID) error
+}
+
+type PaymentService struct {
+	provider         payment.Provider
+	paymentRepo      database.IPaymentRepo
+	webhookEventRepo database.IWebhookEventRepo
+	orderSvc         IOrderService
+}
+
+func NewPaymentService(
+	provider payment.Provider,
+	paymentRepo database.IPaymentRepo,
+	webhookEventRepo database.IWebhookEventRepo,
+	orderSvc IOrderService,
+) *PaymentService {
+	return &PaymentService{
+		provider:         provider,
+		paymentRepo:      paymentRepo,
+		webhookEventRepo: webhookEventRepo,
+		orderSvc:         orderSvc,
+	}
+}
+
+// Pay authorizes and captures the order total with the given payment token and
+// moves the order along with the result: paid once captured, cancelled when
+// the payment is declined or fails. When the provider times out the payment
+// and the order are left pending until the outcome is known. Orders totalling
+// zero, fully discounted ones, are settled without the provider.
+func (svc *PaymentService) Pay(order *models.Order, token string) (*models.Payment, error) {
+	if order.Total.IsZero() {
+		return svc.payNothing(order)
+	}
+
+	attempt := models.NewPayment(order, svc.provider.Name())
+	if err := svc.paymentRepo.Create(attempt); err != nil {
+		return nil, ErrInternal
+	}
+
+	reference, err := svc.provider.Authorize(payment.AuthorizeRequest{
+		OrderId:  order.ID,
+		Amount:   attempt.Amount.Amount,
+		Currency: attempt.Amount.Currency,
+		Token:    token,
+	})
+	switch {
+	case errors.Is(err, payment.ErrTimeout):
+		return attempt, nil
+	case errors.Is(err, payment.ErrDeclined):
+		attempt.Fail(models.PaymentStatusDeclined, err.Error())
+		return attempt, svc.failOrder(order, attempt, ErrPaymentDeclined)
+	case err != nil:
+		attempt.Fail(models.PaymentStatusFailed, err.Error())
+		return attempt, svc.failOrder(order, attempt, ErrPaymentFailed)
+	}
+
+	attempt.ProviderReference = &reference
+	attempt.Status = models.PaymentStatusAuthorized
+	if err := svc.paymentRepo.Update(attempt); err != nil {
+		return attempt, ErrInternal
+	}
+
+	if err := svc.provider.Capture(reference, attempt.Amount.Amount); err != nil {
+		// don't leave money blocked on the customer's card
+		_ = svc.provider.Void(reference)
+		attempt.Fail(models.PaymentStatusVoided, err.Error())
+		return attempt, svc.failOrder(order, attempt, ErrPaymentFailed)
+	}
+	attempt.Status = models.PaymentStatusCaptured
+	if err := svc.paymentRepo.Update(attempt); err != nil {
+		return attempt, ErrInternal
+	}
+	return attempt, svc.settleCapture(order, attempt)
+}
+
+// payNothing records a captured payment of zero for an order with nothing to
+// charge and marks it paid, providers refuse to authorize zero amounts.
+func (svc *PaymentService) payNothing(order *models.Order) (*models.Payment, error) {
+	attempt := models.NewPayment(order, models.PaymentProviderNone)
+	attempt.Status = models.PaymentStatusCaptured
+	if err := svc.paymentRepo.Create(attempt); err != nil {
+		return nil, ErrInternal
+	}
+	return attempt, svc.settleCapture(order, attempt)
+}
+
+// HandleEvent applies an asynchronous payment result reported by the provider.
+// Every event is applied at most once, redeliveries fail with
+// ErrDuplicateEvent.
+func (svc *PaymentService) HandleEvent(event *models.PaymentEvent) error {
+	record := &models.WebhookEvent{
+		Provider: svc.provider.Name(),
+		EventId:  event.ID,
+		Type:     event.Type,
+	}
+	if err := svc.webhookEventRepo.Create(record); err != nil {
+		if errors.Is(err, database.ErrDuplicateKey) {
+			return ErrDuplicateEvent
+		}
+		return ErrInternal
+	}
+
+	if err := svc.applyEvent(event); err != nil {
+		// forget the event so the provider's redelivery gets another chance
+		_ = svc.webhookEventRepo.Delete(record.ID)
+		return err
+	}
+	return nil
+}
+
+func (svc *PaymentService) applyEvent(event *models.PaymentEvent) error {
+	attempt, err := svc.findAttempt(event)
+	if err != nil {
+		return err
+	}
+	order, err := svc.orderSvc.GetOrder(attempt.OrderId)
+	if err != nil {
+		return err
+	}
+
+	switch event.Type {
+	case models.PaymentEventCaptured:
+		if attempt.Status == models.PaymentStatusCaptured {
+			return nil
+		}
+		cancelled := attempt.Status == models.PaymentStatusCancelled
+		attempt.ProviderReference = &event.Data.Reference
+		attempt.Status = models.PaymentStatusCaptured
+		if err := svc.paymentRepo.Update(attempt); err != nil {
+			return ErrInternal
+		}
+		if cancelled {
+			// the order was cancelled before the money came in
+			return svc.refundInFull(attempt)
+		}
+		return svc.settleCapture(order, attempt)
+
+	case models.PaymentEventFailed:
+		if attempt.Status != models.PaymentStatusPending && attempt.Status != models.PaymentStatusAuthorized {
+			return nil
+		}
+		reason := event.Data.Reason
+		if reason == "" {
+			reason = "reported as failed by the provider"
+		}
+		attempt.Fail(models.PaymentStatusFailed, reason)
+		if order.Status != models.OrderStatusPending {
+			if err := svc.paymentRepo.Update(attempt); err != nil {
+				return ErrInternal
+			}
+			return nil
+		}
+		return svc.failOrder(order, attempt, nil)
+
+	case models.PaymentEventRefunded:
+		// refunds issued through RefundService are already recorded, the
+		// event only matters when the refund was made at the provider
+		if !attempt.RefundedAmount.IsZero() {
+			return nil
+		}
+		attempt.RefundedAmount = attempt.Amount
+		attempt.Status = models.PaymentStatusRefunded
+		if err := svc.paymentRepo.Update(attempt); err != nil {
+			return ErrInternal
+		}
+		if !order.Status.CanTransitionTo(models.OrderStatusRefunded) {
+			return nil
+		}
+		_, err := svc.orderSvc.TransitionStatus(order.ID, models.OrderStatusRefunded, nil)
+		return err
+	}
+	return nil
+}
+
+// findAttempt looks the payment up by the provider's reference. Payments that
+// timed out never got one, so it falls back to the latest attempt of the
+// order still waiting for an answer, including those cancelled along with
+// their order.
+func (svc *PaymentService) findAttempt(event *models.PaymentEvent) (*models.Payment, error) {
+	if event.Data.Reference != "" {
+		attempt, err := svc.paymentRepo.GetByReference(svc.provider.Name(), event.Data.Reference)
+		if err == nil {
+			if attempt.OrderId != event.Data.OrderId {
+				return nil, ErrPaymentNotFound
+			}
+			return attempt, nil
+		}
+		if !errors.Is(err, database.ErrRecordNotFound) {
+			return nil, ErrInternal
+		}
+	}
+
+	attempts, err := svc.paymentRepo.GetByOrder(event.Data.OrderId)
+	if err != nil {
+		return nil, ErrInternal
+	}
+	for idx := range attempts {
+		switch attempts[idx].Status {
+		case models.PaymentStatusPending, models.PaymentStatusCancelled:
+			return &attempts[idx], nil
+		}
+	}
+	return nil, ErrPaymentNotFound
+}
+
+// settleCapture marks the order paid after its payment was captured. If the
+// order can't take the payment anymore, e.g. its stock hold expired in the
+// meantime, the money is given back.
+func (svc *PaymentService) settleCapture(order *models.Order, attempt *models.Payment) error {
+	if order.Status != models.OrderStatusPending && order.Status != models.OrderStatusCancelled {
+		// already paid through another attempt or event
+		return nil
+	}
+	paidOrder, err := svc.orderSvc.TransitionStatus(order.ID, models.OrderStatusPaid, nil)
+	if err != nil {
+		if refundErr := svc.refundInFull(attempt); refundErr != nil {
+			return refundErr
+		}
+		return ErrPaymentFailed
+	}
+	order.Status = paidOrder.Status
+	return nil
+}
+
+// refundInFull gives a captured payment back to the customer.
+func (svc *PaymentService) refundInFull(attempt *models.Payment) error {
+	if !attempt.Amount.IsZero() {
+		if err := svc.provider.Refund(*attempt.ProviderReference, attempt.Amount.Amount); err != nil {
+			return ErrInternal
+		}
+	}
+	attempt.RefundedAmount = attempt.Amount
+	attempt.Status = models.PaymentStatusRefunded
+	if err := svc.paymentRepo.Update(attempt); err != nil {
+		return ErrInternal
+	}
+	return nil
+}
+
+// GetCapturedPayment returns the payment that was collected for the order.
+func (svc *PaymentService) GetCapturedPayment(orderId uuid.UUID) (*models.Payment, error) {
+	attempts, err := svc.paymentRepo.GetByOrder(orderId)
+	if err != nil {
+		return nil, ErrInternal
+	}
+	for idx := range attempts {
+		if attempts[idx].Status == models.PaymentStatusCaptured {
+			return &attempts[idx], nil
+		}
+	}
+	return nil, ErrPaymentNotFound
+}
+
+// Refund gives amount of a captured payment back through the provider. It
+// doesn't persist anything, recording the refund is up to the caller.
+func (svc *PaymentService) Refund(attempt *models.Payment, amount models.Money) error {
+	refundable := attempt.Refundable()
+	if attempt.ProviderReference == nil || amount.Amount <= 0 ||
+		!amount.SameCurrency(refundable) || refundable.LessThan(amount) {
+		return ErrPaymentFailed
+	}
+	if err := svc.provider.Refund(*attempt.ProviderReference, amount.Amount); err != nil {
+		return ErrPaymentFailed
+	}
+	return nil
+}
+
+// ReleasePayments hands back everything collected for a cancelled order:
+// authorizations are voided and captured payments are refunded in full.
+func (svc *PaymentService) ReleasePayments(orderId uuid.UUID) error {
+	attempts, err := svc.paymentRepo.GetByOrder(orderId)
+	if err != nil {
+		return ErrInternal
+	}
+	for idx := range attempts {
+		attempt := &attempts[idx]
+		switch attempt.Status {
+		case models.PaymentStatusPending:
+			// the provider can still report a capture, see applyEvent
+			attempt.Fail(models.PaymentStatusCancelled, "order cancelled")
+		case models.PaymentStatusAuthorized:
+			if err := svc.provider.Void(*attempt.ProviderReference); err != nil {
+				return ErrPaymentFailed
+			}
+			attempt.Status = models.PaymentStatusVoided
+		case models.PaymentStatusCaptured:
+			// zero payments never reached the provider
+			if refundable := attempt.Refundable(); !refundable.IsZero() {
+				if err := svc.Refund(attempt, refundable); err != nil {
+					return err
+				}
+			}
+			attempt.RefundedAmount = attempt.Amount
+			attempt.Status = models.PaymentStatusRefunded
+		default:
+			continue
+		}
+		if err := svc.paymentRepo.Update(attempt); err != nil {
+			return ErrInternal
+		}
+	}
+	return nil
+}
+
+// failOrder persists the failed attempt and cancels the order so its stock
+// is released.
+func (svc *PaymentService) failOrder(order *models.Order, attempt *models.Payment, cause error) error {
+	if err := svc.paymentRepo.Update(attempt); err != nil {
+		return ErrInternal
+	}
+	cancelledOrder, err := svc.orderSvc.TransitionStatus(order.ID, models.OrderStatusCancelled, nil)
+	if err != nil {
+		return ErrInternal
+	}
+	order.Status = cancelledOrder.Status
+	return cause
+}
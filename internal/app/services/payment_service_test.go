@@ -0,0 +1,358 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/rezbow/ecommerce/internal/app/models"
+	"github.com/rezbow/ecommerce/internal/platform/database"
+	"github.com/rezbow/ecommerce/internal/platform/payment"
+)
+
+// fakePaymentRepo keeps payments in memory, in the order they were created.
+type fakePaymentRepo struct {
+	payments []*models.Payment
+}
+
+func (repo *fakePaymentRepo) Create(attempt *models.Payment) error {
+	attempt.ID = uuid.New()
+	stored := *attempt
+	repo.payments = append(repo.payments, &stored)
+	return nil
+}
+
+func (repo *fakePaymentRepo) Update(attempt *models.Payment) error {
+	for _, stored := range repo.payments {
+		if stored.ID == attempt.ID {
+			*stored = *attempt
+			return nil
+		}
+	}
+	return database.ErrRecordNotFound
+}
+
+func (repo *fakePaymentRepo) GetByOrder(orderId uuid.UUID) ([]models.Payment, error) {
+	payments := make([]models.Payment, 0)
+	for idx := len(repo.payments) - 1; idx >= 0; idx-- {
+		if repo.payments[idx].OrderId == orderId {
+			payments = append(payments, *repo.payments[idx])
+		}
+	}
+	return payments, nil
+}
+
+func (repo *fakePaymentRepo) GetByReference(provider string, reference string) (*models.Payment, error) {
+	for _, stored := range repo.payments {
+		if stored.Provider == provider && stored.ProviderReference != nil && *stored.ProviderReference == reference {
+			found := *stored
+			return &found, nil
+		}
+	}
+	return nil, database.ErrRecordNotFound
+}
+
+// fakeWebhookEventRepo remembers event ids like the unique index does.
+type fakeWebhookEventRepo struct {
+	events map[uuid.UUID]string
+}
+
+func (repo *fakeWebhookEventRepo) Create(event *models.WebhookEvent) error {
+	if repo.events == nil {
+		repo.events = make(map[uuid.UUID]string)
+	}
+	for _, eventId := range repo.events {
+		if eventId == event.EventId {
+			return database.ErrDuplicateKey
+		}
+	}
+	event.ID = uuid.New()
+	repo.events[event.ID] = event.EventId
+	return nil
+}
+
+func (repo *fakeWebhookEventRepo) Delete(id uuid.UUID) error {
+	delete(repo.events, id)
+	return nil
+}
+
+// fakeOrderService holds orders in memory and enforces the status machine.
+type fakeOrderService struct {
+	IOrderService
+	orders map[uuid.UUID]*models.Order
+}
+
+func newFakeOrderService(orders ...*models.Order) *fakeOrderService {
+	svc := &fakeOrderService{orders: make(map[uuid.UUID]*models.Order)}
+	for _, order := range orders {
+		svc.orders[order.ID] = order
+	}
+	return svc
+}
+
+func (svc *fakeOrderService) GetOrder(orderId uuid.UUID) (*models.Order, error) {
+	order, ok := svc.orders[orderId]
+	if !ok {
+		return nil, ErrOrderNotFound
+	}
+	stored := *order
+	return &stored, nil
+}
+
+func (svc *fakeOrderService) TransitionStatus(orderId uuid.UUID, next models.OrderStatus, _ *uuid.UUID) (*models.Order, error) {
+	order, ok := svc.orders[orderId]
+	if !ok {
+		return nil, ErrOrderNotFound
+	}
+	if !order.Status.CanTransitionTo(next) {
+		return nil, ErrInvalidStatusTransition
+	}
+	order.Status = next
+	stored := *order
+	return &stored, nil
+}
+
+// paymentTest is a payment service charging a single pending order through
+// a fresh fake provider.
+type paymentTest struct {
+	svc         *PaymentService
+	provider    *payment.FakeProvider
+	paymentRepo *fakePaymentRepo
+	orderSvc    *fakeOrderService
+	order       *models.Order
+}
+
+func newPaymentTest(total models.Money) *paymentTest {
+	order := models.NewOrder(uuid.New(), total.Currency)
+	order.ID = uuid.New()
+	order.Total = total
+	test := &paymentTest{
+		provider:    payment.NewFakeProvider(),
+		paymentRepo: &fakePaymentRepo{},
+		orderSvc:    newFakeOrderService(order),
+		order:       order,
+	}
+	test.svc = NewPaymentService(test.provider, test.paymentRepo, &fakeWebhookEventRepo{}, test.orderSvc)
+	return test
+}
+
+func (test *paymentTest) orderStatus() models.OrderStatus {
+	return test.orderSvc.orders[test.order.ID].Status
+}
+
+func (test *paymentTest) paymentStatus(t *testing.T) models.PaymentStatus {
+	t.Helper()
+	if len(test.paymentRepo.payments) != 1 {
+		t.Fatalf("recorded %d payments, want 1", len(test.paymentRepo.payments))
+	}
+	return test.paymentRepo.payments[0].Status
+}
+
+// capturedAtProvider charges the order's total at the provider behind the
+// service's back, like a capture the service only hears about by webhook.
+func (test *paymentTest) capturedAtProvider(t *testing.T) string {
+	t.Helper()
+	reference, err := test.provider.Authorize(payment.AuthorizeRequest{
+		OrderId:  test.order.ID,
+		Amount:   test.order.Total.Amount,
+		Currency: test.order.Total.Currency,
+		Token:    "tok_ok",
+	})
+	if err != nil {
+		t.Fatalf("Authorize: %v", err)
+	}
+	if err := test.provider.Capture(reference, test.order.Total.Amount); err != nil {
+		t.Fatalf("Capture: %v", err)
+	}
+	return reference
+}
+
+func TestPay(t *testing.T) {
+	tests := []struct {
+		name        string
+		token       string
+		wantErr     error
+		wantPayment models.PaymentStatus
+		wantOrder   models.OrderStatus
+	}{
+		{"captured", "tok_ok", nil, models.PaymentStatusCaptured, models.OrderStatusPaid},
+		{"declined", payment.FakeTokenDecline, ErrPaymentDeclined, models.PaymentStatusDeclined, models.OrderStatusCancelled},
+		{"timeout", payment.FakeTokenTimeout, nil, models.PaymentStatusPending, models.OrderStatusPending},
+		{"capture fails", payment.FakeTokenCaptureFail, ErrPaymentFailed, models.PaymentStatusVoided, models.OrderStatusCancelled},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			test := newPaymentTest(models.NewMoney(2500, "USD"))
+
+			_, err := test.svc.Pay(test.order, tt.token)
+			if err != tt.wantErr {
+				t.Fatalf("Pay returned %v, want %v", err, tt.wantErr)
+			}
+			if status := test.paymentStatus(t); status != tt.wantPayment {
+				t.Errorf("payment status is %s, want %s", status, tt.wantPayment)
+			}
+			if status := test.orderStatus(); status != tt.wantOrder {
+				t.Errorf("order status is %s, want %s", status, tt.wantOrder)
+			}
+		})
+	}
+}
+
+func TestPayCaptureFailVoidsAuthorization(t *testing.T) {
+	test := newPaymentTest(models.NewMoney(2500, "USD"))
+
+	attempt, _ := test.svc.Pay(test.order, payment.FakeTokenCaptureFail)
+	if attempt.ProviderReference == nil {
+		t.Fatal("authorized payment has no provider reference")
+	}
+	// a voided authorization can't be captured anymore
+	if err := test.provider.Void(*attempt.ProviderReference); err != nil {
+		t.Fatalf("authorization is not known to the provider: %v", err)
+	}
+	if err := test.provider.Capture(*attempt.ProviderReference, 2500); err != payment.ErrUnknownTransaction {
+		t.Errorf("capturing a voided authorization returned %v", err)
+	}
+}
+
+func TestPayTimeoutSettledByEvent(t *testing.T) {
+	tests := []struct {
+		name        string
+		event       models.PaymentEventType
+		wantPayment models.PaymentStatus
+		wantOrder   models.OrderStatus
+	}{
+		{"captured", models.PaymentEventCaptured, models.PaymentStatusCaptured, models.OrderStatusPaid},
+		{"failed", models.PaymentEventFailed, models.PaymentStatusFailed, models.OrderStatusCancelled},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			test := newPaymentTest(models.NewMoney(2500, "USD"))
+			if _, err := test.svc.Pay(test.order, payment.FakeTokenTimeout); err != nil {
+				t.Fatalf("Pay: %v", err)
+			}
+
+			event := &models.PaymentEvent{
+				ID:   "evt_1",
+				Type: tt.event,
+				Data: models.PaymentEventData{OrderId: test.order.ID, Reference: "fake_late"},
+			}
+			if err := test.svc.HandleEvent(event); err != nil {
+				t.Fatalf("HandleEvent: %v", err)
+			}
+			if err := test.svc.HandleEvent(event); err != ErrDuplicateEvent {
+				t.Errorf("redelivered event returned %v, want %v", err, ErrDuplicateEvent)
+			}
+			if status := test.paymentStatus(t); status != tt.wantPayment {
+				t.Errorf("payment status is %s, want %s", status, tt.wantPayment)
+			}
+			if status := test.orderStatus(); status != tt.wantOrder {
+				t.Errorf("order status is %s, want %s", status, tt.wantOrder)
+			}
+		})
+	}
+}
+
+func TestReleasePayments(t *testing.T) {
+	t.Run("refunds captured payments", func(t *testing.T) {
+		test := newPaymentTest(models.NewMoney(2500, "USD"))
+		attempt, err := test.svc.Pay(test.order, "tok_ok")
+		if err != nil {
+			t.Fatalf("Pay: %v", err)
+		}
+
+		if err := test.svc.ReleasePayments(test.order.ID); err != nil {
+			t.Fatalf("ReleasePayments: %v", err)
+		}
+		if status := test.paymentStatus(t); status != models.PaymentStatusRefunded {
+			t.Errorf("payment status is %s, want %s", status, models.PaymentStatusRefunded)
+		}
+		// everything was given back already
+		if err := test.provider.Refund(*attempt.ProviderReference, 1); err != payment.ErrInvalidAmount {
+			t.Errorf("refunding more returned %v, want %v", err, payment.ErrInvalidAmount)
+		}
+	})
+
+	t.Run("voids authorizations", func(t *testing.T) {
+		test := newPaymentTest(models.NewMoney(2500, "USD"))
+		reference, err := test.provider.Authorize(payment.AuthorizeRequest{
+			OrderId: test.order.ID, Amount: 2500, Currency: "USD", Token: "tok_ok",
+		})
+		if err != nil {
+			t.Fatalf("Authorize: %v", err)
+		}
+		attempt := models.NewPayment(test.order, test.provider.Name())
+		attempt.ProviderReference = &reference
+		attempt.Status = models.PaymentStatusAuthorized
+		test.paymentRepo.Create(attempt)
+
+		if err := test.svc.ReleasePayments(test.order.ID); err != nil {
+			t.Fatalf("ReleasePayments: %v", err)
+		}
+		if status := test.paymentStatus(t); status != models.PaymentStatusVoided {
+			t.Errorf("payment status is %s, want %s", status, models.PaymentStatusVoided)
+		}
+		if err := test.provider.Capture(reference, 2500); err != payment.ErrUnknownTransaction {
+			t.Errorf("capturing a voided authorization returned %v", err)
+		}
+	})
+
+	t.Run("refunds captures reported after the cancellation", func(t *testing.T) {
+		test := newPaymentTest(models.NewMoney(2500, "USD"))
+		if _, err := test.svc.Pay(test.order, payment.FakeTokenTimeout); err != nil {
+			t.Fatalf("Pay: %v", err)
+		}
+		if _, err := test.orderSvc.TransitionStatus(test.order.ID, models.OrderStatusCancelled, nil); err != nil {
+			t.Fatalf("cancelling: %v", err)
+		}
+		if err := test.svc.ReleasePayments(test.order.ID); err != nil {
+			t.Fatalf("ReleasePayments: %v", err)
+		}
+		if status := test.paymentStatus(t); status != models.PaymentStatusCancelled {
+			t.Fatalf("payment status is %s, want %s", status, models.PaymentStatusCancelled)
+		}
+
+		reference := test.capturedAtProvider(t)
+		event := &models.PaymentEvent{
+			ID:   "evt_late",
+			Type: models.PaymentEventCaptured,
+			Data: models.PaymentEventData{OrderId: test.order.ID, Reference: reference},
+		}
+		if err := test.svc.HandleEvent(event); err != nil {
+			t.Fatalf("HandleEvent: %v", err)
+		}
+		if status := test.paymentStatus(t); status != models.PaymentStatusRefunded {
+			t.Errorf("payment status is %s, want %s", status, models.PaymentStatusRefunded)
+		}
+		if status := test.orderStatus(); status != models.OrderStatusCancelled {
+			t.Errorf("order status is %s, want %s", status, models.OrderStatusCancelled)
+		}
+	})
+}
+
+func TestPayZeroTotal(t *testing.T) {
+	test := newPaymentTest(models.Zero("USD"))
+
+	attempt, err := test.svc.Pay(test.order, "tok_any")
+	if err != nil {
+		t.Fatalf("Pay: %v", err)
+	}
+	if attempt.Status != models.PaymentStatusCaptured {
+		t.Errorf("payment status is %s, want %s", attempt.Status, models.PaymentStatusCaptured)
+	}
+	if attempt.Provider != models.PaymentProviderNone || attempt.ProviderReference != nil {
+		t.Errorf("zero payment went through provider %s", attempt.Provider)
+	}
+	if !attempt.Amount.IsZero() {
+		t.Errorf("payment amount is %s, want 0", attempt.Amount)
+	}
+	if status := test.orderStatus(); status != models.OrderStatusPaid {
+		t.Errorf("order status is %s, want %s", status, models.OrderStatusPaid)
+	}
+
+	// cancelling the free order must not try to refund through the provider
+	if err := test.svc.ReleasePayments(test.order.ID); err != nil {
+		t.Fatalf("ReleasePayments: %v", err)
+	}
+	if status := test.paymentStatus(t); status != models.PaymentStatusRefunded {
+		t.Errorf("released payment status is %s, want %s", status, models.PaymentStatusRefunded)
+	}
+}
@@ -2,6 +2,7 @@ package services
 
 import (
 	"errors"
+	"strings"
 
 	"github.com/google/uuid"
 	"github.com/rezbow/ecommerce/internal/app/models"
@@ -9,23 +10,39 @@ import (
 )
 
 var (
-	ErrProductNotFound = errors.New("product not found")
+	ErrProductNotFound    = errors.New("product not found")
+	ErrProductHasVariants = errors.New("product options can't change while it has variants")
+	ErrVariantExists      = errors.New("a variant with this sku or these options already exists")
+	ErrVariantOptions     = errors.New("variant options must hold an allowed value for every option of the product")
+	ErrVariantOrdered     = errors.New("variant was already ordered and can't be deleted")
+	ErrEmptySearchQuery   = errors.New("search query must hold at least one word")
 )
 
 type IProductSvc interface {
-	ListProducts(*models.Pagination) ([]models.Product, error)
+	ListProducts(*models.ProductListQuery, *models.Pagination) ([]models.Product, int64, error)
+	SearchProducts(string, *models.Pagination) ([]models.ProductSearchHit, error)
 	GetProduct(uuid.UUID) (*models.Product, error)
 	CreateProduct(*models.ProductCreate) (*models.Product, error)
 	UpdateProduct(uuid.UUID, *models.ProductUpdateRequest) (*models.Product, error)
+	SetProductPrices(uuid.UUID, *models.ProductPricesUpdate) (*models.Product, error)
+	SetProductCategories(uuid.UUID, *models.ProductCategoriesUpdate) (*models.Product, error)
+	SetProductOptions(uuid.UUID, *models.ProductOptionsUpdate) (*models.Product, error)
+	CreateVariant(uuid.UUID, *models.VariantCreate) (*models.ProductVariant, error)
+	UpdateVariant(uuid.UUID, uuid.UUID, *models.VariantUpdateRequest) (*models.ProductVariant, error)
+	DeleteVariant(uuid.UUID, uuid.UUID) error
 }
 
 type ProductService struct {
-	productRepo database.IProductRepo
+	productRepo  database.IProductRepo
+	variantRepo  database.IVariantRepo
+	categoryRepo database.ICategoryRepo
 }
 
-func NewProductService(repo database.IProductRepo) *ProductService {
+func NewProductService(repo database.IProductRepo, variantRepo database.IVariantRepo, categoryRepo database.ICategoryRepo) *ProductService {
 	return &ProductService{
-		productRepo: repo,
+		productRepo:  repo,
+		variantRepo:  variantRepo,
+		categoryRepo: categoryRepo,
 	}
 }
 
@@ -40,25 +57,85 @@ func (svc *ProductService) GetProduct(id uuid.UUID) (*models.Product, error) {
 	return product, nil
 }
 
-func (svc *ProductService) ListProducts(pagination *models.Pagination) ([]models.Product, error) {
-	products, err := svc.productRepo.GetPaged(pagination)
+// ListProducts pages through the products matching the query, along with
+// how many of them match in total.
+func (svc *ProductService) ListProducts(query *models.ProductListQuery, pagination *models.Pagination) ([]models.Product, int64, error) {
+	filter := &models.ProductFilter{
+		MinPrice:     query.MinPrice,
+		MaxPrice:     query.MaxPrice,
+		Currency:     strings.ToUpper(query.Currency),
+		InStock:      query.InStock,
+		CreatedAfter: query.CreatedAfter,
+		Sort:         models.ProductSort(query.Sort),
+	}
+	if query.Category != "" {
+		category, err := svc.categoryRepo.GetBySlug(query.Category)
+		if err != nil {
+			if errors.Is(err, database.ErrRecordNotFound) {
+				return nil, 0, ErrCategoryNotFound
+			}
+			return nil, 0, ErrInternal
+		}
+		// products of subcategories belong to the category too
+		filter.CategoryIds, err = svc.categoryRepo.GetSubtreeIds(category.ID)
+		if err != nil {
+			if errors.Is(err, database.ErrRecordNotFound) {
+				return nil, 0, ErrCategoryNotFound
+			}
+			return nil, 0, ErrInternal
+		}
+	}
+
+	products, total, err := svc.productRepo.GetPaged(filter, pagination)
+	if err != nil {
+		return nil, 0, ErrInternal
+	}
+	return products, total, nil
+}
+
+// SearchProducts finds the products whose name or description have words
+// starting with every word of the query.
+func (svc *ProductService) SearchProducts(query string, pagination *models.Pagination) ([]models.ProductSearchHit, error) {
+	tsquery := models.PrefixSearchQuery(query)
+	if tsquery == "" {
+		return nil, ErrEmptySearchQuery
+	}
+	hits, err := svc.productRepo.Search(tsquery, pagination)
 	if err != nil {
 		return nil, ErrInternal
 	}
-	return products, nil
+	return hits, nil
 }
 
 func (svc *ProductService) CreateProduct(productCreate *models.ProductCreate) (*models.Product, error) {
 	product := &models.Product{
 		Name:          productCreate.Name,
 		Description:   productCreate.Description,
-		Price:         productCreate.Price,
+		Price:         models.NewMoney(productCreate.Price.Amount, productCreate.Price.Currency),
+		Prices:        priceOverrides(productCreate.Prices),
 		StockQuantity: productCreate.StockQuantity,
+		WeightGrams:   productCreate.WeightGrams,
+		LengthMm:      productCreate.LengthMm,
+		WidthMm:       productCreate.WidthMm,
+		HeightMm:      productCreate.HeightMm,
+		TaxClass:      productCreate.TaxClass,
+	}
+	if product.TaxClass == "" {
+		product.TaxClass = models.DefaultTaxClass
+	}
+	for _, categoryId := range productCreate.CategoryIds {
+		product.Categories = append(product.Categories, models.Category{ID: categoryId})
 	}
 
 	if err := svc.productRepo.Create(product); err != nil {
+		if errors.Is(err, database.ErrForeignKeyViolation) {
+			return nil, ErrCategoryNotFound
+		}
 		return nil, ErrInternal
 	}
+	if len(product.Categories) > 0 {
+		return svc.GetProduct(product.ID)
+	}
 
 	return product, nil
 }
@@ -73,3 +150,119 @@ func (svc *ProductService) UpdateProduct(productId uuid.UUID, productUpdateReque
 	}
 	return product, nil
 }
+
+// SetProductPrices replaces the per-currency price overrides of the product.
+func (svc *ProductService) SetProductPrices(productId uuid.UUID, pricesUpdate *models.ProductPricesUpdate) (*models.Product, error) {
+	if err := svc.productRepo.ReplacePrices(productId, priceOverrides(pricesUpdate.Prices)); err != nil {
+		if errors.Is(err, database.ErrRecordNotFound) {
+			return nil, ErrProductNotFound
+		}
+		return nil, ErrInternal
+	}
+	return svc.GetProduct(productId)
+}
+
+// SetProductCategories replaces the categories the product is assigned to.
+func (svc *ProductService) SetProductCategories(productId uuid.UUID, categoriesUpdate *models.ProductCategoriesUpdate) (*models.Product, error) {
+	if err := svc.productRepo.ReplaceCategories(productId, categoriesUpdate.CategoryIds); err != nil {
+		if errors.Is(err, database.ErrRecordNotFound) {
+			return nil, ErrProductNotFound
+		}
+		if errors.Is(err, database.ErrForeignKeyViolation) {
+			return nil, ErrCategoryNotFound
+		}
+		return nil, ErrInternal
+	}
+	return svc.GetProduct(productId)
+}
+
+// SetProductOptions replaces the options the variants of the product differ
+// in. They can only change before the product has any variant.
+func (svc *ProductService) SetProductOptions(productId uuid.UUID, optionsUpdate *models.ProductOptionsUpdate) (*models.Product, error) {
+	product, err := svc.GetProduct(productId)
+	if err != nil {
+		return nil, err
+	}
+	if product.HasVariants() {
+		return nil, ErrProductHasVariants
+	}
+
+	options := make([]models.ProductOption, len(optionsUpdate.Options))
+	for idx, option := range optionsUpdate.Options {
+		options[idx].Name = option.Name
+		options[idx].Values = option.Values
+	}
+	if err := svc.productRepo.ReplaceOptions(productId, options); err != nil {
+		if errors.Is(err, database.ErrRecordNotFound) {
+			return nil, ErrProductNotFound
+		}
+		return nil, ErrInternal
+	}
+	return svc.GetProduct(productId)
+}
+
+func (svc *ProductService) CreateVariant(productId uuid.UUID, variantCreate *models.VariantCreate) (*models.ProductVariant, error) {
+	product, err := svc.GetProduct(productId)
+	if err != nil {
+		return nil, err
+	}
+	if !product.MatchesOptions(variantCreate.Options) {
+		return nil, ErrVariantOptions
+	}
+
+	variant := &models.ProductVariant{
+		ProductId:     productId,
+		Sku:           variantCreate.Sku,
+		Options:       variantCreate.Options,
+		OptionsKey:    models.VariantOptionsKey(variantCreate.Options),
+		StockQuantity: variantCreate.StockQuantity,
+	}
+	if variantCreate.Price != nil {
+		variant.Price = models.NewMoney(variantCreate.Price.Amount, variantCreate.Price.Currency)
+	}
+	if err := svc.variantRepo.Create(variant); err != nil {
+		if errors.Is(err, database.ErrDuplicateKey) {
+			return nil, ErrVariantExists
+		}
+		if errors.Is(err, database.ErrRecordNotFound) {
+			return nil, ErrProductNotFound
+		}
+		return nil, ErrInternal
+	}
+	return variant, nil
+}
+
+func (svc *ProductService) UpdateVariant(productId uuid.UUID, variantId uuid.UUID, variantUpdateRequest *models.VariantUpdateRequest) (*models.ProductVariant, error) {
+	variant, err := svc.variantRepo.Update(productId, variantId, variantUpdateRequest.ToMap())
+	if err != nil {
+		if errors.Is(err, database.ErrRecordNotFound) {
+			return nil, ErrVariantNotFound
+		}
+		if errors.Is(err, database.ErrDuplicateKey) {
+			return nil, ErrVariantExists
+		}
+		return nil, ErrInternal
+	}
+	return variant, nil
+}
+
+func (svc *ProductService) DeleteVariant(productId uuid.UUID, variantId uuid.UUID) error {
+	if err := svc.variantRepo.Delete(productId, variantId); err != nil {
+		if errors.Is(err, database.ErrRecordNotFound) {
+			return ErrVariantNotFound
+		}
+		if errors.Is(err, database.ErrForeignKeyViolation) {
+			return ErrVariantOrdered
+		}
+		return ErrInternal
+	}
+	return nil
+}
+
+func priceOverrides(prices []models.Money) []models.ProductPrice {
+	overrides := make([]models.ProductPrice, len(prices))
+	for idx, price := range prices {
+		overrides[idx].Price = models.NewMoney(price.Amount, price.Currency)
+	}
+	return overrides
+}
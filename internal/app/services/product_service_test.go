@@ -0,0 +1,50 @@
+package services
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/rezbow/ecommerce/internal/app/models"
+	"github.com/rezbow/ecommerce/internal/platform/database"
+)
+
+func TestSearchProductsEscapesHighlights(t *testing.T) {
+	db := testDB(t)
+	productRepo := database.NewProductRepo(db)
+
+	description := `"quoted" <img src=x onerror=alert(1)> zqxhighlight & more`
+	product := &models.Product{
+		Name:          "<script>alert(1)</script> zqxhighlight",
+		Description:   &description,
+		Price:         models.NewMoney(1000, "USD"),
+		StockQuantity: 1,
+		TaxClass:      models.DefaultTaxClass,
+	}
+	if err := productRepo.Create(product); err != nil {
+		t.Fatalf("creating product: %v", err)
+	}
+	t.Cleanup(func() {
+		db.Delete(&models.Product{}, "id = ?", product.ID)
+	})
+
+	svc := NewProductService(productRepo, database.NewVariantRepo(db), database.NewCategoryRepo(db))
+	hits, err := svc.SearchProducts("zqxhighlight", &models.Pagination{Limit: 10})
+	if err != nil {
+		t.Fatalf("SearchProducts: %v", err)
+	}
+	if len(hits) != 1 {
+		t.Fatalf("found %d products, want 1", len(hits))
+	}
+	if hits[0].DescriptionHighlight == nil {
+		t.Fatal("description has no highlight")
+	}
+	for _, highlight := range []string{hits[0].NameHighlight, *hits[0].DescriptionHighlight} {
+		if !strings.Contains(highlight, "<mark>zqxhighlight</mark>") {
+			t.Errorf("highlight %q does not mark the match", highlight)
+		}
+		markup := strings.NewReplacer("<mark>", "", "</mark>", "").Replace(highlight)
+		if strings.ContainsAny(markup, `<>"'`) {
+			t.Errorf("highlight %q has markup besides <mark> tags", highlight)
+		}
+	}
+}
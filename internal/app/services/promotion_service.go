@@ -0,0 +1,87 @@
+package services
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/rezbow/ecommerce/internal/app/models"
+	"github.com/rezbow/ecommerce/internal/platform/database"
+)
+
+var (
+	ErrPromotionNotFound = errors.New("promotion not found")
+)
+
+type IPromotionService interface {
+	CreatePromotion(*models.PromotionCreate) (*models.Promotion, error)
+	ListPromotions(*models.Pagination) ([]models.Promotion, error)
+	GetPromotion(uuid.UUID) (*models.Promotion, error)
+	DeletePromotion(uuid.UUID) error
+}
+
+type PromotionService struct {
+	promotionRepo database.IPromotionRepo
+}
+
+func NewPromotionService(promotionRepo database.IPromotionRepo) *PromotionService {
+	return &PromotionService{
+		promotionRepo: promotionRepo,
+	}
+}
+
+func (svc *PromotionService) CreatePromotion(promotionCreate *models.PromotionCreate) (*models.Promotion, error) {
+	promotion := &models.Promotion{
+		Name:         promotionCreate.Name,
+		Type:         promotionCreate.Type,
+		Priority:     promotionCreate.Priority,
+		Exclusive:    promotionCreate.Exclusive,
+		PercentBps:   promotionCreate.PercentBps,
+		BuyQuantity:  promotionCreate.BuyQuantity,
+		FreeQuantity: promotionCreate.FreeQuantity,
+		Currency:     strings.ToUpper(promotionCreate.Currency),
+		Tiers:        promotionCreate.Tiers,
+		ProductIds:   promotionCreate.ProductIds,
+		CategoryIds:  promotionCreate.CategoryIds,
+		StartsAt:     promotionCreate.StartsAt,
+		EndsAt:       promotionCreate.EndsAt,
+		Active:       true,
+	}
+	if promotionCreate.Active != nil {
+		promotion.Active = *promotionCreate.Active
+	}
+
+	if err := svc.promotionRepo.Create(promotion); err != nil {
+		return nil, ErrInternal
+	}
+	return promotion, nil
+}
+
+func (svc *PromotionService) ListPromotions(pagination *models.Pagination) ([]models.Promotion, error) {
+	promotions, err := svc.promotionRepo.GetPaged(pagination)
+	if err != nil {
+		return nil, ErrInternal
+	}
+	return promotions, nil
+}
+
+func (svc *PromotionService) GetPromotion(id uuid.UUID) (*models.Promotion, error) {
+	promotion, err := svc.promotionRepo.Get(id)
+	if err != nil {
+		if errors.Is(err, database.ErrRecordNotFound) {
+			return nil, ErrPromotionNotFound
+		}
+		return nil, ErrInternal
+	}
+	return promotion, nil
+}
+
+func (svc *PromotionService) DeletePromotion(id uuid.UUID) error {
+	if err := svc.promotionRepo.Delete(id); err != nil {
+		if errors.Is(err, database.ErrRecordNotFound) {
+			return ErrPromotionNotFound
+		}
+		return ErrInternal
+	}
+	return nil
+}
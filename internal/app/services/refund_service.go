@@ -0,0 +1,153 @@
+package services
+
+import (
+	"errors"
+	"log"
+
+	"github.com/google/uuid"
+	"github.com/rezbow/ecommerce/internal/app/models"
+	"github.com/rezbow/ecommerce/internal/platform/database"
+)
+
+var (
+	ErrOrderNotRefundable    = errors.New("order can't be refunded in its current status")
+	ErrRefundItemNotFound    = errors.New("order item not found")
+	ErrRefundQuantity        = errors.New("refund quantity exceeds the quantity left on the order item")
+	ErrRefundExceedsCaptured = errors.New("refund exceeds the captured amount")
+	ErrNothingToRefund       = errors.New("nothing left to refund")
+)
+
+type IRefundService interface {
+	RefundOrder(uuid.UUID, *models.RefundRequest, uuid.UUID) (*models.Refund, error)
+	ListRefunds(uuid.UUID) ([]models.Refund, error)
+}
+
+type RefundService struct {
+	refundRepo database.IRefundRepo
+	orderSvc   IOrderService
+	paymentSvc IPaymentService
+}
+
+func NewRefundService(refundRepo database.IRefundRepo, orderSvc IOrderService, paymentSvc IPaymentService) *RefundService {
+	return &RefundService{
+		refundRepo: refundRepo,
+		orderSvc:   orderSvc,
+		paymentSvc: paymentSvc,
+	}
+}
+
+// RefundOrder refunds the requested lines of the order, or all that is left of
+// it when no lines are given. The refund is capped at what is left of the
+// captured payment and moves the order to refunded once nothing is left,
+// partially_refunded otherwise. The refund is recorded as pending before the
+// provider is asked to move the money, so a failure on either side can never
+// lead to refunding the same amount twice.
+func (svc *RefundService) RefundOrder(orderId uuid.UUID, refundRequest *models.RefundRequest, actorId uuid.UUID) (*models.Refund, error) {
+	order, err := svc.orderSvc.GetOrder(orderId)
+	if err != nil {
+		return nil, err
+	}
+	if !order.Status.CanTransitionTo(models.OrderStatusPartiallyRefunded) &&
+		!order.Status.CanTransitionTo(models.OrderStatusRefunded) {
+		return nil, ErrOrderNotRefundable
+	}
+
+	payment, err := svc.paymentSvc.GetCapturedPayment(order.ID)
+	if err != nil {
+		if errors.Is(err, ErrPaymentNotFound) {
+			return nil, ErrNothingToRefund
+		}
+		return nil, err
+	}
+
+	refunded, err := svc.refundedQuantities(order.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	refund := models.NewRefund(order, payment, actorId)
+	refund.Reason = refundRequest.Reason
+	refund.Restock = refundRequest.Restock
+	if len(refundRequest.Items) == 0 {
+		for idx := range order.Items {
+			item := &order.Items[idx]
+			if left := item.Quantity - refunded[item.ID]; left > 0 {
+				refund.AddItem(item, left, order.ItemRefundAmount(item, left))
+			}
+		}
+		// a full refund also gives back whatever isn't tied to a line
+		refund.SettleRemainder(payment.Refundable())
+	} else {
+		for _, requested := range refundRequest.Items {
+			item := order.FindItem(requested.OrderItemId)
+			if item == nil {
+				return nil, ErrRefundItemNotFound
+			}
+			if requested.Quantity > item.Quantity-refunded[item.ID] {
+				return nil, ErrRefundQuantity
+			}
+			refund.AddItem(item, requested.Quantity, order.ItemRefundAmount(item, requested.Quantity))
+		}
+	}
+
+	if refund.Amount.Amount <= 0 {
+		return nil, ErrNothingToRefund
+	}
+	exceeds, err := payment.Refundable().CheckedLessThan(refund.Amount)
+	if err != nil {
+		return nil, ErrInternal
+	}
+	if exceeds {
+		return nil, ErrRefundExceedsCaptured
+	}
+
+	if err := svc.refundRepo.Create(refund); err != nil {
+		if errors.Is(err, database.ErrStaleRecord) {
+			return nil, ErrOrderModified
+		}
+		return nil, ErrInternal
+	}
+
+	if err := svc.paymentSvc.Refund(payment, refund.Amount); err != nil {
+		if failErr := svc.refundRepo.Fail(refund); failErr != nil {
+			log.Printf("releasing failed refund %s: %v", refund.ID, failErr)
+		}
+		return nil, err
+	}
+	if err := svc.refundRepo.Complete(refund); err != nil {
+		// the money is gone, the refund stays pending with its amount set
+		// aside on the payment until an admin settles it
+		log.Printf("completing refund %s of order %s: %v", refund.ID, order.ID, err)
+		return nil, ErrInternal
+	}
+	return refund, nil
+}
+
+func (svc *RefundService) ListRefunds(orderId uuid.UUID) ([]models.Refund, error) {
+	if _, err := svc.orderSvc.GetOrder(orderId); err != nil {
+		return nil, err
+	}
+	refunds, err := svc.refundRepo.GetByOrder(orderId)
+	if err != nil {
+		return nil, ErrInternal
+	}
+	return refunds, nil
+}
+
+// refundedQuantities sums up, per order item, the quantity refunded so far.
+func (svc *RefundService) refundedQuantities(orderId uuid.UUID) (map[uuid.UUID]int, error) {
+	refunds, err := svc.refundRepo.GetByOrder(orderId)
+	if err != nil {
+		return nil, ErrInternal
+	}
+	quantities := make(map[uuid.UUID]int)
+	for _, refund := range refunds {
+		if refund.Status == models.RefundStatusFailed {
+			continue
+		}
+		for _, item := range refund.Items {
+			quantities[item.OrderItemId] += item.Quantity
+		}
+	}
+	return quantities, nil
+}
@@ -0,0 +1,79 @@
+package services
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rezbow/ecommerce/internal/app/models"
+	"github.com/rezbow/ecommerce/internal/platform/database"
+)
+
+var (
+	ErrRoleNotFound   = errors.New("role not found")
+	ErrLastSuperAdmin = errors.New("the last super admin can't lose the role")
+)
+
+type IRoleService interface {
+	ListRoles() ([]models.Role, error)
+	SetUserRoles(uuid.UUID, *models.UserRolesUpdate) (*models.User, error)
+}
+
+type RoleService struct {
+	roleRepo database.IRoleRepo
+	userRepo models.UserRepo
+}
+
+func NewRoleService(roleRepo database.IRoleRepo, userRepo models.UserRepo) *RoleService {
+	return &RoleService{
+		roleRepo: roleRepo,
+		userRepo: userRepo,
+	}
+}
+
+func (svc *RoleService) ListRoles() ([]models.Role, error) {
+	roles, err := svc.roleRepo.GetAll()
+	if err != nil {
+		return nil, ErrInternal
+	}
+	return roles, nil
+}
+
+// SetUserRoles replaces the roles of the user and signs them out everywhere,
+// their current access token is the only one still carrying the old
+// permissions.
+func (svc *RoleService) SetUserRoles(userId uuid.UUID, update *models.UserRolesUpdate) (*models.User, error) {
+	roles, err := svc.roleRepo.GetByNames(update.Roles)
+	if err != nil {
+		return nil, ErrInternal
+	}
+	found := make(map[string]bool, len(roles))
+	roleIds := make([]uuid.UUID, len(roles))
+	for idx, role := range roles {
+		found[role.Name] = true
+		roleIds[idx] = role.ID
+	}
+	for _, name := range update.Roles {
+		if !found[name] {
+			return nil, ErrRoleNotFound
+		}
+	}
+
+	if err := svc.roleRepo.SetUserRoles(userId, roleIds, time.Now()); err != nil {
+		if errors.Is(err, database.ErrRecordNotFound) {
+			return nil, ErrUserNotFound
+		}
+		if errors.Is(err, database.ErrLastHolder) {
+			return nil, ErrLastSuperAdmin
+		}
+		return nil, ErrInternal
+	}
+	user, err := svc.userRepo.Get(userId.String())
+	if err != nil {
+		if errors.Is(err, database.ErrRecordNotFound) {
+			return nil, ErrUserNotFound
+		}
+		return nil, ErrInternal
+	}
+	return user, nil
+}
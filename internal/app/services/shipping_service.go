@@ -0,0 +1,125 @@
+package services
+
+import (
+	"errors"
+	"sort"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/rezbow/ecommerce/internal/app/models"
+	"github.com/rezbow/ecommerce/internal/platform/database"
+)
+
+var (
+	ErrShippingMethodNotFound = errors.New("shipping method not found")
+	ErrShippingUnavailable    = errors.New("shipping method can't ship this order to the destination")
+)
+
+type IShippingService interface {
+	QuoteCart(uuid.UUID, *models.ShippingQuoteRequest) ([]models.ShippingQuote, error)
+	Quote(string, *models.Parcel, string) (*models.ShippingQuote, error)
+}
+
+type ShippingService struct {
+	methods     []models.ShippingMethod
+	cartSvc     ICartService
+	addressSvc  IAddressService
+	productRepo database.IProductRepo
+}
+
+func NewShippingService(
+	methods []models.ShippingMethod,
+	cartSvc ICartService,
+	addressSvc IAddressService,
+	productRepo database.IProductRepo,
+) *ShippingService {
+	return &ShippingService{
+		methods:     methods,
+		cartSvc:     cartSvc,
+		addressSvc:  addressSvc,
+		productRepo: productRepo,
+	}
+}
+
+// QuoteCart prices the user's cart with every shipping method that can ship
+// it to the requested destination, cheapest first. The destination is a
+// saved address, a country, or the user's default address. Like at
+// checkout, the parcel is worth what the cart costs after its discounts.
+func (svc *ShippingService) QuoteCart(userId uuid.UUID, quoteRequest *models.ShippingQuoteRequest) ([]models.ShippingQuote, error) {
+	country, err := svc.destinationCountry(userId, quoteRequest)
+	if err != nil {
+		return nil, err
+	}
+
+	cart, err := svc.cartSvc.GetUserCart(userId)
+	if err != nil {
+		return nil, err
+	}
+	parcel := models.NewParcel(cart.Currency)
+	for _, item := range cart.Items {
+		product, err := svc.productRepo.Get(item.ProductId)
+		if err != nil {
+			if errors.Is(err, database.ErrRecordNotFound) {
+				return nil, ErrProductNotFound
+			}
+			return nil, ErrInternal
+		}
+		if err := parcel.Add(product, item.Quantity, item.DiscountedSubTotal()); err != nil {
+			return nil, ErrCartCurrencyMismatch
+		}
+	}
+
+	quotes := make([]models.ShippingQuote, 0)
+	for idx := range svc.methods {
+		method := &svc.methods[idx]
+		if cost, ok := method.Quote(parcel, country); ok {
+			quotes = append(quotes, models.ShippingQuote{Method: method.Code, Name: method.Name, Cost: cost})
+		}
+	}
+	sort.SliceStable(quotes, func(i, j int) bool {
+		return quotes[i].Cost.LessThan(quotes[j].Cost)
+	})
+	return quotes, nil
+}
+
+// Quote prices the parcel with a single shipping method.
+func (svc *ShippingService) Quote(methodCode string, parcel *models.Parcel, country string) (*models.ShippingQuote, error) {
+	for idx := range svc.methods {
+		method := &svc.methods[idx]
+		if method.Code != methodCode {
+			continue
+		}
+		cost, ok := method.Quote(parcel, country)
+		if !ok {
+			return nil, ErrShippingUnavailable
+		}
+		return &models.ShippingQuote{Method: method.Code, Name: method.Name, Cost: cost}, nil
+	}
+	return nil, ErrShippingMethodNotFound
+}
+
+func (svc *ShippingService) destinationCountry(userId uuid.UUID, quoteRequest *models.ShippingQuoteRequest) (string, error) {
+	if quoteRequest.Country != "" {
+		return strings.ToUpper(quoteRequest.Country), nil
+	}
+	if quoteRequest.AddressId != "" {
+		addressId, err := uuid.Parse(quoteRequest.AddressId)
+		if err != nil {
+			return "", ErrAddressNotFound
+		}
+		address, err := svc.addressSvc.GetAddress(userId, addressId)
+		if err != nil {
+			return "", err
+		}
+		return address.Country, nil
+	}
+	address, err := svc.addressSvc.GetDefaultAddress(userId)
+	if err != nil {
+		if errors.Is(err, ErrAddressNotFound) {
+			// only methods that ship everywhere apply
+			return "", nil
+		}
+		return "", err
+	}
+	return address.Country, nil
+}
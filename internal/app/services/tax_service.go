@@ -0,0 +1,70 @@
+package services
+
+import (
+	"errors"
+
+	"github.com/rezbow/ecommerce/internal/app/models"
+)
+
+var (
+	ErrTaxDestinationUnknown = errors.New("the country the order ships to is required to tax it")
+)
+
+type ITaxService interface {
+	TaxCart(*models.Cart, models.TaxDestination)
+	TaxOrder(*models.Order) error
+}
+
+type TaxService struct {
+	config *models.TaxConfig
+}
+
+func NewTaxService(config *models.TaxConfig) *TaxService {
+	return &TaxService{
+		config: config,
+	}
+}
+
+// TaxCart prices the tax of the cart for the destination.
+func (svc *TaxService) TaxCart(cart *models.Cart, destination models.TaxDestination) {
+	breakdown := models.NewTaxBreakdown(svc.config.PricesIncludeTax, cart.Currency)
+	for _, item := range cart.Items {
+		rule := svc.config.Rule(destination, taxClassOrDefault(item.TaxClass))
+		if rule == nil {
+			continue
+		}
+		taxable := item.DiscountedSubTotal()
+		breakdown.Add(rule, taxable, svc.config.Tax(taxable, rule.RateBps))
+	}
+	cart.SetTax(breakdown)
+}
+
+// TaxOrder prices the tax of every order line for the country and region
+// the order ships to. It fails with ErrTaxDestinationUnknown when they are
+// not known, an order must never go untaxed for lack of an address.
+func (svc *TaxService) TaxOrder(order *models.Order) error {
+	destination, ok := order.TaxDestination()
+	if !ok {
+		return ErrTaxDestinationUnknown
+	}
+	breakdown := models.NewTaxBreakdown(svc.config.PricesIncludeTax, order.Total.Currency)
+	for idx := range order.Items {
+		item := &order.Items[idx]
+		rule := svc.config.Rule(destination, taxClassOrDefault(item.TaxClass))
+		if rule == nil {
+			continue
+		}
+		taxable := item.Taxable()
+		item.Tax = svc.config.Tax(taxable, rule.RateBps)
+		breakdown.Add(rule, taxable, item.Tax)
+	}
+	order.SetTax(breakdown)
+	return nil
+}
+
+func taxClassOrDefault(taxClass string) string {
+	if taxClass == "" {
+		return models.DefaultTaxClass
+	}
+	return taxClass
+}
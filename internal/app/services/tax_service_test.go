@@ -0,0 +1,210 @@
+package services
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/rezbow/ecommerce/internal/app/models"
+	"github.com/rezbow/ecommerce/internal/platform/config"
+)
+
+func loadTestTaxConfig(t *testing.T, pricesIncludeTax bool) *models.TaxConfig {
+	t.Helper()
+	var taxConfig models.TaxConfig
+	if err := config.LoadJSONFile("testdata/tax_rules.json", &taxConfig); err != nil {
+		t.Fatalf("loading tax rules: %v", err)
+	}
+	if err := taxConfig.Validate(); err != nil {
+		t.Fatalf("tax rules: %v", err)
+	}
+	taxConfig.PricesIncludeTax = pricesIncludeTax
+	return &taxConfig
+}
+
+type taxTestLine struct {
+	taxClass string
+	price    int64
+	quantity int
+	discount int64
+}
+
+func taxTestOrder(country string, region *string, lines ...taxTestLine) *models.Order {
+	order := models.NewOrder(uuid.New(), "USD")
+	order.ShipToFreeForm("1 Main Street, Springfield", country, region)
+	for _, line := range lines {
+		product := &models.Product{ID: uuid.New(), TaxClass: line.taxClass}
+		order.AddItem(product, nil, line.quantity, models.NewMoney(line.price, "USD"), models.NewMoney(line.discount, "USD"))
+	}
+	return order
+}
+
+func TestTaxOrder(t *testing.T) {
+	region := func(region string) *string { return &region }
+	tests := []struct {
+		name             string
+		pricesIncludeTax bool
+		country          string
+		region           *string
+		lines            []taxTestLine
+		wantTax          int64
+		wantTotal        int64
+		wantRules        []string
+	}{
+		{
+			name:      "region rule wins over the country's",
+			country:   "US",
+			region:    region("CA"),
+			lines:     []taxTestLine{{taxClass: "standard", price: 1000, quantity: 2}},
+			wantTax:   145,
+			wantTotal: 2145,
+			wantRules: []string{"US-CA sales tax"},
+		},
+		{
+			name:      "region without a rule falls back to the country",
+			country:   "US",
+			region:    region("TX"),
+			lines:     []taxTestLine{{taxClass: "standard", price: 1000, quantity: 1}},
+			wantTax:   50,
+			wantTotal: 1050,
+			wantRules: []string{"US sales tax"},
+		},
+		{
+			name:      "no region falls back to the country",
+			country:   "us",
+			lines:     []taxTestLine{{taxClass: "", price: 1000, quantity: 1}},
+			wantTax:   50,
+			wantTotal: 1050,
+			wantRules: []string{"US sales tax"},
+		},
+		{
+			name:    "rates per tax class",
+			country: "DE",
+			lines: []taxTestLine{
+				{taxClass: "standard", price: 1000, quantity: 1},
+				{taxClass: "reduced", price: 1000, quantity: 1},
+			},
+			wantTax:   260,
+			wantTotal: 2260,
+			wantRules: []string{"DE VAT", "DE reduced VAT"},
+		},
+		{
+			name:      "tax class without a rule is not taxed",
+			country:   "GB",
+			lines:     []taxTestLine{{taxClass: "standard", price: 1000, quantity: 1}},
+			wantTax:   0,
+			wantTotal: 1000,
+		},
+		{
+			name:      "country without rules is not taxed",
+			country:   "FR",
+			lines:     []taxTestLine{{taxClass: "standard", price: 1000, quantity: 1}},
+			wantTax:   0,
+			wantTotal: 1000,
+		},
+		{
+			name:      "tax is charged after the discount",
+			country:   "DE",
+			lines:     []taxTestLine{{taxClass: "standard", price: 1200, quantity: 1, discount: 200}},
+			wantTax:   190,
+			wantTotal: 1190,
+			wantRules: []string{"DE VAT"},
+		},
+		{
+			name:      "exclusive tax rounds half up",
+			country:   "US",
+			region:    region("CA"),
+			lines:     []taxTestLine{{taxClass: "standard", price: 1000, quantity: 1}},
+			wantTax:   73,
+			wantTotal: 1073,
+			wantRules: []string{"US-CA sales tax"},
+		},
+		{
+			name:      "exclusive tax rounds down below half",
+			country:   "US",
+			region:    region("CA"),
+			lines:     []taxTestLine{{taxClass: "standard", price: 999, quantity: 1}},
+			wantTax:   72,
+			wantTotal: 1071,
+			wantRules: []string{"US-CA sales tax"},
+		},
+		{
+			name:             "inclusive tax is taken out of the price",
+			pricesIncludeTax: true,
+			country:          "DE",
+			lines:            []taxTestLine{{taxClass: "standard", price: 1190, quantity: 1}},
+			wantTax:          190,
+			wantTotal:        1190,
+			wantRules:        []string{"DE VAT"},
+		},
+		{
+			name:             "inclusive tax rounds half up",
+			pricesIncludeTax: true,
+			country:          "DE",
+			lines:            []taxTestLine{{taxClass: "standard", price: 1000, quantity: 1}},
+			wantTax:          160,
+			wantTotal:        1000,
+			wantRules:        []string{"DE VAT"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc := NewTaxService(loadTestTaxConfig(t, tt.pricesIncludeTax))
+			order := taxTestOrder(tt.country, tt.region, tt.lines...)
+			if err := svc.TaxOrder(order); err != nil {
+				t.Fatalf("TaxOrder: %v", err)
+			}
+			if order.Tax.Amount != tt.wantTax {
+				t.Errorf("tax is %d, want %d", order.Tax.Amount, tt.wantTax)
+			}
+			if order.Total.Amount != tt.wantTotal {
+				t.Errorf("total is %d, want %d", order.Total.Amount, tt.wantTotal)
+			}
+			var itemTax int64
+			for _, item := range order.Items {
+				itemTax += item.Tax.Amount
+			}
+			if itemTax != tt.wantTax {
+				t.Errorf("item taxes add up to %d, want %d", itemTax, tt.wantTax)
+			}
+			if len(order.TaxBreakdown.Lines) != len(tt.wantRules) {
+				t.Fatalf("breakdown has %d lines, want %d", len(order.TaxBreakdown.Lines), len(tt.wantRules))
+			}
+			for idx, line := range order.TaxBreakdown.Lines {
+				if line.Name != tt.wantRules[idx] {
+					t.Errorf("breakdown line %d is %q, want %q", idx, line.Name, tt.wantRules[idx])
+				}
+			}
+		})
+	}
+}
+
+func TestTaxOrderWithoutDestination(t *testing.T) {
+	svc := NewTaxService(loadTestTaxConfig(t, false))
+	order := taxTestOrder("", nil, taxTestLine{taxClass: "standard", price: 1000, quantity: 1})
+	if err := svc.TaxOrder(order); !errors.Is(err, ErrTaxDestinationUnknown) {
+		t.Fatalf("TaxOrder error is %v, want %v", err, ErrTaxDestinationUnknown)
+	}
+}
+
+func TestTaxCart(t *testing.T) {
+	svc := NewTaxService(loadTestTaxConfig(t, false))
+	cart := models.NewCart(uuid.New(), "USD")
+	cart.AddQuantityOrInsert(&models.Product{ID: uuid.New(), TaxClass: "standard"}, nil, models.NewMoney(1000, "USD"), 1)
+	cart.AddQuantityOrInsert(&models.Product{ID: uuid.New(), TaxClass: "standard"}, nil, models.NewMoney(500, "USD"), 2)
+	cart.AddQuantityOrInsert(&models.Product{ID: uuid.New(), TaxClass: "reduced"}, nil, models.NewMoney(1000, "USD"), 1)
+
+	svc.TaxCart(cart, models.TaxDestination{Country: "DE"})
+	if cart.Tax.Total.Amount != 450 {
+		t.Errorf("tax is %d, want 450", cart.Tax.Total.Amount)
+	}
+	if cart.TotalWithTax.Amount != 3450 {
+		t.Errorf("total with tax is %d, want 3450", cart.TotalWithTax.Amount)
+	}
+	if len(cart.Tax.Lines) != 2 {
+		t.Fatalf("breakdown has %d lines, want 2", len(cart.Tax.Lines))
+	}
+	if line := cart.Tax.Lines[0]; line.Taxable.Amount != 2000 || line.Amount.Amount != 380 {
+		t.Errorf("standard line taxes %d for %d, want 380 for 2000", line.Amount.Amount, line.Taxable.Amount)
+	}
+}
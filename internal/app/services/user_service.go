@@ -2,6 +2,8 @@ package services
 
 import (
 	"errors"
+	"log"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/rezbow/ecommerce/internal/app/models"
@@ -10,14 +12,30 @@ import (
 )
 
 type UserSvc struct {
-	userRepo  models.UserRepo
-	jwtSecret string
+	userRepo         models.UserRepo
+	refreshTokenRepo database.IRefreshTokenRepo
+	verificationSvc  IEmailVerificationService
+	jwtSecret        string
+	// how long access and refresh tokens are valid for
+	accessTokenTTL  time.Duration
+	refreshTokenTTL time.Duration
 }
 
-func NewUserService(repo models.UserRepo, jwtSecret string) *UserSvc {
+func NewUserService(
+	repo models.UserRepo,
+	refreshTokenRepo database.IRefreshTokenRepo,
+	verificationSvc IEmailVerificationService,
+	jwtSecret string,
+	accessTokenTTL time.Duration,
+	refreshTokenTTL time.Duration,
+) *UserSvc {
 	return &UserSvc{
-		userRepo:  repo,
-		jwtSecret: jwtSecret,
+		userRepo:         repo,
+		refreshTokenRepo: refreshTokenRepo,
+		verificationSvc:  verificationSvc,
+		jwtSecret:        jwtSecret,
+		accessTokenTTL:   accessTokenTTL,
+		refreshTokenTTL:  refreshTokenTTL,
 	}
 }
 
@@ -26,6 +44,8 @@ var (
 	ErrDuplicateEmail     = errors.New("duplicate email")
 	ErrInternal           = errors.New("internal error")
 	ErrInvalidCredentials = errors.New("wrong email or password")
+	ErrInvalidToken       = errors.New("invalid or expired refresh token")
+	ErrTokenReused        = errors.New("refresh token was already used, the session has been revoked")
 )
 
 func (svc *UserSvc) RegisterUser(data *models.RegisterUser) (*models.User, error) {
@@ -34,7 +54,7 @@ func (svc *UserSvc) RegisterUser(data *models.RegisterUser) (*models.User, error
 		return nil, ErrInternal
 	}
 	user := models.User{
-		Email:        data.Email,
+		Email:        models.NormalizeEmail(data.Email),
 		PasswordHash: passwordHash,
 	}
 	err = svc.userRepo.Create(&user)
@@ -44,30 +64,97 @@ func (svc *UserSvc) RegisterUser(data *models.RegisterUser) (*models.User, error
 		}
 		return nil, ErrInternal
 	}
+	// the account exists either way, the user can ask for another email
+	if err := svc.verificationSvc.SendVerification(&user); err != nil {
+		log.Printf("sending verification email to user %s: %v", user.ID, err)
+	}
 	return &user, nil
 }
 
-func (svc *UserSvc) Authenticate(data *models.Login) (string, error) {
+func (svc *UserSvc) Authenticate(data *models.Login) (*models.TokenPair, error) {
 	// check db
-	user, err := svc.userRepo.GetByEmail(data.Email)
+	user, err := svc.userRepo.GetByEmail(models.NormalizeEmail(data.Email))
 	if err != nil {
 		if errors.Is(err, database.ErrRecordNotFound) {
-			return "", ErrInvalidCredentials
+			return nil, ErrInvalidCredentials
 		}
-		return "", ErrInternal
+		return nil, ErrInternal
 	}
 
 	if !authentication.CheckPassword(data.Password, user.PasswordHash) {
-		return "", ErrInvalidCredentials
+		return nil, ErrInvalidCredentials
 	}
 
-	// generate jwt token
-	token, err := authentication.NewJWTToken(user.ID, user.IsAdmin, svc.jwtSecret)
+	// every login starts a new refresh token family
+	refreshToken, hash, err := authentication.NewOpaqueToken()
 	if err != nil {
-		return "", ErrInternal
+		return nil, ErrInternal
+	}
+	record := models.RefreshToken{
+		UserId:    user.ID,
+		TokenHash: hash,
+		ExpiresAt: time.Now().Add(svc.refreshTokenTTL),
 	}
-	return token, nil
+	if err := svc.refreshTokenRepo.Create(&record); err != nil {
+		return nil, ErrInternal
+	}
+	return svc.tokenPair(user, refreshToken)
+}
 
+// RefreshSession swaps the refresh token for a new one and hands out a fresh
+// access token along with it. A refresh token can only be used once, using
+// it again revokes every token issued since the login it came from.
+func (svc *UserSvc) RefreshSession(refreshToken string) (*models.TokenPair, error) {
+	nextToken, hash, err := authentication.NewOpaqueToken()
+	if err != nil {
+		return nil, ErrInternal
+	}
+	now := time.Now()
+	next := models.RefreshToken{
+		TokenHash: hash,
+		ExpiresAt: now.Add(svc.refreshTokenTTL),
+	}
+	err = svc.refreshTokenRepo.Rotate(authentication.HashOpaqueToken(refreshToken), &next, now)
+	if err != nil {
+		switch {
+		case errors.Is(err, database.ErrRecordNotFound), errors.Is(err, database.ErrExpired):
+			return nil, ErrInvalidToken
+		case errors.Is(err, database.ErrTokenReused):
+			return nil, ErrTokenReused
+		}
+		return nil, ErrInternal
+	}
+
+	// the claims are read again so role changes apply on refresh
+	user, err := svc.userRepo.Get(next.UserId.String())
+	if err != nil {
+		if errors.Is(err, database.ErrRecordNotFound) {
+			return nil, ErrInvalidToken
+		}
+		return nil, ErrInternal
+	}
+	return svc.tokenPair(user, nextToken)
+}
+
+// Logout revokes the refresh token along with the rest of its family. Access
+// tokens already handed out stay valid until they expire.
+func (svc *UserSvc) Logout(refreshToken string) error {
+	if err := svc.refreshTokenRepo.RevokeFamily(authentication.HashOpaqueToken(refreshToken), time.Now()); err != nil {
+		return ErrInternal
+	}
+	return nil
+}
+
+func (svc *UserSvc) tokenPair(user *models.User, refreshToken string) (*models.TokenPair, error) {
+	accessToken, err := authentication.NewJWTToken(user.ID, user.Permissions(), svc.jwtSecret, svc.accessTokenTTL)
+	if err != nil {
+		return nil, ErrInternal
+	}
+	return &models.TokenPair{
+		AccessToken:  accessToken,
+		RefreshToken: refreshToken,
+		ExpiresIn:    svc.accessTokenTTL,
+	}, nil
 }
 
 func (svc *UserSvc) GetUser(id uuid.UUID) (*models.User, error) {
@@ -0,0 +1,103 @@
+package services
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rezbow/ecommerce/internal/app/models"
+	"github.com/rezbow/ecommerce/internal/platform/authentication"
+	"github.com/rezbow/ecommerce/internal/platform/database"
+)
+
+func TestRefreshSessionReuseDetection(t *testing.T) {
+	db := testDB(t)
+	userRepo := database.NewUserRepo(db)
+
+	passwordHash, err := authentication.HashPassword("Correct-Horse9")
+	if err != nil {
+		t.Fatalf("hashing password: %v", err)
+	}
+	user := &models.User{Email: "refresh-" + uuid.NewString() + "@example.com", PasswordHash: passwordHash}
+	if err := userRepo.Create(user); err != nil {
+		t.Fatalf("creating user: %v", err)
+	}
+	t.Cleanup(func() {
+		db.Where("user_id = ?", user.ID).Delete(&models.RefreshToken{})
+		db.Delete(&models.User{}, "id = ?", user.ID)
+	})
+
+	svc := NewUserService(userRepo, database.NewRefreshTokenRepo(db), nil, "jwt secret", time.Minute, time.Hour)
+	login := func() *models.TokenPair {
+		t.Helper()
+		pair, err := svc.Authenticate(&models.Login{Email: user.Email, Password: "Correct-Horse9"})
+		if err != nil {
+			t.Fatalf("Authenticate: %v", err)
+		}
+		return pair
+	}
+	first := login()
+	otherDevice := login()
+
+	second, err := svc.RefreshSession(first.RefreshToken)
+	if err != nil {
+		t.Fatalf("RefreshSession: %v", err)
+	}
+	if second.RefreshToken == first.RefreshToken {
+		t.Fatal("RefreshSession handed the same refresh token back")
+	}
+
+	steps := []struct {
+		name  string
+		token string
+		want  error
+	}{
+		// a replayed token revokes the whole family, the latest token too
+		{name: "replayed token", token: first.RefreshToken, want: ErrTokenReused},
+		{name: "latest token of the revoked family", token: second.RefreshToken, want: ErrTokenReused},
+		{name: "unknown token", token: "not a token", want: ErrInvalidToken},
+		{name: "token of another login", token: otherDevice.RefreshToken, want: nil},
+	}
+	for _, step := range steps {
+		if _, err := svc.RefreshSession(step.token); !errors.Is(err, step.want) {
+			t.Errorf("%s: RefreshSession error is %v, want %v", step.name, err, step.want)
+		}
+	}
+
+	expiring := NewUserService(userRepo, database.NewRefreshTokenRepo(db), nil, "jwt secret", time.Minute, -time.Minute)
+	expired, err := expiring.Authenticate(&models.Login{Email: user.Email, Password: "Correct-Horse9"})
+	if err != nil {
+		t.Fatalf("Authenticate: %v", err)
+	}
+	if _, err := svc.RefreshSession(expired.RefreshToken); !errors.Is(err, ErrInvalidToken) {
+		t.Errorf("expired token: RefreshSession error is %v, want %v", err, ErrInvalidToken)
+	}
+}
+
+type fakeRefreshTokenRepo struct {
+	database.IRefreshTokenRepo
+	rotateErr error
+}
+
+func (repo *fakeRefreshTokenRepo) Rotate(string, *models.RefreshToken, time.Time) error {
+	return repo.rotateErr
+}
+
+func TestRefreshSessionErrors(t *testing.T) {
+	tests := []struct {
+		rotateErr error
+		want      error
+	}{
+		{rotateErr: database.ErrTokenReused, want: ErrTokenReused},
+		{rotateErr: database.ErrRecordNotFound, want: ErrInvalidToken},
+		{rotateErr: database.ErrExpired, want: ErrInvalidToken},
+		{rotateErr: database.ErrInternal, want: ErrInternal},
+	}
+	for _, tt := range tests {
+		svc := NewUserService(nil, &fakeRefreshTokenRepo{rotateErr: tt.rotateErr}, nil, "jwt secret", time.Minute, time.Hour)
+		if _, err := svc.RefreshSession("token"); !errors.Is(err, tt.want) {
+			t.Errorf("rotating failed with %v, RefreshSession error is %v, want %v", tt.rotateErr, err, tt.want)
+		}
+	}
+}
@@ -10,20 +10,35 @@ import (
 )
 
 type UserClaims struct {
-	UserId  uuid.UUID
-	IsAdmin bool
+	UserId uuid.UUID
+	// Permissions are resolved from the user's roles when the token is
+	// issued
+	Permissions []string
 	jwt.RegisteredClaims
 }
 
+// HasPermission tells whether the token grants permission.
+func (c *UserClaims) HasPermission(permission string) bool {
+	for _, granted := range c.Permissions {
+		if granted == permission {
+			return true
+		}
+	}
+	return false
+}
+
+// NewJWTToken signs a short lived access token for the user that expires
+// after ttl.
 func NewJWTToken(
 	userId uuid.UUID,
-	isAdmin bool,
+	permissions []string,
 	secretKey string,
+	ttl time.Duration,
 ) (string, error) {
-	expiresAt := time.Now().Add(time.Hour * 24)
+	expiresAt := time.Now().Add(ttl)
 	claims := &UserClaims{
-		UserId:  userId,
-		IsAdmin: isAdmin,
+		UserId:      userId,
+		Permissions: permissions,
 		RegisteredClaims: jwt.RegisteredClaims{
 			ExpiresAt: jwt.NewNumericDate(expiresAt),
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
@@ -1,14 +1,52 @@
 package config
 
 import (
+	"encoding/json"
 	"errors"
+	"fmt"
 	"os"
+	"strings"
+	"time"
 
 	"github.com/joho/godotenv"
+	"github.com/rezbow/ecommerce/internal/platform/signing"
 )
 
 type Config struct {
 	JWTSecret string
+	// how long access tokens and refresh tokens are valid for
+	AccessTokenTTL  time.Duration
+	RefreshTokenTTL time.Duration
+	// how long password reset links are valid for
+	PasswordResetTTL time.Duration
+	// how long email verification links are valid for, and how long users
+	// have to wait before asking for another one
+	EmailVerificationTTL            time.Duration
+	EmailVerificationResendInterval time.Duration
+	// public address of the shop, used for the links in emails
+	AppURL string
+	// how emails are sent, "smtp" or "log". The log mailer delivers nothing
+	// and is only meant for local development.
+	Mailer   string
+	SMTPHost string
+	SMTPPort string
+	SMTPUser string
+	SMTPPass string
+	// address emails are sent from
+	MailFrom string
+	// secret shared with the payment provider to sign webhooks
+	PaymentWebhookSecret string
+	// json file listing the available shipping methods
+	ShippingMethodsFile string
+	// json file with the tax rules
+	TaxRulesFile string
+	// json file with the policy new passwords have to satisfy
+	PasswordPolicyFile string
+	// currency of new carts that don't pick one
+	DefaultCurrency string
+	// key the pagination cursors handed out to clients are signed with,
+	// derived from JWTSecret when not set
+	CursorSecret string
 	// database
 	DBHost string
 	DBPort string
@@ -24,7 +62,20 @@ func LoadConfig() (*Config, error) {
 	}
 
 	config := Config{
-		JWTSecret: os.Getenv("JWT_SECRET"),
+		JWTSecret:            os.Getenv("JWT_SECRET"),
+		PaymentWebhookSecret: os.Getenv("PAYMENT_WEBHOOK_SECRET"),
+		ShippingMethodsFile:  os.Getenv("SHIPPING_METHODS_FILE"),
+		TaxRulesFile:         os.Getenv("TAX_RULES_FILE"),
+		PasswordPolicyFile:   os.Getenv("PASSWORD_POLICY_FILE"),
+		DefaultCurrency:      os.Getenv("DEFAULT_CURRENCY"),
+		CursorSecret:         os.Getenv("CURSOR_SECRET"),
+		AppURL:               os.Getenv("APP_URL"),
+		Mailer:               os.Getenv("MAILER"),
+		SMTPHost:             os.Getenv("SMTP_HOST"),
+		SMTPPort:             os.Getenv("SMTP_PORT"),
+		SMTPUser:             os.Getenv("SMTP_USER"),
+		SMTPPass:             os.Getenv("SMTP_PASS"),
+		MailFrom:             os.Getenv("MAIL_FROM"),
 		//
 		DBHost: os.Getenv("DB_HOST"),
 		DBPort: os.Getenv("DB_PORT"),
@@ -37,6 +88,78 @@ func LoadConfig() (*Config, error) {
 		return nil, errors.New("missing JWT_SECRET from .env")
 	}
 
+	if config.PaymentWebhookSecret == "" {
+		return nil, errors.New("missing PAYMENT_WEBHOOK_SECRET from .env")
+	}
+
+	config.AccessTokenTTL, err = durationEnv("ACCESS_TOKEN_TTL", 15*time.Minute)
+	if err != nil {
+		return nil, err
+	}
+	config.RefreshTokenTTL, err = durationEnv("REFRESH_TOKEN_TTL", 30*24*time.Hour)
+	if err != nil {
+		return nil, err
+	}
+
+	config.PasswordResetTTL, err = durationEnv("PASSWORD_RESET_TTL", 30*time.Minute)
+	if err != nil {
+		return nil, err
+	}
+
+	config.EmailVerificationTTL, err = durationEnv("EMAIL_VERIFICATION_TTL", 48*time.Hour)
+	if err != nil {
+		return nil, err
+	}
+	config.EmailVerificationResendInterval, err = durationEnv("EMAIL_VERIFICATION_RESEND_INTERVAL", time.Minute)
+	if err != nil {
+		return nil, err
+	}
+
+	if config.CursorSecret == "" {
+		config.CursorSecret = deriveCursorSecret(config.JWTSecret)
+	}
+
+	if config.AppURL == "" {
+		config.AppURL = "http://localhost:8080"
+	}
+	config.AppURL = strings.TrimRight(config.AppURL, "/")
+
+	if config.Mailer == "" {
+		config.Mailer = "smtp"
+	}
+	switch config.Mailer {
+	case "smtp":
+		if config.SMTPHost == "" {
+			return nil, errors.New("missing SMTP_HOST from .env, set MAILER=log to not send emails in development")
+		}
+		if config.MailFrom == "" {
+			return nil, errors.New("missing MAIL_FROM from .env")
+		}
+		if config.SMTPPort == "" {
+			config.SMTPPort = "587"
+		}
+	case "log":
+	default:
+		return nil, errors.New("MAILER must be smtp or log")
+	}
+
+	if config.ShippingMethodsFile == "" {
+		config.ShippingMethodsFile = "config/shipping_methods.json"
+	}
+	if config.TaxRulesFile == "" {
+		config.TaxRulesFile = "config/tax_rules.json"
+	}
+	if config.PasswordPolicyFile == "" {
+		config.PasswordPolicyFile = "config/password_policy.json"
+	}
+	if config.DefaultCurrency == "" {
+		config.DefaultCurrency = "USD"
+	}
+	config.DefaultCurrency = strings.ToUpper(config.DefaultCurrency)
+	if len(config.DefaultCurrency) != 3 {
+		return nil, errors.New("DEFAULT_CURRENCY must be a three letter code")
+	}
+
 	if config.DBHost == "" {
 		config.DBHost = "localhost"
 	}
@@ -55,3 +178,55 @@ func LoadConfig() (*Config, error) {
 
 	return &config, nil
 }
+
+// deriveCursorSecret derives the key cursors are signed with when none is
+// configured. Cursors must not be signed with the JWT key itself, anyone
+// could then turn a signed cursor into a forged token or the other way
+// around.
+func deriveCursorSecret(jwtSecret string) string {
+	return signing.Sign([]byte(jwtSecret), []byte("cursor"))
+}
+
+// durationEnv parses the environment variable key as a duration, like
+// "15m", falling back to def when it is not set.
+func durationEnv(key string, def time.Duration) (time.Duration, error) {
+	value := os.Getenv(key)
+	if value == "" {
+		return def, nil
+	}
+	duration, err := time.ParseDuration(value)
+	if err != nil || duration <= 0 {
+		return 0, fmt.Errorf("%s must be a positive duration like 15m", key)
+	}
+	return duration, nil
+}
+
+// LoadJSONFile decodes the json file at path into v.
+func LoadJSONFile(path string, v any) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("reading %s: %w", path, err)
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		return fmt.Errorf("decoding %s: %w", path, err)
+	}
+	return nil
+}
+
+// LoadLines reads the non empty lines of the file at path, lines starting
+// with # are comments.
+func LoadLines(path string) ([]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading %s: %w", path, err)
+	}
+	lines := make([]string, 0)
+	for _, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		lines = append(lines, line)
+	}
+	return lines, nil
+}
@@ -0,0 +1,16 @@
+package config
+
+import "testing"
+
+func TestDeriveCursorSecret(t *testing.T) {
+	secret := deriveCursorSecret("jwt secret")
+	if secret == "" || secret == "jwt secret" {
+		t.Fatalf("cursor secret is %q, want a key derived from the JWT secret", secret)
+	}
+	if again := deriveCursorSecret("jwt secret"); again != secret {
+		t.Errorf("cursor secret changed between calls: %q and %q", secret, again)
+	}
+	if other := deriveCursorSecret("other jwt secret"); other == secret {
+		t.Errorf("different JWT secrets derive the same cursor secret %q", secret)
+	}
+}
@@ -0,0 +1,122 @@
+package database
+
+import (
+	"errors"
+
+	"github.com/google/uuid"
+	"github.com/rezbow/ecommerce/internal/app/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type IAddressRepo interface {
+	Create(*models.Address) error
+	GetUserAddress(uuid.UUID, uuid.UUID) (*models.Address, error)
+	GetUserDefault(uuid.UUID) (*models.Address, error)
+	GetUserAddresses(uuid.UUID) ([]models.Address, error)
+	Update(uuid.UUID, uuid.UUID, map[string]any) (*models.Address, error)
+	Delete(uuid.UUID, uuid.UUID) error
+}
+
+type AddressRepo struct {
+	db *gorm.DB
+}
+
+func NewAddressRepo(db *gorm.DB) *AddressRepo {
+	return &AddressRepo{db: db}
+}
+
+// Create stores the address, a default address takes the flag over from the
+// user's previous default.
+func (repo *AddressRepo) Create(address *models.Address) error {
+	address.ID = uuid.New()
+	err := repo.db.Transaction(func(tx *gorm.DB) error {
+		if address.IsDefault {
+			if err := clearDefaultAddress(tx, address.UserId); err != nil {
+				return err
+			}
+		}
+		return tx.Create(address).Error
+	})
+	if err != nil {
+		return ErrInternal
+	}
+	return nil
+}
+
+func (repo *AddressRepo) GetUserAddress(id uuid.UUID, userId uuid.UUID) (*models.Address, error) {
+	var address models.Address
+	if err := repo.db.First(&address, "id = ? AND user_id = ?", id, userId).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrRecordNotFound
+		}
+		return nil, ErrInternal
+	}
+	return &address, nil
+}
+
+func (repo *AddressRepo) GetUserDefault(userId uuid.UUID) (*models.Address, error) {
+	var address models.Address
+	if err := repo.db.First(&address, "user_id = ? AND is_default", userId).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrRecordNotFound
+		}
+		return nil, ErrInternal
+	}
+	return &address, nil
+}
+
+func (repo *AddressRepo) GetUserAddresses(userId uuid.UUID) ([]models.Address, error) {
+	var addresses []models.Address
+	err := repo.db.Where("user_id = ?", userId).Order("is_default DESC, created_at ASC").Find(&addresses).Error
+	if err != nil {
+		return nil, ErrInternal
+	}
+	return addresses, nil
+}
+
+func (repo *AddressRepo) Update(id uuid.UUID, userId uuid.UUID, updatedColumns map[string]any) (*models.Address, error) {
+	var address models.Address
+	err := repo.db.Transaction(func(tx *gorm.DB) error {
+		if isDefault, ok := updatedColumns["is_default"].(bool); ok && isDefault {
+			if err := clearDefaultAddress(tx, userId); err != nil {
+				return err
+			}
+		}
+		result := tx.Model(&address).
+			Clauses(clause.Returning{}).
+			Where("id = ? AND user_id = ?", id, userId).
+			Updates(updatedColumns)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrRecordNotFound
+		}
+		return nil
+	})
+	if err != nil {
+		if errors.Is(err, ErrRecordNotFound) {
+			return nil, ErrRecordNotFound
+		}
+		return nil, ErrInternal
+	}
+	return &address, nil
+}
+
+func (repo *AddressRepo) Delete(id uuid.UUID, userId uuid.UUID) error {
+	result := repo.db.Delete(&models.Address{}, "id = ? AND user_id = ?", id, userId)
+	if result.Error != nil {
+		return ErrInternal
+	}
+	if result.RowsAffected == 0 {
+		return ErrRecordNotFound
+	}
+	return nil
+}
+
+func clearDefaultAddress(tx *gorm.DB, userId uuid.UUID) error {
+	return tx.Model(&models.Address{}).
+		Where("user_id = ? AND is_default", userId).
+		Update("is_default", false).Error
+}
@@ -6,11 +6,13 @@ import (
 	"time"
 
 	"github.com/go-redis/redis/v8"
+	"github.com/google/uuid"
 	"github.com/rezbow/ecommerce/internal/app/models"
 )
 
 type ICartRepo interface {
 	Get(string) (*models.Cart, error)
+	GetLegacy(string, string) (*models.Cart, error)
 	Save(string, *models.Cart, time.Duration) error
 	Delete(string) error
 }
@@ -42,6 +44,45 @@ func (repo *CartRepoRedis) Get(key string) (*models.Cart, error) {
 	return &cart, nil
 }
 
+// legacyCart is the part of a cart saved before prices carried a currency
+// that is still of use, its lines.
+type legacyCart struct {
+	UserId uuid.UUID `json:"user_id"`
+	Items  []struct {
+		ProductId uuid.UUID `json:"product_id"`
+		Quantity  int       `json:"quantity"`
+	} `json:"items"`
+}
+
+// GetLegacy reads a cart saved before prices carried a currency. Only its
+// lines are kept, in currency and without prices, they have to be synced
+// before the cart is shown.
+func (repo *CartRepoRedis) GetLegacy(key string, currency string) (*models.Cart, error) {
+	value, err := repo.client.Get(context.Background(), key).Result()
+	if err == redis.Nil {
+		return nil, ErrRecordNotFound
+	}
+	if err != nil {
+		return nil, ErrInternal
+	}
+
+	var legacy legacyCart
+	if err := json.Unmarshal([]byte(value), &legacy); err != nil {
+		return nil, ErrInternal
+	}
+	cart := models.NewCart(legacy.UserId, currency)
+	items := make([]*models.CartItem, 0, len(legacy.Items))
+	for _, legacyItem := range legacy.Items {
+		item := models.NewCartItem(legacyItem.ProductId, nil)
+		item.Quantity = legacyItem.Quantity
+		item.Price = models.Zero(currency)
+		item.SubTotal = models.Zero(currency)
+		items = append(items, item)
+	}
+	cart.SetItems(items)
+	return cart, nil
+}
+
 func (repo *CartRepoRedis) Save(key string, cart *models.Cart, exp time.Duration) error {
 	value, err := json.Marshal(cart)
 	if err != nil {
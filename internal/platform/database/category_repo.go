@@ -0,0 +1,128 @@
+package database
+
+import (
+	"errors"
+
+	"github.com/google/uuid"
+	"github.com/rezbow/ecommerce/internal/app/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type ICategoryRepo interface {
+	GetAll() ([]models.Category, error)
+	Get(uuid.UUID) (*models.Category, error)
+	GetBySlug(string) (*models.Category, error)
+	GetSubtreeIds(uuid.UUID) ([]uuid.UUID, error)
+	Create(*models.Category) error
+	Update(uuid.UUID, map[string]any) (*models.Category, error)
+	Delete(uuid.UUID) error
+}
+
+type CategoryRepo struct {
+	db *gorm.DB
+}
+
+func NewCategoryRepo(db *gorm.DB) *CategoryRepo {
+	return &CategoryRepo{db: db}
+}
+
+func (repo *CategoryRepo) GetAll() ([]models.Category, error) {
+	var categories []models.Category
+	if err := repo.db.Order("name ASC").Find(&categories).Error; err != nil {
+		return nil, ErrInternal
+	}
+	return categories, nil
+}
+
+func (repo *CategoryRepo) Get(id uuid.UUID) (*models.Category, error) {
+	var category models.Category
+	if err := repo.db.First(&category, "id = ?", id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrRecordNotFound
+		}
+		return nil, ErrInternal
+	}
+	return &category, nil
+}
+
+func (repo *CategoryRepo) GetBySlug(slug string) (*models.Category, error) {
+	var category models.Category
+	if err := repo.db.First(&category, "slug = ?", slug).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrRecordNotFound
+		}
+		return nil, ErrInternal
+	}
+	return &category, nil
+}
+
+// GetSubtreeIds returns the id of the category followed by the ids of all
+// its descendants.
+func (repo *CategoryRepo) GetSubtreeIds(id uuid.UUID) ([]uuid.UUID, error) {
+	var ids []uuid.UUID
+	err := repo.db.Raw(`
+		WITH RECURSIVE subtree AS (
+			SELECT id FROM categories WHERE id = ?
+			UNION
+			SELECT c.id FROM categories c JOIN subtree s ON c.parent_id = s.id
+		)
+		SELECT id FROM subtree`, id).Scan(&ids).Error
+	if err != nil {
+		return nil, ErrInternal
+	}
+	if len(ids) == 0 {
+		return nil, ErrRecordNotFound
+	}
+	return ids, nil
+}
+
+func (repo *CategoryRepo) Create(category *models.Category) error {
+	category.ID = uuid.New()
+	if err := repo.db.Create(category).Error; err != nil {
+		if errors.Is(err, gorm.ErrDuplicatedKey) {
+			return ErrDuplicateKey
+		}
+		if errors.Is(err, gorm.ErrForeignKeyViolated) {
+			return ErrForeignKeyViolation
+		}
+		return ErrInternal
+	}
+	return nil
+}
+
+func (repo *CategoryRepo) Update(id uuid.UUID, updatedColumns map[string]any) (*models.Category, error) {
+	category := models.Category{
+		ID: id,
+	}
+	result := repo.db.Model(&category).Clauses(clause.Returning{}).Updates(updatedColumns)
+	if result.Error != nil {
+		if errors.Is(result.Error, gorm.ErrDuplicatedKey) {
+			return nil, ErrDuplicateKey
+		}
+		if errors.Is(result.Error, gorm.ErrForeignKeyViolated) {
+			return nil, ErrForeignKeyViolation
+		}
+		return nil, ErrInternal
+	}
+	if result.RowsAffected == 0 {
+		return nil, ErrRecordNotFound
+	}
+	return &category, nil
+}
+
+// Delete removes the category and its product assignments. It fails with
+// ErrForeignKeyViolation while the category still has subcategories.
+func (repo *CategoryRepo) Delete(id uuid.UUID) error {
+	result := repo.db.Delete(&models.Category{}, "id = ?", id)
+	if result.Error != nil {
+		if errors.Is(result.Error, gorm.ErrForeignKeyViolated) {
+			return ErrForeignKeyViolation
+		}
+		return ErrInternal
+	}
+	if result.RowsAffected == 0 {
+		return ErrRecordNotFound
+	}
+	return nil
+}
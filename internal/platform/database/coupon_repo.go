@@ -0,0 +1,133 @@
+package database
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rezbow/ecommerce/internal/app/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type ICouponRepo interface {
+	Create(*models.Coupon) error
+	GetByCode(string) (*models.Coupon, error)
+	GetPaged(*models.Pagination) ([]models.Coupon, error)
+	CountUserRedemptions(uuid.UUID, uuid.UUID) (int64, error)
+}
+
+type CouponRepo struct {
+	db *gorm.DB
+}
+
+func NewCouponRepo(db *gorm.DB) *CouponRepo {
+	return &CouponRepo{db: db}
+}
+
+func (repo *CouponRepo) Create(coupon *models.Coupon) error {
+	coupon.ID = uuid.New()
+	if err := repo.db.Create(coupon).Error; err != nil {
+		if errors.Is(err, gorm.ErrDuplicatedKey) {
+			return ErrDuplicateKey
+		}
+		return ErrInternal
+	}
+	return nil
+}
+
+func (repo *CouponRepo) GetByCode(code string) (*models.Coupon, error) {
+	var coupon models.Coupon
+	if err := repo.db.First(&coupon, "code = ?", code).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrRecordNotFound
+		}
+		return nil, ErrInternal
+	}
+	return &coupon, nil
+}
+
+func (repo *CouponRepo) GetPaged(pagination *models.Pagination) ([]models.Coupon, error) {
+	var coupons []models.Coupon
+	err := repo.db.Order("created_at DESC").
+		Offset(pagination.Offset).
+		Limit(pagination.Limit).
+		Find(&coupons).Error
+	if err != nil {
+		return nil, ErrInternal
+	}
+	return coupons, nil
+}
+
+func (repo *CouponRepo) CountUserRedemptions(couponId uuid.UUID, userId uuid.UUID) (int64, error) {
+	var count int64
+	err := repo.db.Model(&models.CouponRedemption{}).
+		Where("coupon_id = ? AND user_id = ?", couponId, userId).
+		Count(&count).Error
+	if err != nil {
+		return 0, ErrInternal
+	}
+	return count, nil
+}
+
+// redeemCoupon counts the order's use of its coupon. The coupon row stays
+// locked until the transaction ends, so concurrent checkouts can't use it
+// past its limits. It fails with ErrLimitReached when the coupon can't be
+// used anymore.
+func redeemCoupon(tx *gorm.DB, order *models.Order, now time.Time) error {
+	var coupon models.Coupon
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&coupon, "id = ?", *order.CouponId).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrLimitReached
+		}
+		return err
+	}
+	if !coupon.ActiveAt(now) {
+		return ErrLimitReached
+	}
+	if coupon.MaxRedemptions != nil && coupon.Redemptions >= *coupon.MaxRedemptions {
+		return ErrLimitReached
+	}
+	if coupon.MaxRedemptionsPerUser != nil {
+		var used int64
+		err := tx.Model(&models.CouponRedemption{}).
+			Where("coupon_id = ? AND user_id = ?", coupon.ID, order.UserId).
+			Count(&used).Error
+		if err != nil {
+			return err
+		}
+		if used >= int64(*coupon.MaxRedemptionsPerUser) {
+			return ErrLimitReached
+		}
+	}
+
+	err = tx.Model(&models.Coupon{}).
+		Where("id = ?", coupon.ID).
+		Update("redemptions", gorm.Expr("redemptions + 1")).Error
+	if err != nil {
+		return err
+	}
+	redemption := models.CouponRedemption{
+		ID:       uuid.New(),
+		CouponId: coupon.ID,
+		UserId:   order.UserId,
+		OrderId:  order.ID,
+	}
+	return tx.Create(&redemption).Error
+}
+
+// releaseCouponRedemption gives the coupon use of a cancelled order back.
+func releaseCouponRedemption(tx *gorm.DB, orderId uuid.UUID) error {
+	var redemption models.CouponRedemption
+	result := tx.Clauses(clause.Returning{}).Where("order_id = ?", orderId).Delete(&redemption)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return nil
+	}
+	return tx.Model(&models.Coupon{}).
+		Where("id = ?", redemption.CouponId).
+		Update("redemptions", gorm.Expr("redemptions - 1")).Error
+}
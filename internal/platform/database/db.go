@@ -3,6 +3,7 @@ package database
 import (
 	"fmt"
 
+	"github.com/rezbow/ecommerce/internal/app/models"
 	"github.com/rezbow/ecommerce/internal/platform/config"
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
@@ -16,3 +17,12 @@ func ConnectDB(cfg *config.Config) (*gorm.DB, error) {
 	})
 	return db, err
 }
+
+// pageOffset is how many rows to skip for the page, none when the page
+// starts after a cursor.
+func pageOffset(pagination *models.Pagination) int {
+	if pagination.After != nil {
+		return 0
+	}
+	return pagination.Offset
+}
@@ -0,0 +1,93 @@
+package database
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rezbow/ecommerce/internal/app/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type IEmailVerificationRepo interface {
+	Create(*models.EmailVerificationToken) error
+	GetLatest(uuid.UUID) (*models.EmailVerificationToken, error)
+	Verify(string, time.Time) error
+}
+
+type EmailVerificationRepo struct {
+	db *gorm.DB
+}
+
+func NewEmailVerificationRepo(db *gorm.DB) *EmailVerificationRepo {
+	return &EmailVerificationRepo{db: db}
+}
+
+// Create stores the token, dropping every token sent to the user before so
+// only the latest email works.
+func (repo *EmailVerificationRepo) Create(token *models.EmailVerificationToken) error {
+	token.ID = uuid.New()
+	err := repo.db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Where("user_id = ?", token.UserId).
+			Delete(&models.EmailVerificationToken{}).Error
+		if err != nil {
+			return err
+		}
+		return tx.Create(token).Error
+	})
+	if err != nil {
+		return ErrInternal
+	}
+	return nil
+}
+
+// GetLatest returns the last token sent to the user.
+func (repo *EmailVerificationRepo) GetLatest(userId uuid.UUID) (*models.EmailVerificationToken, error) {
+	var token models.EmailVerificationToken
+	err := repo.db.Where("user_id = ?", userId).
+		Order("created_at DESC").
+		First(&token).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrRecordNotFound
+		}
+		return nil, ErrInternal
+	}
+	return &token, nil
+}
+
+// Verify marks the email of the user the token stored under hash was sent
+// to as verified and uses the token up. It fails with ErrRecordNotFound for
+// unknown tokens and with ErrExpired for expired ones.
+func (repo *EmailVerificationRepo) Verify(hash string, now time.Time) error {
+	err := repo.db.Transaction(func(tx *gorm.DB) error {
+		var token models.EmailVerificationToken
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&token, "token_hash = ?", hash).Error
+		if err != nil {
+			return err
+		}
+		if !now.Before(token.ExpiresAt) {
+			return ErrExpired
+		}
+
+		err = tx.Model(&models.User{}).
+			Where("id = ? AND email_verified_at IS NULL", token.UserId).
+			Updates(map[string]any{"email_verified_at": now, "updated_at": now}).Error
+		if err != nil {
+			return err
+		}
+		return tx.Where("user_id = ?", token.UserId).
+			Delete(&models.EmailVerificationToken{}).Error
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			return ErrRecordNotFound
+		case errors.Is(err, ErrExpired):
+			return ErrExpired
+		}
+		return ErrInternal
+	}
+	return nil
+}
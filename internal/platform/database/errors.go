@@ -9,4 +9,10 @@ var (
 	ErrForeignKeyViolation = errors.New("foreign key violation")
 	ErrDuplicateKey        = errors.New("unique key violation")
 	ErrInternal            = errors.New("internal database error")
+	ErrStaleRecord         = errors.New("record was modified concurrently")
+	ErrInsufficientStock   = errors.New("insufficient stock")
+	ErrLimitReached        = errors.New("usage limit reached")
+	ErrExpired             = errors.New("record has expired")
+	ErrTokenReused         = errors.New("token was already used")
+	ErrLastHolder          = errors.New("record is the last holder of a role")
 )
@@ -1 +1,161 @@
 package database
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rezbow/ecommerce/internal/app/models"
+	"gorm.io/gorm"
+)
+
+type IOrderRepo interface {
+	Create(*models.Order, time.Time) error
+	GetUserOrder(uuid.UUID, uuid.UUID) (*models.Order, error)
+	GetUserOrdersPaged(uuid.UUID, *models.Pagination) ([]models.Order, error)
+	Get(uuid.UUID) (*models.Order, error)
+	UpdateStatus(*models.Order, *models.OrderStatusChange) error
+	GetStatusHistory(uuid.UUID) ([]models.OrderStatusChange, error)
+}
+
+type OrderRepo struct {
+	db *gorm.DB
+}
+
+func NewOrderRepo(db *gorm.DB) *OrderRepo {
+	return &OrderRepo{db: db}
+}
+
+// Create places the order, holds stock for every item until holdUntil and
+// redeems the order's coupon, all in a single transaction. It fails with
+// ErrInsufficientStock when any product no longer has enough stock and with
+// ErrLimitReached when the coupon can't be used anymore.
+func (repo *OrderRepo) Create(order *models.Order, holdUntil time.Time) error {
+	order.ID = uuid.New()
+	for idx := range order.Items {
+		order.Items[idx].ID = uuid.New()
+		order.Items[idx].OrderId = order.ID
+	}
+
+	err := repo.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit("Items").Create(order).Error; err != nil {
+			return err
+		}
+		if err := tx.Create(&order.Items).Error; err != nil {
+			return err
+		}
+		if err := holdStock(tx, order, holdUntil); err != nil {
+			return err
+		}
+		if order.CouponId != nil {
+			return redeemCoupon(tx, order, time.Now())
+		}
+		return nil
+	})
+	if err != nil {
+		if errors.Is(err, ErrInsufficientStock) {
+			return ErrInsufficientStock
+		}
+		if errors.Is(err, ErrLimitReached) {
+			return ErrLimitReached
+		}
+		return ErrInternal
+	}
+	return nil
+}
+
+// GetUserOrder only returns the order if it belongs to the given user.
+func (repo *OrderRepo) GetUserOrder(id uuid.UUID, userId uuid.UUID) (*models.Order, error) {
+	var order models.Order
+	err := repo.db.Preload("Items").First(&order, "id = ? AND user_id = ?", id, userId).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrRecordNotFound
+		}
+		return nil, ErrInternal
+	}
+	return &order, nil
+}
+
+func (repo *OrderRepo) GetUserOrdersPaged(userId uuid.UUID, pagination *models.Pagination) ([]models.Order, error) {
+	query := repo.db.Where("user_id = ?", userId)
+	if pagination.After != nil {
+		query = query.Where("(created_at, id) < (?, ?)", pagination.After.CreatedAt, pagination.After.ID)
+	}
+
+	var orders []models.Order
+	err := query.Preload("Items").
+		Order("created_at DESC, id DESC").
+		Offset(pageOffset(pagination)).
+		Limit(pagination.Limit).
+		Find(&orders).Error
+	if err != nil {
+		return nil, ErrInternal
+	}
+	return orders, nil
+}
+
+func (repo *OrderRepo) Get(id uuid.UUID) (*models.Order, error) {
+	var order models.Order
+	if err := repo.db.Preload("Items").First(&order, "id = ?", id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrRecordNotFound
+		}
+		return nil, ErrInternal
+	}
+	return &order, nil
+}
+
+// UpdateStatus moves the order from change.FromStatus to change.ToStatus and
+// records the change. Paying an order commits its stock holds and cancelling
+// it releases them along with its coupon use, within the same transaction. It fails with
+// ErrStaleRecord when the order is no longer in change.FromStatus.
+func (repo *OrderRepo) UpdateStatus(order *models.Order, change *models.OrderStatusChange) error {
+	change.ID = uuid.New()
+	change.OrderId = order.ID
+	err := repo.db.Transaction(func(tx *gorm.DB) error {
+		if err := updateOrderStatus(tx, change); err != nil {
+			return err
+		}
+		switch change.ToStatus {
+		case models.OrderStatusPaid:
+			return commitReservations(tx, order.ID)
+		case models.OrderStatusCancelled:
+			if err := releaseReservations(tx, order.ID); err != nil {
+				return err
+			}
+			return releaseCouponRedemption(tx, order.ID)
+		}
+		return nil
+	})
+	if err != nil {
+		if errors.Is(err, ErrStaleRecord) {
+			return ErrStaleRecord
+		}
+		return ErrInternal
+	}
+	order.Status = change.ToStatus
+	return nil
+}
+
+func (repo *OrderRepo) GetStatusHistory(orderId uuid.UUID) ([]models.OrderStatusChange, error) {
+	var changes []models.OrderStatusChange
+	err := repo.db.Where("order_id = ?", orderId).Order("created_at ASC").Find(&changes).Error
+	if err != nil {
+		return nil, ErrInternal
+	}
+	return changes, nil
+}
+
+func updateOrderStatus(tx *gorm.DB, change *models.OrderStatusChange) error {
+	result := tx.Model(&models.Order{}).
+		Where("id = ? AND status = ?", change.OrderId, change.FromStatus).
+		Update("status", change.ToStatus)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrStaleRecord
+	}
+	return tx.Create(change).Error
+}
@@ -0,0 +1,86 @@
+package database
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rezbow/ecommerce/internal/app/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type IPasswordResetRepo interface {
+	Create(*models.PasswordResetToken) error
+	ResetPassword(string, string, time.Time) error
+}
+
+type PasswordResetRepo struct {
+	db *gorm.DB
+}
+
+func NewPasswordResetRepo(db *gorm.DB) *PasswordResetRepo {
+	return &PasswordResetRepo{db: db}
+}
+
+// Create stores the token, dropping every unused token the user asked for
+// before so only the latest email works.
+func (repo *PasswordResetRepo) Create(token *models.PasswordResetToken) error {
+	token.ID = uuid.New()
+	err := repo.db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Where("user_id = ? AND used_at IS NULL", token.UserId).
+			Delete(&models.PasswordResetToken{}).Error
+		if err != nil {
+			return err
+		}
+		return tx.Create(token).Error
+	})
+	if err != nil {
+		return ErrInternal
+	}
+	return nil
+}
+
+// ResetPassword sets passwordHash as the password of the user the token
+// stored under hash was issued to, uses up the token and revokes every
+// session of the user. It fails with ErrRecordNotFound for unknown or used
+// tokens and with ErrExpired for expired ones.
+func (repo *PasswordResetRepo) ResetPassword(hash string, passwordHash string, now time.Time) error {
+	err := repo.db.Transaction(func(tx *gorm.DB) error {
+		var token models.PasswordResetToken
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&token, "token_hash = ?", hash).Error
+		if err != nil {
+			return err
+		}
+		if token.UsedAt != nil {
+			return gorm.ErrRecordNotFound
+		}
+		if !now.Before(token.ExpiresAt) {
+			return ErrExpired
+		}
+
+		err = tx.Model(&models.User{}).
+			Where("id = ?", token.UserId).
+			Updates(map[string]any{"password_hash": passwordHash, "updated_at": now}).Error
+		if err != nil {
+			return err
+		}
+		err = tx.Model(&models.PasswordResetToken{}).
+			Where("id = ?", token.ID).
+			Update("used_at", now).Error
+		if err != nil {
+			return err
+		}
+		return revokeUserTokens(tx, token.UserId, now)
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			return ErrRecordNotFound
+		case errors.Is(err, ErrExpired):
+			return ErrExpired
+		}
+		return ErrInternal
+	}
+	return nil
+}
@@ -0,0 +1,68 @@
+package database
+
+import (
+	"errors"
+
+	"github.com/google/uuid"
+	"github.com/rezbow/ecommerce/internal/app/models"
+	"gorm.io/gorm"
+)
+
+type IPaymentRepo interface {
+	Create(*models.Payment) error
+	Update(*models.Payment) error
+	GetByOrder(uuid.UUID) ([]models.Payment, error)
+	GetByReference(string, string) (*models.Payment, error)
+}
+
+type PaymentRepo struct {
+	db *gorm.DB
+}
+
+func NewPaymentRepo(db *gorm.DB) *PaymentRepo {
+	return &PaymentRepo{db: db}
+}
+
+func (repo *PaymentRepo) Create(payment *models.Payment) error {
+	payment.ID = uuid.New()
+	if err := repo.db.Create(payment).Error; err != nil {
+		if errors.Is(err, gorm.ErrForeignKeyViolated) {
+			return ErrForeignKeyViolation
+		}
+		return ErrInternal
+	}
+	return nil
+}
+
+func (repo *PaymentRepo) Update(payment *models.Payment) error {
+	result := repo.db.Model(payment).Select("provider_reference", "refunded_amount", "refunded_currency", "status", "failure_reason").Updates(payment)
+	if result.Error != nil {
+		return ErrInternal
+	}
+	if result.RowsAffected == 0 {
+		return ErrRecordNotFound
+	}
+	return nil
+}
+
+// GetByOrder returns every payment attempt of the order, newest first.
+func (repo *PaymentRepo) GetByOrder(orderId uuid.UUID) ([]models.Payment, error) {
+	var payments []models.Payment
+	err := repo.db.Where("order_id = ?", orderId).Order("created_at DESC").Find(&payments).Error
+	if err != nil {
+		return nil, ErrInternal
+	}
+	return payments, nil
+}
+
+func (repo *PaymentRepo) GetByReference(provider string, reference string) (*models.Payment, error) {
+	var payment models.Payment
+	err := repo.db.First(&payment, "provider = ? AND provider_reference = ?", provider, reference).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrRecordNotFound
+		}
+		return nil, ErrInternal
+	}
+	return &payment, nil
+}
@@ -11,9 +11,13 @@ import (
 
 type IProductRepo interface {
 	Get(uuid.UUID) (*models.Product, error)
-	GetPaged(*models.Pagination) ([]models.Product, error)
+	GetPaged(*models.ProductFilter, *models.Pagination) ([]models.Product, int64, error)
 	Create(*models.Product) error
 	Update(uuid.UUID, map[string]any) (*models.Product, error)
+	ReplacePrices(uuid.UUID, []models.ProductPrice) error
+	ReplaceCategories(uuid.UUID, []uuid.UUID) error
+	ReplaceOptions(uuid.UUID, []models.ProductOption) error
+	Search(string, *models.Pagination) ([]models.ProductSearchHit, error)
 }
 
 type ProductRepo struct {
@@ -26,7 +30,7 @@ func NewProductRepo(db *gorm.DB) *ProductRepo {
 
 func (repo *ProductRepo) Get(id uuid.UUID) (*models.Product, error) {
 	var product models.Product
-	if err := repo.db.First(&product, "id = ?", id).Error; err != nil {
+	if err := withProductDetails(repo.db).First(&product, "id = ?", id).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return nil, ErrRecordNotFound
 		}
@@ -37,19 +41,118 @@ func (repo *ProductRepo) Get(id uuid.UUID) (*models.Product, error) {
 
 func (repo *ProductRepo) Create(product *models.Product) error {
 	product.ID = uuid.New()
-	if err := repo.db.Create(product).Error; err != nil {
+	for idx := range product.Prices {
+		product.Prices[idx].ID = uuid.New()
+	}
+	// categories are only referenced, never created along with the product
+	if err := repo.db.Omit("Categories.*").Create(product).Error; err != nil {
+		if errors.Is(err, gorm.ErrForeignKeyViolated) {
+			return ErrForeignKeyViolation
+		}
 		return ErrInternal
 	}
 	return nil
 }
 
-func (repo *ProductRepo) GetPaged(pagination *models.Pagination) ([]models.Product, error) {
+// GetPaged pages through the products matching the filter, along with how
+// many of them match in total.
+func (repo *ProductRepo) GetPaged(filter *models.ProductFilter, pagination *models.Pagination) ([]models.Product, int64, error) {
+	query := filterProducts(repo.db.Model(models.Product{}), filter)
+
+	var total int64
+	if err := query.Session(&gorm.Session{}).Count(&total).Error; err != nil {
+		return nil, 0, ErrInternal
+	}
+
+	// the cursor only narrows the page down, the total stays the same
+	if pagination.After != nil {
+		query = query.Where("(products.created_at, products.id) < (?, ?)", pagination.After.CreatedAt, pagination.After.ID)
+	}
+
 	var products []models.Product
-	err := repo.db.Model(models.Product{}).Offset(pagination.Offset).Limit(pagination.Limit).Find(&products).Error
+	err := withProductDetails(query).
+		Order(productOrder(filter)).
+		Offset(pageOffset(pagination)).
+		Limit(pagination.Limit).
+		Find(&products).Error
 	if err != nil {
-		return nil, ErrInternal
+		return nil, 0, ErrInternal
 	}
-	return products, nil
+	return products, total, nil
+}
+
+// productBasePriceSQL is the price of the product itself in a currency,
+// NULL when the product isn't sold in it. It takes the currency twice.
+const productBasePriceSQL = `COALESCE(
+	(SELECT pp.price_amount FROM product_prices pp WHERE pp.product_id = products.id AND pp.price_currency = ?),
+	CASE WHEN products.price_currency = ? THEN products.price_amount END)`
+
+// productPriceSQL is the lowest price the product sells for in a currency,
+// which for products with variants is the cheapest variant, a variant without
+// its own price in the currency selling at the product's. It is NULL when the
+// product isn't sold in the currency, its vars come from productPriceVars.
+const productPriceSQL = `COALESCE(
+	(SELECT MIN(CASE WHEN v.price_amount > 0 AND v.price_currency = ? THEN v.price_amount ELSE ` + productBasePriceSQL + ` END)
+		FROM product_variants v WHERE v.product_id = products.id),
+	` + productBasePriceSQL + `)`
+
+// productPriceVars returns the vars of productPriceSQL for currency,
+// followed by extra.
+func productPriceVars(currency string, extra ...any) []any {
+	return append([]any{currency, currency, currency, currency, currency}, extra...)
+}
+
+// productUnitsSoldSQL counts the units of the product in orders that were
+// paid, cancelled and unpaid orders don't count.
+const productUnitsSoldSQL = `(SELECT COALESCE(SUM(oi.quantity), 0) FROM order_items oi
+	JOIN orders o ON o.id = oi.order_id
+	WHERE oi.product_id = products.id AND o.status NOT IN ('pending', 'cancelled'))`
+
+func filterProducts(query *gorm.DB, filter *models.ProductFilter) *gorm.DB {
+	if filter.MinPrice != nil {
+		query = query.Where(productPriceSQL+" >= ?", productPriceVars(filter.Currency, *filter.MinPrice)...)
+	}
+	if filter.MaxPrice != nil {
+		query = query.Where(productPriceSQL+" <= ?", productPriceVars(filter.Currency, *filter.MaxPrice)...)
+	}
+	if filter.Sort.SortsByPrice() {
+		query = query.Where(productPriceSQL+" IS NOT NULL", productPriceVars(filter.Currency)...)
+	}
+	if filter.InStock {
+		// products with variants only keep stock on the variants
+		query = query.Where(`(EXISTS (SELECT 1 FROM product_variants v WHERE v.product_id = products.id AND v.stock_quantity > 0)
+			OR (products.stock_quantity > 0 AND NOT EXISTS (SELECT 1 FROM product_variants v WHERE v.product_id = products.id)))`)
+	}
+	if filter.CategoryIds != nil {
+		query = query.Where("products.id IN (SELECT product_id FROM product_categories WHERE category_id IN ?)", filter.CategoryIds)
+	}
+	if filter.CreatedAfter != nil {
+		query = query.Where("products.created_at > ?", *filter.CreatedAfter)
+	}
+	return query
+}
+
+// productOrder turns the whitelisted sort into its ORDER BY clause, nothing
+// from the request ever ends up in the SQL itself. Ties are broken by id so
+// pages never overlap, the default order is the one cursors point into.
+func productOrder(filter *models.ProductFilter) clause.OrderBy {
+	var expr clause.Expr
+	switch filter.Sort {
+	case models.ProductSortPrice:
+		expr = clause.Expr{SQL: productPriceSQL + " ASC", Vars: productPriceVars(filter.Currency)}
+	case models.ProductSortPriceDesc:
+		expr = clause.Expr{SQL: productPriceSQL + " DESC", Vars: productPriceVars(filter.Currency)}
+	case models.ProductSortName:
+		expr = clause.Expr{SQL: "products.name ASC"}
+	case models.ProductSortNameDesc:
+		expr = clause.Expr{SQL: "products.name DESC"}
+	case models.ProductSortPopularity:
+		expr = clause.Expr{SQL: productUnitsSoldSQL + " DESC"}
+	default:
+		return clause.OrderBy{Expression: clause.Expr{SQL: "products.created_at DESC, products.id DESC"}}
+	}
+	expr.SQL += ", products.id ASC"
+	return clause.OrderBy{Expression: expr}
 }
 
 func (repo *ProductRepo) Update(id uuid.UUID, updatedColumns map[string]any) (*models.Product, error) {
@@ -63,5 +166,226 @@ func (repo *ProductRepo) Update(id uuid.UUID, updatedColumns map[string]any) (*m
 	if result.RowsAffected == 0 {
 		return nil, ErrRecordNotFound
 	}
+	if err := repo.db.Where("product_id = ?", id).Find(&product.Prices).Error; err != nil {
+		return nil, ErrInternal
+	}
+	if err := repo.db.Model(&product).Association("Categories").Find(&product.Categories); err != nil {
+		return nil, ErrInternal
+	}
+	if err := repo.db.Where("product_id = ?", id).Order("position ASC").Find(&product.Options).Error; err != nil {
+		return nil, ErrInternal
+	}
+	if err := repo.db.Where("product_id = ?", id).Order("created_at ASC, id ASC").Find(&product.Variants).Error; err != nil {
+		return nil, ErrInternal
+	}
 	return &product, nil
 }
+
+// search headlines mark the matching words, descriptions are cut down
+// to the fragments around them
+const (
+	searchNameHeadlineOptions        = "StartSel=<mark>, StopSel=</mark>, HighlightAll=true"
+	searchDescriptionHeadlineOptions = "StartSel=<mark>, StopSel=</mark>, MaxFragments=2, MaxWords=20, MinWords=5"
+)
+
+// htmlEscapedSQL escapes the text column for HTML the way html.EscapeString
+// does, headlines are built from the escaped text so the <mark> tags are the
+// only markup in them.
+func htmlEscapedSQL(column string) string {
+	return "replace(replace(replace(replace(replace(" + column +
+		`, '&', '&amp;'), '<', '&lt;'), '>', '&gt;'), '"', '&#34;'), '''', '&#39;')`
+}
+
+// Search pages through the products matching the tsquery, best matches
+// first. The search_vector column is kept up to date by a trigger.
+func (repo *ProductRepo) Search(tsquery string, pagination *models.Pagination) ([]models.ProductSearchHit, error) {
+	var rows []struct {
+		ID                   uuid.UUID
+		Rank                 float64
+		NameHighlight        string
+		DescriptionHighlight *string
+	}
+	err := repo.db.Raw(`
+		SELECT p.id,
+			ts_rank(p.search_vector, q.query) AS rank,
+			ts_headline('english', `+htmlEscapedSQL("p.name")+`, q.query, ?) AS name_highlight,
+			ts_headline('english', `+htmlEscapedSQL("p.description")+`, q.query, ?) AS description_highlight
+		FROM products p, to_tsquery('english', ?) AS q(query)
+		WHERE p.search_vector @@ q.query
+		ORDER BY rank DESC, p.id ASC
+		OFFSET ? LIMIT ?`,
+		searchNameHeadlineOptions, searchDescriptionHeadlineOptions, tsquery,
+		pagination.Offset, pagination.Limit).
+		Scan(&rows).Error
+	if err != nil {
+		return nil, ErrInternal
+	}
+	if len(rows) == 0 {
+		return make([]models.ProductSearchHit, 0), nil
+	}
+
+	ids := make([]uuid.UUID, len(rows))
+	for idx, row := range rows {
+		ids[idx] = row.ID
+	}
+	var products []models.Product
+	if err := withProductDetails(repo.db).Where("id IN ?", ids).Find(&products).Error; err != nil {
+		return nil, ErrInternal
+	}
+	byId := make(map[uuid.UUID]models.Product, len(products))
+	for _, product := range products {
+		byId[product.ID] = product
+	}
+
+	hits := make([]models.ProductSearchHit, 0, len(rows))
+	for _, row := range rows {
+		product, ok := byId[row.ID]
+		if !ok {
+			continue
+		}
+		hits = append(hits, models.ProductSearchHit{
+			Product:              product,
+			Rank:                 row.Rank,
+			NameHighlight:        row.NameHighlight,
+			DescriptionHighlight: row.DescriptionHighlight,
+		})
+	}
+	return hits, nil
+}
+
+// withProductDetails loads everything a product is sold with along with it.
+func withProductDetails(db *gorm.DB) *gorm.DB {
+	return db.Preload("Prices").
+		Preload("Categories").
+		Preload("Options", func(db *gorm.DB) *gorm.DB {
+			return db.Order("position ASC")
+		}).
+		Preload("Variants", func(db *gorm.DB) *gorm.DB {
+			return db.Order("created_at ASC, id ASC")
+		})
+}
+
+// ReplacePrices swaps every price override of the product for prices in a
+// single transaction.
+func (repo *ProductRepo) ReplacePrices(productId uuid.UUID, prices []models.ProductPrice) error {
+	for idx := range prices {
+		prices[idx].ID = uuid.New()
+		prices[idx].ProductId = productId
+	}
+	err := repo.db.Transaction(func(tx *gorm.DB) error {
+		var product models.Product
+		if err := tx.Select("id").First(&product, "id = ?", productId).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("product_id = ?", productId).Delete(&models.ProductPrice{}).Error; err != nil {
+			return err
+		}
+		if len(prices) == 0 {
+			return nil
+		}
+		return tx.Create(&prices).Error
+	})
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrRecordNotFound
+		}
+		return ErrInternal
+	}
+	return nil
+}
+
+// ReplaceCategories swaps every category assignment of the product for
+// categoryIds in a single transaction. It fails with ErrForeignKeyViolation
+// when one of the categories doesn't exist.
+func (repo *ProductRepo) ReplaceCategories(productId uuid.UUID, categoryIds []uuid.UUID) error {
+	err := repo.db.Transaction(func(tx *gorm.DB) error {
+		var product models.Product
+		if err := tx.Select("id").First(&product, "id = ?", productId).Error; err != nil {
+			return err
+		}
+		if err := tx.Exec("DELETE FROM product_categories WHERE product_id = ?", productId).Error; err != nil {
+			return err
+		}
+		for _, categoryId := range categoryIds {
+			err := tx.Exec("INSERT INTO product_categories (product_id, category_id) VALUES (?, ?)", productId, categoryId).Error
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrRecordNotFound
+		}
+		if errors.Is(err, gorm.ErrForeignKeyViolated) {
+			return ErrForeignKeyViolation
+		}
+		return ErrInternal
+	}
+	return nil
+}
+
+// ReplaceOptions swaps every option of the product for options in a single
+// transaction.
+func (repo *ProductRepo) ReplaceOptions(productId uuid.UUID, options []models.ProductOption) error {
+	for idx := range options {
+		options[idx].ID = uuid.New()
+		options[idx].ProductId = productId
+		options[idx].Position = idx
+	}
+	err := repo.db.Transaction(func(tx *gorm.DB) error {
+		var product models.Product
+		if err := tx.Select("id").First(&product, "id = ?", productId).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("product_id = ?", productId).Delete(&models.ProductOption{}).Error; err != nil {
+			return err
+		}
+		if len(options) == 0 {
+			return nil
+		}
+		return tx.Create(&options).Error
+	})
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrRecordNotFound
+		}
+		return ErrInternal
+	}
+	return nil
+}
+
+// decrementStock takes quantity out of the stock of the variant, or of the
+// product when variantId is nil. The check and the update happen in a single
+// statement, so concurrent callers can never push the stock below zero.
+func decrementStock(tx *gorm.DB, productId uuid.UUID, variantId *uuid.UUID, quantity int) error {
+	query := tx.Model(&models.Product{}).Where("id = ? AND stock_quantity >= ?", productId, quantity)
+	if variantId != nil {
+		query = tx.Model(&models.ProductVariant{}).
+			Where("id = ? AND product_id = ? AND stock_quantity >= ?", *variantId, productId, quantity)
+	}
+	result := query.Update("stock_quantity", gorm.Expr("stock_quantity - ?", quantity))
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrInsufficientStock
+	}
+	return nil
+}
+
+func incrementStock(tx *gorm.DB, productId uuid.UUID, variantId *uuid.UUID, quantity int) error {
+	query := tx.Model(&models.Product{}).Where("id = ?", productId)
+	if variantId != nil {
+		query = tx.Model(&models.ProductVariant{}).Where("id = ? AND product_id = ?", *variantId, productId)
+	}
+	result := query.Update("stock_quantity", gorm.Expr("stock_quantity + ?", quantity))
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrRecordNotFound
+	}
+	return nil
+}
@@ -0,0 +1,81 @@
+package database
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rezbow/ecommerce/internal/app/models"
+	"gorm.io/gorm"
+)
+
+type IPromotionRepo interface {
+	Create(*models.Promotion) error
+	Get(uuid.UUID) (*models.Promotion, error)
+	GetPaged(*models.Pagination) ([]models.Promotion, error)
+	GetActive(time.Time) ([]models.Promotion, error)
+	Delete(uuid.UUID) error
+}
+
+type PromotionRepo struct {
+	db *gorm.DB
+}
+
+func NewPromotionRepo(db *gorm.DB) *PromotionRepo {
+	return &PromotionRepo{db: db}
+}
+
+func (repo *PromotionRepo) Create(promotion *models.Promotion) error {
+	promotion.ID = uuid.New()
+	if err := repo.db.Create(promotion).Error; err != nil {
+		return ErrInternal
+	}
+	return nil
+}
+
+func (repo *PromotionRepo) Get(id uuid.UUID) (*models.Promotion, error) {
+	var promotion models.Promotion
+	if err := repo.db.First(&promotion, "id = ?", id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrRecordNotFound
+		}
+		return nil, ErrInternal
+	}
+	return &promotion, nil
+}
+
+func (repo *PromotionRepo) GetPaged(pagination *models.Pagination) ([]models.Promotion, error) {
+	var promotions []models.Promotion
+	err := repo.db.Order("priority ASC, created_at ASC, id ASC").
+		Offset(pagination.Offset).
+		Limit(pagination.Limit).
+		Find(&promotions).Error
+	if err != nil {
+		return nil, ErrInternal
+	}
+	return promotions, nil
+}
+
+// GetActive returns the promotions that are switched on and valid at now.
+func (repo *PromotionRepo) GetActive(now time.Time) ([]models.Promotion, error) {
+	var promotions []models.Promotion
+	err := repo.db.
+		Where("active AND (starts_at IS NULL OR starts_at <= ?) AND (ends_at IS NULL OR ends_at > ?)", now, now).
+		Order("priority ASC, created_at ASC, id ASC").
+		Find(&promotions).Error
+	if err != nil {
+		return nil, ErrInternal
+	}
+	return promotions, nil
+}
+
+func (repo *PromotionRepo) Delete(id uuid.UUID) error {
+	result := repo.db.Delete(&models.Promotion{}, "id = ?", id)
+	if result.Error != nil {
+		return ErrInternal
+	}
+	if result.RowsAffected == 0 {
+		return ErrRecordNotFound
+	}
+	return nil
+}
@@ -0,0 +1,118 @@
+package database
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rezbow/ecommerce/internal/app/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type IRefreshTokenRepo interface {
+	Create(*models.RefreshToken) error
+	Rotate(string, *models.RefreshToken, time.Time) error
+	RevokeFamily(string, time.Time) error
+}
+
+type RefreshTokenRepo struct {
+	db *gorm.DB
+}
+
+func NewRefreshTokenRepo(db *gorm.DB) *RefreshTokenRepo {
+	return &RefreshTokenRepo{db: db}
+}
+
+// Create stores the first token of a new family, unless the token already
+// belongs to one.
+func (repo *RefreshTokenRepo) Create(token *models.RefreshToken) error {
+	token.ID = uuid.New()
+	if token.FamilyId == uuid.Nil {
+		token.FamilyId = token.ID
+	}
+	if err := repo.db.Create(token).Error; err != nil {
+		if errors.Is(err, gorm.ErrDuplicatedKey) {
+			return ErrDuplicateKey
+		}
+		return ErrInternal
+	}
+	return nil
+}
+
+// Rotate swaps the token stored under hash for next, which joins the same
+// family and user. The old token stays locked until the swap commits, so it
+// can only be rotated once. Presenting a token that was already rotated or
+// revoked revokes its whole family and fails with ErrTokenReused, as either
+// the client or whoever stole the token is replaying it.
+func (repo *RefreshTokenRepo) Rotate(hash string, next *models.RefreshToken, now time.Time) error {
+	reused := false
+	err := repo.db.Transaction(func(tx *gorm.DB) error {
+		var current models.RefreshToken
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&current, "token_hash = ?", hash).Error
+		if err != nil {
+			return err
+		}
+		if current.RevokedAt != nil {
+			// the revocation has to commit, the error is reported below
+			reused = true
+			return revokeFamily(tx, current.FamilyId, now)
+		}
+		if !now.Before(current.ExpiresAt) {
+			return ErrExpired
+		}
+
+		next.ID = uuid.New()
+		next.UserId = current.UserId
+		next.FamilyId = current.FamilyId
+		if err := tx.Create(next).Error; err != nil {
+			return err
+		}
+		return tx.Model(&models.RefreshToken{}).
+			Where("id = ?", current.ID).
+			Updates(map[string]any{"revoked_at": now, "replaced_by": next.ID}).Error
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			return ErrRecordNotFound
+		case errors.Is(err, ErrExpired):
+			return ErrExpired
+		}
+		return ErrInternal
+	}
+	if reused {
+		return ErrTokenReused
+	}
+	return nil
+}
+
+// RevokeFamily revokes every token of the family the token stored under hash
+// belongs to. Unknown tokens are ignored.
+func (repo *RefreshTokenRepo) RevokeFamily(hash string, now time.Time) error {
+	var token models.RefreshToken
+	if err := repo.db.First(&token, "token_hash = ?", hash).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+		return ErrInternal
+	}
+	if err := revokeFamily(repo.db, token.FamilyId, now); err != nil {
+		return ErrInternal
+	}
+	return nil
+}
+
+func revokeFamily(tx *gorm.DB, familyId uuid.UUID, now time.Time) error {
+	return tx.Model(&models.RefreshToken{}).
+		Where("family_id = ? AND revoked_at IS NULL", familyId).
+		Update("revoked_at", now).Error
+}
+
+// revokeUserTokens revokes every refresh token of the user, ending all of
+// their sessions.
+func revokeUserTokens(tx *gorm.DB, userId uuid.UUID, now time.Time) error {
+	return tx.Model(&models.RefreshToken{}).
+		Where("user_id = ? AND revoked_at IS NULL", userId).
+		Update("revoked_at", now).Error
+}
@@ -0,0 +1,175 @@
+package database
+
+import (
+	"errors"
+
+	"github.com/google/uuid"
+	"github.com/rezbow/ecommerce/internal/app/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type IRefundRepo interface {
+	Create(*models.Refund) error
+	Complete(*models.Refund) error
+	Fail(*models.Refund) error
+	GetByOrder(uuid.UUID) ([]models.Refund, error)
+}
+
+type RefundRepo struct {
+	db *gorm.DB
+}
+
+func NewRefundRepo(db *gorm.DB) *RefundRepo {
+	return &RefundRepo{db: db}
+}
+
+// Create records the refund as pending and sets its amount aside on the
+// payment, so nothing can be refunded twice while the provider is asked to
+// move the money. It fails with ErrStaleRecord when what is left of the
+// payment doesn't cover the refund anymore.
+func (repo *RefundRepo) Create(refund *models.Refund) error {
+	refund.ID = uuid.New()
+	refund.Status = models.RefundStatusPending
+	for idx := range refund.Items {
+		refund.Items[idx].ID = uuid.New()
+		refund.Items[idx].RefundId = refund.ID
+	}
+
+	err := repo.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(refund).Error; err != nil {
+			return err
+		}
+
+		// the refunded amount can never go past the captured amount, even
+		// with two refunds racing each other
+		result := tx.Model(&models.Payment{}).
+			Where("id = ? AND status = ? AND currency = ? AND amount - refunded_amount >= ?",
+				refund.PaymentId, models.PaymentStatusCaptured, refund.Amount.Currency, refund.Amount.Amount).
+			Updates(map[string]any{
+				"refunded_amount":   gorm.Expr("refunded_amount + ?", refund.Amount.Amount),
+				"refunded_currency": refund.Amount.Currency,
+			})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrStaleRecord
+		}
+		return nil
+	})
+	if err != nil {
+		if errors.Is(err, ErrStaleRecord) {
+			return ErrStaleRecord
+		}
+		return ErrInternal
+	}
+	return nil
+}
+
+// Complete marks the pending refund as done once the provider moved the
+// money. It restocks the refunded items if asked to and moves the order to
+// refunded once nothing is left of the payment, partially_refunded
+// otherwise, all in a single transaction.
+func (repo *RefundRepo) Complete(refund *models.Refund) error {
+	err := repo.db.Transaction(func(tx *gorm.DB) error {
+		if err := setPendingRefundStatus(tx, refund, models.RefundStatusCompleted); err != nil {
+			return err
+		}
+
+		var payment models.Payment
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&payment, "id = ?", refund.PaymentId).Error
+		if err != nil {
+			return err
+		}
+		next := models.OrderStatusPartiallyRefunded
+		if payment.RefundedAmount.Amount >= payment.Amount.Amount {
+			next = models.OrderStatusRefunded
+			err := tx.Model(&models.Payment{}).
+				Where("id = ?", payment.ID).
+				Update("status", models.PaymentStatusRefunded).Error
+			if err != nil {
+				return err
+			}
+		}
+
+		if refund.Restock {
+			for _, item := range refund.Items {
+				if err := incrementStock(tx, item.ProductId, item.VariantId, item.Quantity); err != nil {
+					return err
+				}
+			}
+		}
+
+		var order models.Order
+		err = tx.Clauses(clause.Locking{Strength: "UPDATE"}).Select("id", "status").First(&order, "id = ?", refund.OrderId).Error
+		if err != nil {
+			return err
+		}
+		// the money moved either way, the order only follows when it still
+		// can, e.g. a second partial refund leaves it where it is
+		if !order.Status.CanTransitionTo(next) {
+			return nil
+		}
+		return updateOrderStatus(tx, &models.OrderStatusChange{
+			ID:         uuid.New(),
+			OrderId:    order.ID,
+			FromStatus: order.Status,
+			ToStatus:   next,
+			ChangedBy:  &refund.CreatedBy,
+		})
+	})
+	if err != nil {
+		if errors.Is(err, ErrStaleRecord) {
+			return ErrStaleRecord
+		}
+		return ErrInternal
+	}
+	refund.Status = models.RefundStatusCompleted
+	return nil
+}
+
+// Fail marks the pending refund as failed and gives the amount it set aside
+// back to the payment.
+func (repo *RefundRepo) Fail(refund *models.Refund) error {
+	err := repo.db.Transaction(func(tx *gorm.DB) error {
+		if err := setPendingRefundStatus(tx, refund, models.RefundStatusFailed); err != nil {
+			return err
+		}
+		return tx.Model(&models.Payment{}).
+			Where("id = ?", refund.PaymentId).
+			Update("refunded_amount", gorm.Expr("refunded_amount - ?", refund.Amount.Amount)).Error
+	})
+	if err != nil {
+		if errors.Is(err, ErrStaleRecord) {
+			return ErrStaleRecord
+		}
+		return ErrInternal
+	}
+	refund.Status = models.RefundStatusFailed
+	return nil
+}
+
+// setPendingRefundStatus settles a pending refund, it fails with
+// ErrStaleRecord when the refund was settled already.
+func setPendingRefundStatus(tx *gorm.DB, refund *models.Refund, status models.RefundStatus) error {
+	result := tx.Model(&models.Refund{}).
+		Where("id = ? AND status = ?", refund.ID, models.RefundStatusPending).
+		Update("status", status)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrStaleRecord
+	}
+	return nil
+}
+
+func (repo *RefundRepo) GetByOrder(orderId uuid.UUID) ([]models.Refund, error) {
+	var refunds []models.Refund
+	err := repo.db.Preload("Items").Where("order_id = ?", orderId).Order("created_at ASC").Find(&refunds).Error
+	if err != nil {
+		return nil, ErrInternal
+	}
+	return refunds, nil
+}
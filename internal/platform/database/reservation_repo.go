@@ -0,0 +1,98 @@
+package database
+
+import (
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rezbow/ecommerce/internal/app/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type IReservationRepo interface {
+	GetExpiredOrderIds(time.Time, int) ([]uuid.UUID, error)
+}
+
+type ReservationRepo struct {
+	db *gorm.DB
+}
+
+func NewReservationRepo(db *gorm.DB) *ReservationRepo {
+	return &ReservationRepo{db: db}
+}
+
+// GetExpiredOrderIds returns the orders that still hold stock past their
+// expiry time.
+func (repo *ReservationRepo) GetExpiredOrderIds(now time.Time, limit int) ([]uuid.UUID, error) {
+	var orderIds []uuid.UUID
+	err := repo.db.Model(&models.StockReservation{}).
+		Distinct("order_id").
+		Where("status = ? AND expires_at < ?", models.ReservationStatusHeld, now).
+		Limit(limit).
+		Pluck("order_id", &orderIds).Error
+	if err != nil {
+		return nil, ErrInternal
+	}
+	return orderIds, nil
+}
+
+// holdStock takes the items of the order out of stock and records a hold for
+// each of them that expires at holdUntil.
+func holdStock(tx *gorm.DB, order *models.Order, holdUntil time.Time) error {
+	// always lock product and variant rows in the same order so concurrent
+	// checkouts over the same products can't deadlock each other, the
+	// order's own items keep the order they were added in
+	items := make([]*models.OrderItem, len(order.Items))
+	for idx := range order.Items {
+		items[idx] = &order.Items[idx]
+	}
+	sort.Slice(items, func(i, j int) bool {
+		return items[i].StockKey().String() < items[j].StockKey().String()
+	})
+
+	for _, item := range items {
+		if err := decrementStock(tx, item.ProductId, item.VariantId, item.Quantity); err != nil {
+			return err
+		}
+		reservation := models.StockReservation{
+			ID:        uuid.New(),
+			OrderId:   order.ID,
+			ProductId: item.ProductId,
+			VariantId: item.VariantId,
+			Quantity:  item.Quantity,
+			Status:    models.ReservationStatusHeld,
+			ExpiresAt: holdUntil,
+		}
+		if err := tx.Create(&reservation).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func commitReservations(tx *gorm.DB, orderId uuid.UUID) error {
+	return tx.Model(&models.StockReservation{}).
+		Where("order_id = ? AND status = ?", orderId, models.ReservationStatusHeld).
+		Update("status", models.ReservationStatusCommitted).Error
+}
+
+// releaseReservations gives every reservation of the order that was not
+// released yet back to the product stock.
+func releaseReservations(tx *gorm.DB, orderId uuid.UUID) error {
+	var reservations []models.StockReservation
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("order_id = ? AND status <> ?", orderId, models.ReservationStatusReleased).
+		Find(&reservations).Error
+	if err != nil {
+		return err
+	}
+	for _, reservation := range reservations {
+		if err := incrementStock(tx, reservation.ProductId, reservation.VariantId, reservation.Quantity); err != nil {
+			return err
+		}
+	}
+	return tx.Model(&models.StockReservation{}).
+		Where("order_id = ? AND status <> ?", orderId, models.ReservationStatusReleased).
+		Update("status", models.ReservationStatusReleased).Error
+}
@@ -0,0 +1,103 @@
+package database
+
+import (
+	"errors"
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rezbow/ecommerce/internal/app/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type IRoleRepo interface {
+	GetAll() ([]models.Role, error)
+	GetByNames([]string) ([]models.Role, error)
+	SetUserRoles(uuid.UUID, []uuid.UUID, time.Time) error
+}
+
+type RoleRepo struct {
+	db *gorm.DB
+}
+
+func NewRoleRepo(db *gorm.DB) *RoleRepo {
+	return &RoleRepo{db: db}
+}
+
+func (repo *RoleRepo) GetAll() ([]models.Role, error) {
+	var roles []models.Role
+	if err := repo.db.Preload("Permissions").Order("name").Find(&roles).Error; err != nil {
+		return nil, ErrInternal
+	}
+	return roles, nil
+}
+
+// GetByNames returns the roles with the names, names of missing roles are
+// skipped.
+func (repo *RoleRepo) GetByNames(names []string) ([]models.Role, error) {
+	roles := make([]models.Role, 0)
+	if len(names) == 0 {
+		return roles, nil
+	}
+	if err := repo.db.Preload("Permissions").Where("name IN ?", names).Find(&roles).Error; err != nil {
+		return nil, ErrInternal
+	}
+	return roles, nil
+}
+
+// SetUserRoles replaces every role of the user with roleIds, which must not
+// hold duplicates, and revokes the user's refresh tokens so permissions they
+// lost don't outlive their current access token. It fails with
+// ErrLastHolder when it would leave no user with the super admin role.
+func (repo *RoleRepo) SetUserRoles(userId uuid.UUID, roleIds []uuid.UUID, now time.Time) error {
+	err := repo.db.Transaction(func(tx *gorm.DB) error {
+		var user models.User
+		if err := tx.Select("id").First(&user, "id = ?", userId).Error; err != nil {
+			return err
+		}
+		// the super admin role stays locked until the new roles commit, so
+		// two super admins can't demote each other at the same time
+		var superAdmin models.Role
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&superAdmin, "name = ?", models.RoleSuperAdmin).Error
+		if err != nil {
+			return err
+		}
+		if !slices.Contains(roleIds, superAdmin.ID) {
+			var holders []uuid.UUID
+			err := tx.Table("user_roles").
+				Where("role_id = ?", superAdmin.ID).
+				Pluck("user_id", &holders).Error
+			if err != nil {
+				return err
+			}
+			if len(holders) == 1 && holders[0] == userId {
+				return ErrLastHolder
+			}
+		}
+
+		if err := tx.Exec("DELETE FROM user_roles WHERE user_id = ?", userId).Error; err != nil {
+			return err
+		}
+		for _, roleId := range roleIds {
+			err := tx.Exec("INSERT INTO user_roles (user_id, role_id) VALUES (?, ?)", userId, roleId).Error
+			if err != nil {
+				return err
+			}
+		}
+		return revokeUserTokens(tx, userId, now)
+	})
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrRecordNotFound
+		}
+		if errors.Is(err, gorm.ErrForeignKeyViolated) {
+			return ErrForeignKeyViolation
+		}
+		if errors.Is(err, ErrLastHolder) {
+			return ErrLastHolder
+		}
+		return ErrInternal
+	}
+	return nil
+}
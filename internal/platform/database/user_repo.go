@@ -20,7 +20,8 @@ func NewUserRepo(db *gorm.DB) *UserRepo {
 
 func (repo *UserRepo) GetByEmail(email string) (*models.User, error) {
 	var user models.User
-	if err := repo.DB.First(&user, "email = ?", email).Error; err != nil {
+	// accounts created before emails were normalized can still be in mixed case
+	if err := repo.DB.Preload("Roles.Permissions").First(&user, "LOWER(email) = ?", email).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return nil, ErrRecordNotFound
 		}
@@ -31,7 +32,7 @@ func (repo *UserRepo) GetByEmail(email string) (*models.User, error) {
 
 func (repo *UserRepo) Get(id string) (*models.User, error) {
 	var user models.User
-	if err := repo.DB.First(&user, "id = ?", id).Error; err != nil {
+	if err := repo.DB.Preload("Roles.Permissions").First(&user, "id = ?", id).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return nil, ErrRecordNotFound
 		}
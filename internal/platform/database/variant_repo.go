@@ -0,0 +1,74 @@
+package database
+
+import (
+	"errors"
+
+	"github.com/google/uuid"
+	"github.com/rezbow/ecommerce/internal/app/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type IVariantRepo interface {
+	Create(*models.ProductVariant) error
+	Update(uuid.UUID, uuid.UUID, map[string]any) (*models.ProductVariant, error)
+	Delete(uuid.UUID, uuid.UUID) error
+}
+
+type VariantRepo struct {
+	db *gorm.DB
+}
+
+func NewVariantRepo(db *gorm.DB) *VariantRepo {
+	return &VariantRepo{db: db}
+}
+
+// Create fails with ErrDuplicateKey when the sku is taken or the product
+// already has a variant with the same options.
+func (repo *VariantRepo) Create(variant *models.ProductVariant) error {
+	variant.ID = uuid.New()
+	if err := repo.db.Create(variant).Error; err != nil {
+		if errors.Is(err, gorm.ErrDuplicatedKey) {
+			return ErrDuplicateKey
+		}
+		if errors.Is(err, gorm.ErrForeignKeyViolated) {
+			return ErrRecordNotFound
+		}
+		return ErrInternal
+	}
+	return nil
+}
+
+// Update only touches the variant when it belongs to the product.
+func (repo *VariantRepo) Update(productId uuid.UUID, variantId uuid.UUID, updatedColumns map[string]any) (*models.ProductVariant, error) {
+	var variant models.ProductVariant
+	result := repo.db.Model(&variant).
+		Clauses(clause.Returning{}).
+		Where("id = ? AND product_id = ?", variantId, productId).
+		Updates(updatedColumns)
+	if result.Error != nil {
+		if errors.Is(result.Error, gorm.ErrDuplicatedKey) {
+			return nil, ErrDuplicateKey
+		}
+		return nil, ErrInternal
+	}
+	if result.RowsAffected == 0 {
+		return nil, ErrRecordNotFound
+	}
+	return &variant, nil
+}
+
+// Delete fails with ErrForeignKeyViolation once the variant was ordered.
+func (repo *VariantRepo) Delete(productId uuid.UUID, variantId uuid.UUID) error {
+	result := repo.db.Where("id = ? AND product_id = ?", variantId, productId).Delete(&models.ProductVariant{})
+	if result.Error != nil {
+		if errors.Is(result.Error, gorm.ErrForeignKeyViolated) {
+			return ErrForeignKeyViolation
+		}
+		return ErrInternal
+	}
+	if result.RowsAffected == 0 {
+		return ErrRecordNotFound
+	}
+	return nil
+}
@@ -0,0 +1,42 @@
+package database
+
+import (
+	"errors"
+
+	"github.com/google/uuid"
+	"github.com/rezbow/ecommerce/internal/app/models"
+	"gorm.io/gorm"
+)
+
+type IWebhookEventRepo interface {
+	Create(*models.WebhookEvent) error
+	Delete(uuid.UUID) error
+}
+
+type WebhookEventRepo struct {
+	db *gorm.DB
+}
+
+func NewWebhookEventRepo(db *gorm.DB) *WebhookEventRepo {
+	return &WebhookEventRepo{db: db}
+}
+
+// Create fails with ErrDuplicateKey when the provider already delivered an
+// event with the same id.
+func (repo *WebhookEventRepo) Create(event *models.WebhookEvent) error {
+	event.ID = uuid.New()
+	if err := repo.db.Create(event).Error; err != nil {
+		if errors.Is(err, gorm.ErrDuplicatedKey) {
+			return ErrDuplicateKey
+		}
+		return ErrInternal
+	}
+	return nil
+}
+
+func (repo *WebhookEventRepo) Delete(id uuid.UUID) error {
+	if err := repo.db.Delete(&models.WebhookEvent{}, "id = ?", id).Error; err != nil {
+		return ErrInternal
+	}
+	return nil
+}
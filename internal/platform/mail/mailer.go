@@ -0,0 +1,13 @@
+package mail
+
+// Mailer is implemented by every service the shop can send emails through.
+type Mailer interface {
+	Send(Message) error
+}
+
+type Message struct {
+	To      string
+	Subject string
+	// Body is plain text
+	Body string
+}
@@ -38,24 +38,26 @@ func AuthMiddleware(cfg *config.Config) gin.HandlerFunc {
 		// 4. INJECT: Add user data from claims into Gin's context
 		// Use the custom context keys for safe retrieval later
 		c.Set("userId", claims.UserId)
-		c.Set("isAdmin", claims.IsAdmin)
+		c.Set("claims", claims)
 
 		// 5. CONTINUE: Token is valid, proceed to the next handler
 		c.Next()
 	}
 }
 
-func AdminMiddleware() gin.HandlerFunc {
+// RequirePermission only lets through users whose access token grants
+// permission. It has to run after AuthMiddleware.
+func RequirePermission(permission string) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		isAdminVal, exists := c.Get("isAdmin")
+		value, exists := c.Get("claims")
 		if !exists {
-			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"errors": "unauthorized"})
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
 			return
 		}
 
-		isAdmin, ok := isAdminVal.(bool)
-		if !ok || !isAdmin {
-			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"errors": "Requires Administrator privileges"})
+		claims, ok := value.(*authentication.UserClaims)
+		if !ok || !claims.HasPermission(permission) {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "requires the " + permission + " permission"})
 			return
 		}
 		c.Next()
@@ -0,0 +1,201 @@
+package middlewares
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-redis/redis/v8"
+)
+
+const (
+	idempotencyHeader       = "Idempotency-Key"
+	idempotencyReplayHeader = "Idempotent-Replayed"
+	idempotencyTTL          = time.Hour * 24
+	maxIdempotencyKeyLength = 255
+)
+
+var errIdempotencyKeyMissing = errors.New("idempotency key not found")
+
+// idempotencyStore keeps the records of the keys in use.
+type idempotencyStore interface {
+	// Claim stores record under key unless the key is taken already
+	Claim(ctx context.Context, key string, record []byte) (bool, error)
+	// Get returns errIdempotencyKeyMissing when nothing is stored under key
+	Get(ctx context.Context, key string) ([]byte, error)
+	Set(ctx context.Context, key string, record []byte) error
+	Release(ctx context.Context, key string) error
+}
+
+type redisIdempotencyStore struct {
+	client *redis.Client
+}
+
+func (s *redisIdempotencyStore) Claim(ctx context.Context, key string, record []byte) (bool, error) {
+	return s.client.SetNX(ctx, key, record, idempotencyTTL).Result()
+}
+
+func (s *redisIdempotencyStore) Get(ctx context.Context, key string) ([]byte, error) {
+	value, err := s.client.Get(ctx, key).Bytes()
+	if err == redis.Nil {
+		return nil, errIdempotencyKeyMissing
+	}
+	return value, err
+}
+
+func (s *redisIdempotencyStore) Set(ctx context.Context, key string, record []byte) error {
+	return s.client.Set(ctx, key, record, idempotencyTTL).Err()
+}
+
+func (s *redisIdempotencyStore) Release(ctx context.Context, key string) error {
+	return s.client.Del(ctx, key).Err()
+}
+
+type idempotencyRecord struct {
+	// Fingerprint is the hash of the request the key was first used with
+	Fingerprint string `json:"fingerprint"`
+	Completed   bool   `json:"completed"`
+	StatusCode  int    `json:"status_code"`
+	ContentType string `json:"content_type"`
+	Body        []byte `json:"body"`
+}
+
+type responseRecorder struct {
+	gin.ResponseWriter
+	body *bytes.Buffer
+}
+
+func (w *responseRecorder) Write(data []byte) (int, error) {
+	w.body.Write(data)
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *responseRecorder) WriteString(data string) (int, error) {
+	w.body.WriteString(data)
+	return w.ResponseWriter.WriteString(data)
+}
+
+// IdempotencyMiddleware makes retries of a request that carries an
+// Idempotency-Key header safe. The first response for a key is stored per user
+// and replayed for every retry, reusing a key for a different request is
+// rejected. Must run after AuthMiddleware.
+func IdempotencyMiddleware(client *redis.Client) gin.HandlerFunc {
+	return idempotencyMiddleware(&redisIdempotencyStore{client: client})
+}
+
+func idempotencyMiddleware(store idempotencyStore) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key := c.GetHeader(idempotencyHeader)
+		if key == "" {
+			c.Next()
+			return
+		}
+		if len(key) > maxIdempotencyKeyLength {
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "Idempotency-Key is too long"})
+			return
+		}
+
+		userId, exists := c.Get("userId")
+		if !exists {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+			return
+		}
+
+		body, err := io.ReadAll(c.Request.Body)
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "unreadable body"})
+			return
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+		hash := sha256.New()
+		hash.Write([]byte(c.Request.Method + " " + c.FullPath() + "\n"))
+		hash.Write(body)
+		fingerprint := hex.EncodeToString(hash.Sum(nil))
+
+		ctx := c.Request.Context()
+		redisKey := fmt.Sprintf("idempotency:%v:%s", userId, key)
+
+		// claim the key, only one request can hold it at a time
+		claim, _ := json.Marshal(idempotencyRecord{Fingerprint: fingerprint})
+		claimed, err := store.Claim(ctx, redisKey, claim)
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+			return
+		}
+
+		if !claimed {
+			replayIdempotentResponse(c, store, redisKey, fingerprint)
+			return
+		}
+
+		// a handler that panics never completes the response, release the
+		// key so it isn't stuck in progress until it expires
+		completed := false
+		defer func() {
+			if !completed {
+				store.Release(context.Background(), redisKey)
+			}
+		}()
+
+		recorder := &responseRecorder{ResponseWriter: c.Writer, body: &bytes.Buffer{}}
+		c.Writer = recorder
+		c.Next()
+		completed = true
+
+		// server errors are not final, let the client retry with the same key
+		if recorder.Status() >= http.StatusInternalServerError {
+			store.Release(context.Background(), redisKey)
+			return
+		}
+
+		record, _ := json.Marshal(idempotencyRecord{
+			Fingerprint: fingerprint,
+			Completed:   true,
+			StatusCode:  recorder.Status(),
+			ContentType: recorder.Header().Get("Content-Type"),
+			Body:        recorder.body.Bytes(),
+		})
+		store.Set(context.Background(), redisKey, record)
+	}
+}
+
+func replayIdempotentResponse(c *gin.Context, store idempotencyStore, redisKey string, fingerprint string) {
+	value, err := store.Get(c.Request.Context(), redisKey)
+	if errors.Is(err, errIdempotencyKeyMissing) {
+		// the first request failed and released the key in the meantime
+		c.AbortWithStatusJSON(http.StatusConflict, gin.H{"error": "request with this Idempotency-Key is being retried, try again"})
+		return
+	}
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	var record idempotencyRecord
+	if err := json.Unmarshal(value, &record); err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	if record.Fingerprint != fingerprint {
+		c.AbortWithStatusJSON(http.StatusUnprocessableEntity, gin.H{"error": "Idempotency-Key was already used with a different request"})
+		return
+	}
+	if !record.Completed {
+		c.AbortWithStatusJSON(http.StatusConflict, gin.H{"error": "request with this Idempotency-Key is still in progress"})
+		return
+	}
+
+	c.Header(idempotencyReplayHeader, "true")
+	c.Data(record.StatusCode, record.ContentType, record.Body)
+	c.Abort()
+}
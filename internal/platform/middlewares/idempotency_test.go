@@ -0,0 +1,182 @@
+package middlewares
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+type memoryIdempotencyStore struct {
+	mu      sync.Mutex
+	records map[string][]byte
+}
+
+func newMemoryIdempotencyStore() *memoryIdempotencyStore {
+	return &memoryIdempotencyStore{records: make(map[string][]byte)}
+}
+
+func (s *memoryIdempotencyStore) Claim(_ context.Context, key string, record []byte) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.records[key]; ok {
+		return false, nil
+	}
+	s.records[key] = record
+	return true, nil
+}
+
+func (s *memoryIdempotencyStore) Get(_ context.Context, key string) ([]byte, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	record, ok := s.records[key]
+	if !ok {
+		return nil, errIdempotencyKeyMissing
+	}
+	return record, nil
+}
+
+func (s *memoryIdempotencyStore) Set(_ context.Context, key string, record []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.records[key] = record
+	return nil
+}
+
+func (s *memoryIdempotencyStore) Release(_ context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.records, key)
+	return nil
+}
+
+type idempotencyTest struct {
+	router *gin.Engine
+	calls  int
+}
+
+// newIdempotencyTest routes POST /orders through the middleware to handler,
+// counting how often the handler runs.
+func newIdempotencyTest(handler gin.HandlerFunc) *idempotencyTest {
+	gin.SetMode(gin.TestMode)
+	test := &idempotencyTest{router: gin.New()}
+	test.router.Use(gin.Recovery(), func(c *gin.Context) {
+		c.Set("userId", "user-1")
+	}, idempotencyMiddleware(newMemoryIdempotencyStore()))
+	test.router.POST("/orders", func(c *gin.Context) {
+		test.calls++
+		handler(c)
+	})
+	return test
+}
+
+func (test *idempotencyTest) post(key string, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, "/orders", strings.NewReader(body))
+	req.Header.Set(idempotencyHeader, key)
+	w := httptest.NewRecorder()
+	test.router.ServeHTTP(w, req)
+	return w
+}
+
+func TestIdempotencyReplay(t *testing.T) {
+	test := newIdempotencyTest(func(c *gin.Context) {
+		c.JSON(http.StatusCreated, gin.H{"order": "1"})
+	})
+
+	first := test.post("key-1", `{"item":1}`)
+	second := test.post("key-1", `{"item":1}`)
+	if test.calls != 1 {
+		t.Errorf("handler ran %d times, want 1", test.calls)
+	}
+	if second.Code != first.Code || second.Body.String() != first.Body.String() {
+		t.Errorf("replayed %d %s, want %d %s", second.Code, second.Body, first.Code, first.Body)
+	}
+	if second.Header().Get(idempotencyReplayHeader) != "true" {
+		t.Errorf("replayed response misses the %s header", idempotencyReplayHeader)
+	}
+	if first.Header().Get(idempotencyReplayHeader) != "" {
+		t.Errorf("first response has the %s header", idempotencyReplayHeader)
+	}
+}
+
+func TestIdempotencyFingerprintMismatch(t *testing.T) {
+	test := newIdempotencyTest(func(c *gin.Context) {
+		c.JSON(http.StatusCreated, gin.H{"order": "1"})
+	})
+
+	test.post("key-1", `{"item":1}`)
+	w := test.post("key-1", `{"item":2}`)
+	if w.Code != http.StatusUnprocessableEntity {
+		t.Errorf("status is %d, want %d", w.Code, http.StatusUnprocessableEntity)
+	}
+	if test.calls != 1 {
+		t.Errorf("handler ran %d times, want 1", test.calls)
+	}
+}
+
+func TestIdempotencyInProgress(t *testing.T) {
+	var test *idempotencyTest
+	var retry *httptest.ResponseRecorder
+	test = newIdempotencyTest(func(c *gin.Context) {
+		// the client retries while the first request is still handled
+		if retry == nil {
+			retry = test.post("key-1", `{"item":1}`)
+		}
+		c.JSON(http.StatusCreated, gin.H{"order": "1"})
+	})
+
+	test.post("key-1", `{"item":1}`)
+	if retry.Code != http.StatusConflict {
+		t.Errorf("retry status is %d, want %d", retry.Code, http.StatusConflict)
+	}
+	if test.calls != 1 {
+		t.Errorf("handler ran %d times, want 1", test.calls)
+	}
+}
+
+func TestIdempotencyReleasesFailedRequests(t *testing.T) {
+	tests := []struct {
+		name    string
+		handler gin.HandlerFunc
+	}{
+		{
+			name: "server error",
+			handler: func(c *gin.Context) {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+			},
+		},
+		{
+			name: "panic",
+			handler: func(c *gin.Context) {
+				panic("handler failed")
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			failed := false
+			test := newIdempotencyTest(func(c *gin.Context) {
+				if !failed {
+					failed = true
+					tt.handler(c)
+					return
+				}
+				c.JSON(http.StatusCreated, gin.H{"order": "1"})
+			})
+
+			if w := test.post("key-1", `{"item":1}`); w.Code != http.StatusInternalServerError {
+				t.Fatalf("first status is %d, want %d", w.Code, http.StatusInternalServerError)
+			}
+			if w := test.post("key-1", `{"item":1}`); w.Code != http.StatusCreated {
+				t.Errorf("retry status is %d, want %d", w.Code, http.StatusCreated)
+			}
+			if test.calls != 2 {
+				t.Errorf("handler ran %d times, want 2", test.calls)
+			}
+		})
+	}
+}
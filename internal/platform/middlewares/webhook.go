@@ -0,0 +1,44 @@
+package middlewares
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rezbow/ecommerce/internal/platform/signing"
+)
+
+const (
+	webhookSignatureHeader = "X-Webhook-Signature"
+	webhookTolerance       = time.Minute * 5
+	maxWebhookBodySize     = 1 << 20
+)
+
+// WebhookSignatureMiddleware authenticates machine to machine callbacks by the
+// HMAC signature of their raw body instead of a user token.
+func WebhookSignatureMiddleware(secret string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		header := c.GetHeader(webhookSignatureHeader)
+		if header == "" {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "signature header required"})
+			return
+		}
+
+		body, err := io.ReadAll(io.LimitReader(c.Request.Body, maxWebhookBodySize))
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "unreadable body"})
+			return
+		}
+
+		if err := signing.VerifyTimestamped([]byte(secret), body, header, webhookTolerance, time.Now()); err != nil {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+			return
+		}
+
+		// put the body back for the handler
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+		c.Next()
+	}
+}
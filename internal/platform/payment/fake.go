@@ -0,0 +1,107 @@
+package payment
+
+import (
+	"fmt"
+	"sync"
+)
+
+// Tokens understood by FakeProvider, any other token is authorized and
+// captured.
+const (
+	FakeTokenDecline = "tok_decline"
+	FakeTokenTimeout = "tok_timeout"
+	// FakeTokenCaptureFail is authorized, but capturing it fails
+	FakeTokenCaptureFail = "tok_capture_fail"
+)
+
+type fakeTransaction struct {
+	authorized  int64
+	captured    int64
+	refunded    int64
+	voided      bool
+	captureFail bool
+}
+
+// FakeProvider is an in-process payment provider for local development and
+// tests. It keeps transactions in memory and its outcome only depends on the
+// token it is given, see FakeTokenDecline, FakeTokenTimeout and
+// FakeTokenCaptureFail.
+type FakeProvider struct {
+	mu           sync.Mutex
+	transactions map[string]*fakeTransaction
+	sequence     int
+}
+
+func NewFakeProvider() *FakeProvider {
+	return &FakeProvider{
+		transactions: make(map[string]*fakeTransaction),
+	}
+}
+
+func (p *FakeProvider) Name() string {
+	return "fake"
+}
+
+func (p *FakeProvider) Authorize(req AuthorizeRequest) (string, error) {
+	switch req.Token {
+	case FakeTokenDecline:
+		return "", ErrDeclined
+	case FakeTokenTimeout:
+		return "", ErrTimeout
+	}
+	if req.Amount <= 0 || len(req.Currency) != 3 {
+		return "", ErrInvalidAmount
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.sequence++
+	reference := fmt.Sprintf("fake_%s_%d", req.OrderId, p.sequence)
+	p.transactions[reference] = &fakeTransaction{
+		authorized:  req.Amount,
+		captureFail: req.Token == FakeTokenCaptureFail,
+	}
+	return reference, nil
+}
+
+func (p *FakeProvider) Capture(reference string, amount int64) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	transaction, ok := p.transactions[reference]
+	if !ok || transaction.voided {
+		return ErrUnknownTransaction
+	}
+	if transaction.captureFail {
+		return ErrDeclined
+	}
+	if amount <= 0 || transaction.captured+amount > transaction.authorized {
+		return ErrInvalidAmount
+	}
+	transaction.captured += amount
+	return nil
+}
+
+func (p *FakeProvider) Void(reference string) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	transaction, ok := p.transactions[reference]
+	if !ok || transaction.captured > 0 {
+		return ErrUnknownTransaction
+	}
+	transaction.voided = true
+	return nil
+}
+
+func (p *FakeProvider) Refund(reference string, amount int64) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	transaction, ok := p.transactions[reference]
+	if !ok {
+		return ErrUnknownTransaction
+	}
+	if amount <= 0 || transaction.refunded+amount > transaction.captured {
+		return ErrInvalidAmount
+	}
+	transaction.refunded += amount
+	return nil
+}
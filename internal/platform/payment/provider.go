@@ -0,0 +1,39 @@
+package payment
+
+import (
+	"errors"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrDeclined           = errors.New("payment declined")
+	ErrTimeout            = errors.New("payment provider timed out")
+	ErrUnknownTransaction = errors.New("unknown payment transaction")
+	ErrInvalidAmount      = errors.New("invalid payment amount")
+)
+
+// Provider is implemented by every payment gateway the shop can charge
+// through. Amounts are in the smallest unit of the currency the transaction
+// was authorized in and always positive, orders with nothing to charge are
+// settled by the payment service without a provider.
+type Provider interface {
+	Name() string
+	// Authorize reserves amount on the customer's payment method and returns
+	// the provider's reference for the transaction.
+	Authorize(AuthorizeRequest) (string, error)
+	// Capture collects a previously authorized amount.
+	Capture(reference string, amount int64) error
+	// Void cancels an authorization that was not captured.
+	Void(reference string) error
+	// Refund gives back part or all of a captured amount.
+	Refund(reference string, amount int64) error
+}
+
+type AuthorizeRequest struct {
+	OrderId  uuid.UUID
+	Amount   int64
+	Currency string
+	// Token identifies the customer's payment method at the provider
+	Token string
+}
@@ -0,0 +1,86 @@
+package signing
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	ErrMalformedSignature = errors.New("malformed signature header")
+	ErrInvalidSignature   = errors.New("signature mismatch")
+	ErrStaleTimestamp     = errors.New("signature timestamp outside of tolerance")
+)
+
+// Sign returns the hex encoded HMAC-SHA256 of message.
+func Sign(secret []byte, message []byte) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write(message)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify checks signature against message in constant time.
+func Verify(secret []byte, message []byte, signature string) bool {
+	expected, err := hex.DecodeString(signature)
+	if err != nil {
+		return false
+	}
+	mac := hmac.New(sha256.New, secret)
+	mac.Write(message)
+	return hmac.Equal(mac.Sum(nil), expected)
+}
+
+// SignTimestamped builds a signature header of the form "t=<unix>,v1=<hex>"
+// where the signed message is "<unix>.<payload>".
+func SignTimestamped(secret []byte, payload []byte, at time.Time) string {
+	timestamp := strconv.FormatInt(at.Unix(), 10)
+	return fmt.Sprintf("t=%s,v1=%s", timestamp, Sign(secret, timestampedMessage(timestamp, payload)))
+}
+
+// VerifyTimestamped checks a header built by SignTimestamped and rejects it
+// when its timestamp is more than tolerance away from now, so a captured
+// request can't be replayed later on.
+func VerifyTimestamped(secret []byte, payload []byte, header string, tolerance time.Duration, now time.Time) error {
+	var timestamp, signature string
+	for _, part := range strings.Split(header, ",") {
+		key, value, found := strings.Cut(strings.TrimSpace(part), "=")
+		if !found {
+			return ErrMalformedSignature
+		}
+		switch key {
+		case "t":
+			timestamp = value
+		case "v1":
+			signature = value
+		}
+	}
+	if timestamp == "" || signature == "" {
+		return ErrMalformedSignature
+	}
+
+	unix, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return ErrMalformedSignature
+	}
+	age := now.Sub(time.Unix(unix, 0))
+	if age > tolerance || age < -tolerance {
+		return ErrStaleTimestamp
+	}
+
+	if !Verify(secret, timestampedMessage(timestamp, payload), signature) {
+		return ErrInvalidSignature
+	}
+	return nil
+}
+
+func timestampedMessage(timestamp string, payload []byte) []byte {
+	message := make([]byte, 0, len(timestamp)+1+len(payload))
+	message = append(message, timestamp...)
+	message = append(message, '.')
+	return append(message, payload...)
+}
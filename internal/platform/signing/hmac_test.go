@@ -0,0 +1,89 @@
+package signing
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSignVerify(t *testing.T) {
+	secret := []byte("secret")
+	message := []byte(`{"id":"evt_1"}`)
+	signature := Sign(secret, message)
+
+	// RFC 4231 test case 2
+	if got := Sign([]byte("Jefe"), []byte("what do ya want for nothing?")); got != "5bdcc146bf60754e6a042426089575c75a003f089d2739839dec58b964ec3843" {
+		t.Errorf("Sign is %s, want the RFC 4231 digest", got)
+	}
+
+	tests := []struct {
+		name      string
+		secret    []byte
+		message   []byte
+		signature string
+		want      bool
+	}{
+		{name: "valid", secret: secret, message: message, signature: signature, want: true},
+		{name: "upper case hex", secret: secret, message: message, signature: strings.ToUpper(signature), want: true},
+		{name: "other secret", secret: []byte("other"), message: message, signature: signature},
+		{name: "other message", secret: secret, message: []byte(`{"id":"evt_2"}`), signature: signature},
+		{name: "truncated", secret: secret, message: message, signature: signature[:len(signature)-2]},
+		{name: "not hex", secret: secret, message: message, signature: "zz" + signature[2:]},
+		{name: "empty", secret: secret, message: message, signature: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Verify(tt.secret, tt.message, tt.signature); got != tt.want {
+				t.Errorf("Verify is %t, want %t", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestVerifyTimestamped(t *testing.T) {
+	secret := []byte("secret")
+	payload := []byte(`{"id":"evt_1"}`)
+	now := time.Unix(1_700_000_000, 0)
+	tolerance := 5 * time.Minute
+	signed := SignTimestamped(secret, payload, now)
+	_, signature, _ := strings.Cut(signed, ",")
+
+	tests := []struct {
+		name    string
+		secret  []byte
+		payload []byte
+		header  string
+		want    error
+	}{
+		{name: "valid", header: signed},
+		{name: "spaces after the comma", header: strings.Replace(signed, ",", ", ", 1)},
+		{name: "at the edge of the tolerance", header: SignTimestamped(secret, payload, now.Add(-tolerance))},
+		{name: "slightly in the future", header: SignTimestamped(secret, payload, now.Add(time.Minute))},
+		{name: "too old", header: SignTimestamped(secret, payload, now.Add(-tolerance-time.Second)), want: ErrStaleTimestamp},
+		{name: "too far in the future", header: SignTimestamped(secret, payload, now.Add(tolerance+time.Second)), want: ErrStaleTimestamp},
+		{name: "timestamp swapped", header: "t=1700000060," + signature, want: ErrInvalidSignature},
+		{name: "other payload", payload: []byte(`{"id":"evt_2"}`), header: signed, want: ErrInvalidSignature},
+		{name: "other secret", secret: []byte("other"), header: signed, want: ErrInvalidSignature},
+		{name: "missing signature", header: "t=1700000000", want: ErrMalformedSignature},
+		{name: "missing timestamp", header: signature, want: ErrMalformedSignature},
+		{name: "timestamp not a number", header: "t=yesterday," + signature, want: ErrMalformedSignature},
+		{name: "part without a value", header: signed + ",v0", want: ErrMalformedSignature},
+		{name: "empty", header: "", want: ErrMalformedSignature},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			secret, payload := secret, payload
+			if tt.secret != nil {
+				secret = tt.secret
+			}
+			if tt.payload != nil {
+				payload = tt.payload
+			}
+			err := VerifyTimestamped(secret, payload, tt.header, tolerance, now)
+			if !errors.Is(err, tt.want) {
+				t.Errorf("VerifyTimestamped error is %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
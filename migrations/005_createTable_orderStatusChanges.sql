@@ -0,0 +1,16 @@
+-- +goose Up
+
+CREATE TABLE order_status_changes (
+	id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+	order_id UUID NOT NULL REFERENCES orders(id),
+	from_status VARCHAR(50) NOT NULL,
+	to_status VARCHAR(50) NOT NULL,
+	changed_by UUID NOT NULL REFERENCES users(id),
+	created_at TIMESTAMP
+);
+
+CREATE INDEX idx_order_status_changes_order_id ON order_status_changes(order_id);
+
+-- +goose Down
+
+DROP TABLE IF EXISTS order_status_changes;
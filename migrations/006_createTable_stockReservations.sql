@@ -0,0 +1,26 @@
+-- +goose Up
+
+CREATE TABLE stock_reservations (
+	id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+	order_id UUID NOT NULL REFERENCES orders(id),
+	product_id UUID NOT NULL REFERENCES products(id),
+	quantity INTEGER NOT NULL,
+	status VARCHAR(20) NOT NULL,
+	expires_at TIMESTAMP NOT NULL,
+	created_at TIMESTAMP,
+	updated_at TIMESTAMP
+);
+
+CREATE INDEX idx_stock_reservations_order_id ON stock_reservations(order_id);
+CREATE INDEX idx_stock_reservations_held_expiry ON stock_reservations(expires_at) WHERE status = 'held';
+
+ALTER TABLE products ADD CONSTRAINT products_stock_quantity_non_negative CHECK (stock_quantity >= 0);
+
+-- holds expire without a user behind them, so status changes can be made by the system
+ALTER TABLE order_status_changes ALTER COLUMN changed_by DROP NOT NULL;
+
+-- +goose Down
+
+ALTER TABLE order_status_changes ALTER COLUMN changed_by SET NOT NULL;
+ALTER TABLE products DROP CONSTRAINT IF EXISTS products_stock_quantity_non_negative;
+DROP TABLE IF EXISTS stock_reservations;
@@ -0,0 +1,20 @@
+-- +goose Up
+
+CREATE TABLE payments (
+	id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+	order_id UUID NOT NULL REFERENCES orders(id),
+	provider VARCHAR(50) NOT NULL,
+	provider_reference VARCHAR(255),
+	amount BIGINT NOT NULL,
+	status VARCHAR(20) NOT NULL,
+	failure_reason TEXT,
+	created_at TIMESTAMP,
+	updated_at TIMESTAMP
+);
+
+CREATE INDEX idx_payments_order_id ON payments(order_id);
+CREATE INDEX idx_payments_provider_reference ON payments(provider, provider_reference);
+
+-- +goose Down
+
+DROP TABLE IF EXISTS payments;
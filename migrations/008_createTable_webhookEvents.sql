@@ -0,0 +1,14 @@
+-- +goose Up
+
+CREATE TABLE webhook_events (
+	id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+	provider VARCHAR(50) NOT NULL,
+	event_id VARCHAR(255) NOT NULL,
+	type VARCHAR(50) NOT NULL,
+	created_at TIMESTAMP,
+	UNIQUE (provider, event_id)
+);
+
+-- +goose Down
+
+DROP TABLE IF EXISTS webhook_events;
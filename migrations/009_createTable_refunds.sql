@@ -0,0 +1,33 @@
+-- +goose Up
+
+CREATE TABLE refunds (
+	id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+	order_id UUID NOT NULL REFERENCES orders(id),
+	payment_id UUID NOT NULL REFERENCES payments(id),
+	amount BIGINT NOT NULL,
+	reason TEXT,
+	restock BOOLEAN NOT NULL DEFAULT FALSE,
+	created_by UUID NOT NULL REFERENCES users(id),
+	created_at TIMESTAMP
+);
+
+CREATE INDEX idx_refunds_order_id ON refunds(order_id);
+
+CREATE TABLE refund_items (
+	id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+	refund_id UUID NOT NULL REFERENCES refunds(id),
+	order_item_id UUID NOT NULL REFERENCES order_items(id),
+	product_id UUID NOT NULL REFERENCES products(id),
+	quantity INTEGER NOT NULL,
+	amount BIGINT NOT NULL
+);
+
+CREATE INDEX idx_refund_items_refund_id ON refund_items(refund_id);
+
+ALTER TABLE payments ADD COLUMN refunded_amount BIGINT NOT NULL DEFAULT 0;
+
+-- +goose Down
+
+ALTER TABLE payments DROP COLUMN IF EXISTS refunded_amount;
+DROP TABLE IF EXISTS refund_items;
+DROP TABLE IF EXISTS refunds;
@@ -0,0 +1,28 @@
+-- +goose Up
+
+CREATE TABLE addresses (
+	id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+	user_id UUID NOT NULL REFERENCES users(id),
+	full_name VARCHAR(255) NOT NULL,
+	line1 VARCHAR(255) NOT NULL,
+	line2 VARCHAR(255),
+	city VARCHAR(100) NOT NULL,
+	region VARCHAR(100),
+	postal_code VARCHAR(20) NOT NULL,
+	country CHAR(2) NOT NULL,
+	phone VARCHAR(30),
+	is_default BOOLEAN NOT NULL DEFAULT FALSE,
+	created_at TIMESTAMP,
+	updated_at TIMESTAMP
+);
+
+CREATE INDEX idx_addresses_user_id ON addresses(user_id);
+-- a user has at most one default address
+CREATE UNIQUE INDEX idx_addresses_user_default ON addresses(user_id) WHERE is_default;
+
+ALTER TABLE orders ADD COLUMN shipping_address_snapshot JSONB;
+
+-- +goose Down
+
+ALTER TABLE orders DROP COLUMN IF EXISTS shipping_address_snapshot;
+DROP TABLE IF EXISTS addresses;
@@ -0,0 +1,23 @@
+-- +goose Up
+
+ALTER TABLE products
+	ADD COLUMN weight_grams INTEGER NOT NULL DEFAULT 0,
+	ADD COLUMN length_mm INTEGER NOT NULL DEFAULT 0,
+	ADD COLUMN width_mm INTEGER NOT NULL DEFAULT 0,
+	ADD COLUMN height_mm INTEGER NOT NULL DEFAULT 0;
+
+ALTER TABLE orders
+	ADD COLUMN shipping_method VARCHAR(50),
+	ADD COLUMN shipping_cost BIGINT NOT NULL DEFAULT 0;
+
+-- +goose Down
+
+ALTER TABLE orders
+	DROP COLUMN IF EXISTS shipping_cost,
+	DROP COLUMN IF EXISTS shipping_method;
+
+ALTER TABLE products
+	DROP COLUMN IF EXISTS height_mm,
+	DROP COLUMN IF EXISTS width_mm,
+	DROP COLUMN IF EXISTS length_mm,
+	DROP COLUMN IF EXISTS weight_grams;
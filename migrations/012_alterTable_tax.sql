@@ -0,0 +1,23 @@
+-- +goose Up
+
+ALTER TABLE products ADD COLUMN tax_class VARCHAR(50) NOT NULL DEFAULT 'standard';
+
+ALTER TABLE orders
+	ADD COLUMN tax_amount BIGINT NOT NULL DEFAULT 0,
+	ADD COLUMN tax_breakdown JSONB;
+
+ALTER TABLE order_items
+	ADD COLUMN tax_class VARCHAR(50) NOT NULL DEFAULT 'standard',
+	ADD COLUMN tax_amount BIGINT NOT NULL DEFAULT 0;
+
+-- +goose Down
+
+ALTER TABLE order_items
+	DROP COLUMN IF EXISTS tax_amount,
+	DROP COLUMN IF EXISTS tax_class;
+
+ALTER TABLE orders
+	DROP COLUMN IF EXISTS tax_breakdown,
+	DROP COLUMN IF EXISTS tax_amount;
+
+ALTER TABLE products DROP COLUMN IF EXISTS tax_class;
@@ -0,0 +1,80 @@
+-- +goose Up
+
+ALTER TABLE products RENAME COLUMN price TO price_amount;
+ALTER TABLE products ADD COLUMN price_currency CHAR(3) NOT NULL DEFAULT 'USD';
+
+CREATE TABLE product_prices (
+	id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+	product_id UUID NOT NULL REFERENCES products(id) ON DELETE CASCADE,
+	price_amount BIGINT NOT NULL,
+	price_currency CHAR(3) NOT NULL,
+	UNIQUE (product_id, price_currency)
+);
+
+ALTER TABLE orders RENAME COLUMN shipping_cost TO shipping_cost_amount;
+ALTER TABLE orders
+	ADD COLUMN total_currency CHAR(3) NOT NULL DEFAULT 'USD',
+	ADD COLUMN shipping_cost_currency CHAR(3) NOT NULL DEFAULT 'USD',
+	ADD COLUMN tax_currency CHAR(3) NOT NULL DEFAULT 'USD';
+
+ALTER TABLE order_items RENAME COLUMN unit_price TO unit_price_amount;
+ALTER TABLE order_items
+	ADD COLUMN unit_price_currency CHAR(3) NOT NULL DEFAULT 'USD',
+	ADD COLUMN tax_currency CHAR(3) NOT NULL DEFAULT 'USD';
+
+ALTER TABLE payments
+	ADD COLUMN currency CHAR(3) NOT NULL DEFAULT 'USD',
+	ADD COLUMN refunded_currency CHAR(3) NOT NULL DEFAULT 'USD';
+
+ALTER TABLE refunds ADD COLUMN currency CHAR(3) NOT NULL DEFAULT 'USD';
+ALTER TABLE refund_items ADD COLUMN currency CHAR(3) NOT NULL DEFAULT 'USD';
+
+-- tax breakdowns store their amounts as money objects from now on
+UPDATE orders SET tax_breakdown = jsonb_build_object(
+	'prices_include_tax', tax_breakdown->'prices_include_tax',
+	'total', jsonb_build_object('amount', tax_breakdown->'total', 'currency', total_currency),
+	'lines', COALESCE((
+		SELECT jsonb_agg(line || jsonb_build_object(
+			'taxable', jsonb_build_object('amount', line->'taxable', 'currency', total_currency),
+			'amount', jsonb_build_object('amount', line->'amount', 'currency', total_currency)))
+		FROM jsonb_array_elements(tax_breakdown->'lines') AS line
+	), '[]'::jsonb)
+)
+WHERE tax_breakdown IS NOT NULL;
+
+-- +goose Down
+
+UPDATE orders SET tax_breakdown = jsonb_build_object(
+	'prices_include_tax', tax_breakdown->'prices_include_tax',
+	'total', tax_breakdown->'total'->'amount',
+	'lines', COALESCE((
+		SELECT jsonb_agg(line || jsonb_build_object(
+			'taxable', line->'taxable'->'amount',
+			'amount', line->'amount'->'amount'))
+		FROM jsonb_array_elements(tax_breakdown->'lines') AS line
+	), '[]'::jsonb)
+)
+WHERE tax_breakdown IS NOT NULL;
+
+ALTER TABLE refund_items DROP COLUMN IF EXISTS currency;
+ALTER TABLE refunds DROP COLUMN IF EXISTS currency;
+
+ALTER TABLE payments
+	DROP COLUMN IF EXISTS refunded_currency,
+	DROP COLUMN IF EXISTS currency;
+
+ALTER TABLE order_items
+	DROP COLUMN IF EXISTS tax_currency,
+	DROP COLUMN IF EXISTS unit_price_currency;
+ALTER TABLE order_items RENAME COLUMN unit_price_amount TO unit_price;
+
+ALTER TABLE orders
+	DROP COLUMN IF EXISTS tax_currency,
+	DROP COLUMN IF EXISTS shipping_cost_currency,
+	DROP COLUMN IF EXISTS total_currency;
+ALTER TABLE orders RENAME COLUMN shipping_cost_amount TO shipping_cost;
+
+DROP TABLE IF EXISTS product_prices;
+
+ALTER TABLE products DROP COLUMN IF EXISTS price_currency;
+ALTER TABLE products RENAME COLUMN price_amount TO price;
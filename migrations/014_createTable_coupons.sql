@@ -0,0 +1,54 @@
+-- +goose Up
+
+CREATE TABLE coupons (
+	id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+	code VARCHAR(50) NOT NULL UNIQUE,
+	type VARCHAR(20) NOT NULL,
+	percent_bps BIGINT NOT NULL DEFAULT 0,
+	fixed_amount BIGINT NOT NULL DEFAULT 0,
+	fixed_currency VARCHAR(3) NOT NULL DEFAULT '',
+	min_total_amount BIGINT NOT NULL DEFAULT 0,
+	min_total_currency VARCHAR(3) NOT NULL DEFAULT '',
+	starts_at TIMESTAMP,
+	ends_at TIMESTAMP,
+	max_redemptions INTEGER,
+	max_redemptions_per_user INTEGER,
+	redemptions INTEGER NOT NULL DEFAULT 0,
+	created_at TIMESTAMP,
+	updated_at TIMESTAMP
+);
+
+CREATE TABLE coupon_redemptions (
+	id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+	coupon_id UUID NOT NULL REFERENCES coupons(id),
+	user_id UUID NOT NULL REFERENCES users(id),
+	order_id UUID NOT NULL UNIQUE REFERENCES orders(id),
+	created_at TIMESTAMP
+);
+
+CREATE INDEX idx_coupon_redemptions_coupon_user ON coupon_redemptions(coupon_id, user_id);
+
+ALTER TABLE orders
+	ADD COLUMN coupon_id UUID REFERENCES coupons(id),
+	ADD COLUMN coupon_code VARCHAR(50),
+	ADD COLUMN discount_amount BIGINT NOT NULL DEFAULT 0,
+	ADD COLUMN discount_currency CHAR(3) NOT NULL DEFAULT 'USD';
+
+ALTER TABLE order_items
+	ADD COLUMN discount_amount BIGINT NOT NULL DEFAULT 0,
+	ADD COLUMN discount_currency CHAR(3) NOT NULL DEFAULT 'USD';
+
+-- +goose Down
+
+ALTER TABLE order_items
+	DROP COLUMN IF EXISTS discount_currency,
+	DROP COLUMN IF EXISTS discount_amount;
+
+ALTER TABLE orders
+	DROP COLUMN IF EXISTS discount_currency,
+	DROP COLUMN IF EXISTS discount_amount,
+	DROP COLUMN IF EXISTS coupon_code,
+	DROP COLUMN IF EXISTS coupon_id;
+
+DROP TABLE IF EXISTS coupon_redemptions;
+DROP TABLE IF EXISTS coupons;
@@ -0,0 +1,26 @@
+-- +goose Up
+
+CREATE TABLE promotions (
+	id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+	name VARCHAR(100) NOT NULL,
+	type VARCHAR(20) NOT NULL,
+	priority INTEGER NOT NULL DEFAULT 0,
+	exclusive BOOLEAN NOT NULL DEFAULT FALSE,
+	percent_bps BIGINT NOT NULL DEFAULT 0,
+	buy_quantity INTEGER NOT NULL DEFAULT 0,
+	free_quantity INTEGER NOT NULL DEFAULT 0,
+	currency VARCHAR(3) NOT NULL DEFAULT '',
+	tiers JSONB,
+	product_ids JSONB,
+	starts_at TIMESTAMP,
+	ends_at TIMESTAMP,
+	active BOOLEAN NOT NULL DEFAULT TRUE,
+	created_at TIMESTAMP,
+	updated_at TIMESTAMP
+);
+
+CREATE INDEX idx_promotions_active ON promotions(active, priority);
+
+-- +goose Down
+
+DROP TABLE IF EXISTS promotions;
@@ -0,0 +1,30 @@
+-- +goose Up
+
+CREATE TABLE categories (
+	id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+	parent_id UUID REFERENCES categories(id),
+	name VARCHAR(100) NOT NULL,
+	slug VARCHAR(100) NOT NULL UNIQUE,
+	description TEXT,
+	created_at TIMESTAMP,
+	updated_at TIMESTAMP
+);
+
+CREATE INDEX idx_categories_parent_id ON categories(parent_id);
+
+CREATE TABLE product_categories (
+	product_id UUID NOT NULL REFERENCES products(id) ON DELETE CASCADE,
+	category_id UUID NOT NULL REFERENCES categories(id) ON DELETE CASCADE,
+	PRIMARY KEY (product_id, category_id)
+);
+
+CREATE INDEX idx_product_categories_category_id ON product_categories(category_id);
+
+ALTER TABLE promotions ADD COLUMN category_ids JSONB;
+
+-- +goose Down
+
+ALTER TABLE promotions DROP COLUMN IF EXISTS category_ids;
+
+DROP TABLE IF EXISTS product_categories;
+DROP TABLE IF EXISTS categories;
@@ -0,0 +1,45 @@
+-- +goose Up
+
+CREATE TABLE product_options (
+	id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+	product_id UUID NOT NULL REFERENCES products(id) ON DELETE CASCADE,
+	name VARCHAR(50) NOT NULL,
+	values JSONB NOT NULL,
+	position INTEGER NOT NULL DEFAULT 0,
+	UNIQUE (product_id, name)
+);
+
+CREATE TABLE product_variants (
+	id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+	product_id UUID NOT NULL REFERENCES products(id) ON DELETE CASCADE,
+	sku VARCHAR(64) NOT NULL UNIQUE,
+	options JSONB NOT NULL,
+	options_key TEXT NOT NULL,
+	price_amount BIGINT NOT NULL DEFAULT 0,
+	price_currency VARCHAR(3) NOT NULL DEFAULT '',
+	stock_quantity INTEGER NOT NULL DEFAULT 0 CHECK (stock_quantity >= 0),
+	created_at TIMESTAMP,
+	updated_at TIMESTAMP,
+	UNIQUE (product_id, options_key)
+);
+
+ALTER TABLE order_items
+	ADD COLUMN variant_id UUID REFERENCES product_variants(id),
+	ADD COLUMN sku VARCHAR(64);
+
+ALTER TABLE stock_reservations ADD COLUMN variant_id UUID REFERENCES product_variants(id);
+
+ALTER TABLE refund_items ADD COLUMN variant_id UUID REFERENCES product_variants(id);
+
+-- +goose Down
+
+ALTER TABLE refund_items DROP COLUMN IF EXISTS variant_id;
+
+ALTER TABLE stock_reservations DROP COLUMN IF EXISTS variant_id;
+
+ALTER TABLE order_items
+	DROP COLUMN IF EXISTS sku,
+	DROP COLUMN IF EXISTS variant_id;
+
+DROP TABLE IF EXISTS product_variants;
+DROP TABLE IF EXISTS product_options;
@@ -0,0 +1,32 @@
+-- +goose Up
+
+ALTER TABLE products ADD COLUMN search_vector TSVECTOR;
+
+-- +goose StatementBegin
+CREATE FUNCTION products_search_vector_update() RETURNS trigger AS $$
+BEGIN
+	NEW.search_vector :=
+		setweight(to_tsvector('english', coalesce(NEW.name, '')), 'A') ||
+		setweight(to_tsvector('english', coalesce(NEW.description, '')), 'B');
+	RETURN NEW;
+END
+$$ LANGUAGE plpgsql;
+-- +goose StatementEnd
+
+CREATE TRIGGER products_search_vector_trigger
+	BEFORE INSERT OR UPDATE OF name, description ON products
+	FOR EACH ROW EXECUTE FUNCTION products_search_vector_update();
+
+-- fill the index for the products that already exist
+UPDATE products SET search_vector =
+	setweight(to_tsvector('english', coalesce(name, '')), 'A') ||
+	setweight(to_tsvector('english', coalesce(description, '')), 'B');
+
+CREATE INDEX idx_products_search_vector ON products USING GIN (search_vector);
+
+-- +goose Down
+
+DROP INDEX IF EXISTS idx_products_search_vector;
+DROP TRIGGER IF EXISTS products_search_vector_trigger ON products;
+DROP FUNCTION IF EXISTS products_search_vector_update();
+ALTER TABLE products DROP COLUMN IF EXISTS search_vector;
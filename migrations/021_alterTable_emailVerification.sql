@@ -0,0 +1,25 @@
+-- +goose Up
+
+ALTER TABLE users
+	ADD COLUMN email_verified_at TIMESTAMP;
+
+-- accounts from before verification existed keep checking out, only new
+-- signups have to confirm their email
+UPDATE users SET email_verified_at = COALESCE(created_at, NOW());
+
+CREATE TABLE email_verification_tokens (
+	id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+	user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+	token_hash VARCHAR(64) NOT NULL UNIQUE,
+	expires_at TIMESTAMP NOT NULL,
+	created_at TIMESTAMP
+);
+
+CREATE INDEX idx_email_verification_tokens_user ON email_verification_tokens(user_id, created_at);
+
+-- +goose Down
+
+DROP TABLE IF EXISTS email_verification_tokens;
+
+ALTER TABLE users
+	DROP COLUMN IF EXISTS email_verified_at;
@@ -0,0 +1,36 @@
+-- +goose Up
+
+-- emails used to be unique only in their exact case. Of the accounts whose
+-- emails only differ in case, the oldest keeps its email; the others are
+-- moved to a placeholder that still shows the original address and are
+-- listed in user_email_conflicts so support can merge them with the owner.
+CREATE TABLE user_email_conflicts (
+	user_id UUID PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+	email VARCHAR(255) NOT NULL,
+	created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+INSERT INTO user_email_conflicts (user_id, email)
+SELECT id, email FROM (
+	SELECT id, email, ROW_NUMBER() OVER (PARTITION BY LOWER(email) ORDER BY created_at, id) AS position
+	FROM users
+) ranked
+WHERE position > 1;
+
+UPDATE users SET email = LEFT('conflict-' || users.id || '+' || users.email, 255)
+FROM user_email_conflicts
+WHERE user_email_conflicts.user_id = users.id;
+
+-- emails are stored in lower case from now on, older accounts keep theirs
+-- but can't be registered again in another case
+CREATE UNIQUE INDEX idx_users_email_lower ON users(LOWER(email));
+
+-- +goose Down
+
+DROP INDEX IF EXISTS idx_users_email_lower;
+
+UPDATE users SET email = user_email_conflicts.email
+FROM user_email_conflicts
+WHERE user_email_conflicts.user_id = users.id;
+
+DROP TABLE IF EXISTS user_email_conflicts;
@@ -0,0 +1,78 @@
+-- +goose Up
+
+CREATE TABLE permissions (
+	name VARCHAR(50) PRIMARY KEY,
+	description TEXT NOT NULL DEFAULT ''
+);
+
+CREATE TABLE roles (
+	id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+	name VARCHAR(50) NOT NULL UNIQUE,
+	description TEXT NOT NULL DEFAULT '',
+	created_at TIMESTAMP,
+	updated_at TIMESTAMP
+);
+
+CREATE TABLE role_permissions (
+	role_id UUID NOT NULL REFERENCES roles(id) ON DELETE CASCADE,
+	permission_name VARCHAR(50) NOT NULL REFERENCES permissions(name) ON DELETE CASCADE,
+	PRIMARY KEY (role_id, permission_name)
+);
+
+CREATE TABLE user_roles (
+	user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+	role_id UUID NOT NULL REFERENCES roles(id) ON DELETE CASCADE,
+	PRIMARY KEY (user_id, role_id)
+);
+
+CREATE INDEX idx_user_roles_role ON user_roles(role_id);
+
+INSERT INTO permissions (name, description) VALUES
+	('products:write', 'create and edit products, their prices, options and variants'),
+	('categories:write', 'edit the category tree'),
+	('orders:read', 'see the status history and refunds of any order'),
+	('orders:write', 'move orders through their lifecycle'),
+	('refunds:write', 'refund orders'),
+	('coupons:read', 'list coupons'),
+	('coupons:write', 'create coupons'),
+	('promotions:read', 'list promotions'),
+	('promotions:write', 'create and delete promotions'),
+	('roles:read', 'list roles and their permissions'),
+	('roles:write', 'assign roles to users');
+
+INSERT INTO roles (name, description, created_at, updated_at) VALUES
+	('super_admin', 'everything', NOW(), NOW()),
+	('catalog_manager', 'products, categories and discounts', NOW(), NOW()),
+	('order_operator', 'order fulfilment and refunds', NOW(), NOW()),
+	('support_agent', 'read only access to orders', NOW(), NOW());
+
+INSERT INTO role_permissions (role_id, permission_name)
+SELECT roles.id, permissions.name FROM roles, permissions
+WHERE roles.name = 'super_admin'
+	OR (roles.name = 'catalog_manager' AND permissions.name IN (
+		'products:write', 'categories:write', 'coupons:read', 'coupons:write', 'promotions:read', 'promotions:write'))
+	OR (roles.name = 'order_operator' AND permissions.name IN ('orders:read', 'orders:write', 'refunds:write'))
+	OR (roles.name = 'support_agent' AND permissions.name IN ('orders:read'));
+
+-- every admin keeps full access
+INSERT INTO user_roles (user_id, role_id)
+SELECT users.id, roles.id FROM users, roles
+WHERE users.is_admin AND roles.name = 'super_admin';
+
+ALTER TABLE users DROP COLUMN is_admin;
+
+-- +goose Down
+
+ALTER TABLE users ADD COLUMN is_admin BOOLEAN;
+
+UPDATE users SET is_admin = TRUE
+WHERE id IN (
+	SELECT user_roles.user_id FROM user_roles
+	JOIN roles ON roles.id = user_roles.role_id
+	WHERE roles.name = 'super_admin'
+);
+
+DROP TABLE IF EXISTS user_roles;
+DROP TABLE IF EXISTS role_permissions;
+DROP TABLE IF EXISTS roles;
+DROP TABLE IF EXISTS permissions;
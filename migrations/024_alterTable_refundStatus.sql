@@ -0,0 +1,11 @@
+-- +goose Up
+
+-- refunds are recorded as pending before the provider is asked to move the
+-- money, every refund made so far went through
+ALTER TABLE refunds
+	ADD COLUMN status VARCHAR(20) NOT NULL DEFAULT 'completed';
+
+-- +goose Down
+
+ALTER TABLE refunds
+	DROP COLUMN IF EXISTS status;
@@ -0,0 +1,17 @@
+-- +goose Up
+
+-- orders to free form addresses need a country too, so they can be taxed
+ALTER TABLE orders
+	ADD COLUMN shipping_country VARCHAR(2) NOT NULL DEFAULT '',
+	ADD COLUMN shipping_region VARCHAR(100);
+
+UPDATE orders
+SET shipping_country = COALESCE(shipping_address_snapshot->>'country', ''),
+	shipping_region = shipping_address_snapshot->>'region'
+WHERE shipping_address_snapshot IS NOT NULL;
+
+-- +goose Down
+
+ALTER TABLE orders
+	DROP COLUMN IF EXISTS shipping_region,
+	DROP COLUMN IF EXISTS shipping_country;
@@ -0,0 +1,19 @@
+-- +goose Up
+
+-- full refunds give back more than their items, the shipping and rounding
+-- leftovers, that difference is recorded on its own
+ALTER TABLE refunds
+	ADD COLUMN adjustment_amount BIGINT NOT NULL DEFAULT 0,
+	ADD COLUMN adjustment_currency CHAR(3) NOT NULL DEFAULT 'USD';
+
+UPDATE refunds
+SET adjustment_amount = refunds.amount - COALESCE((
+		SELECT SUM(refund_items.amount) FROM refund_items WHERE refund_items.refund_id = refunds.id
+	), 0),
+	adjustment_currency = refunds.currency;
+
+-- +goose Down
+
+ALTER TABLE refunds
+	DROP COLUMN IF EXISTS adjustment_currency,
+	DROP COLUMN IF EXISTS adjustment_amount;